@@ -0,0 +1,97 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewsapw/avalio/notificators"
+)
+
+// Client calls API of a running avalio instance
+type Client struct {
+	baseURL string
+	token   string
+	client  http.Client
+}
+
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Acknowledge acknowledges open incidents of the resource
+func (c *Client) Acknowledge(resourceName, by string) error {
+	path := "/api/v1/resources/" + url.PathEscape(resourceName) + "/acknowledge"
+	return c.do(http.MethodPost, path, AcknowledgeRequest{By: by}, nil)
+}
+
+// Silences returns active and pending silences
+func (c *Client) Silences() ([]notificators.Silence, error) {
+	var silences []notificators.Silence
+	if err := c.do(http.MethodGet, "/api/v1/silences", nil, &silences); err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
+
+// AddSilence creates silence, which starts now
+func (c *Client) AddSilence(request SilenceRequest) (notificators.Silence, error) {
+	var silence notificators.Silence
+	if err := c.do(http.MethodPost, "/api/v1/silences", request, &silence); err != nil {
+		return notificators.Silence{}, err
+	}
+	return silence, nil
+}
+
+// ExpireSilence removes silence before its end
+func (c *Client) ExpireSilence(id int) error {
+	return c.do(http.MethodDelete, "/api/v1/silences/"+strconv.Itoa(id), nil, nil)
+}
+
+func (c *Client) do(method, path string, body, result any) error {
+	var requestBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&requestBody).Encode(body); err != nil {
+			return fmt.Errorf("failed to marshal request body: %v", err)
+		}
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, &requestBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiError errorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&apiError); err != nil || apiError.Error == "" {
+			return fmt.Errorf("API error: %s", resp.Status)
+		}
+		return fmt.Errorf("API error: %s", apiError.Error)
+	}
+
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to decode response: %v", err)
+		}
+	}
+	return nil
+}
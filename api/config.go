@@ -0,0 +1,55 @@
+package api
+
+import (
+	"fmt"
+	"net"
+)
+
+// [api]
+// listen = '127.0.0.1:8080'
+// token = '...'
+type Config struct {
+	// Listen is the server address, the server is disabled if it is empty
+	Listen string `toml:"listen"`
+	// Token is required as a bearer token in API requests, if set
+	Token string `toml:"token"`
+	// TokenFile is read into Token on config load
+	TokenFile string `toml:"token_file"`
+}
+
+func (c Config) Validate() error {
+	if c.Listen == "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(c.Listen)
+	if err != nil {
+		return fmt.Errorf("[api] - listen must be host:port: %v", err)
+	}
+	// the API can acknowledge outages and create silences, so it must not
+	// be open to the network without a token. TokenFile is already read
+	// into Token, so the resolved token is checked.
+	if c.Token == "" && !isLoopback(host) {
+		return fmt.Errorf("[api] - token or token_file is required, unless listen is a loopback address")
+	}
+	return nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// URL returns base URL of the server for clients on the same host
+func (c Config) URL() string {
+	host, port, err := net.SplitHostPort(c.Listen)
+	if err != nil {
+		return ""
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
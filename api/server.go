@@ -0,0 +1,223 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andrewsapw/avalio/notificators"
+	"github.com/andrewsapw/avalio/resources"
+)
+
+// Backend is the application state exposed by the API
+type Backend interface {
+	Statuses() []notificators.ResourceStatus
+	Incidents() []notificators.Incident
+	Acknowledge(resourceName, by string) error
+	Gaps() []notificators.Gap
+	Silences() []notificators.Silence
+	AddSilence(silence notificators.Silence) (notificators.Silence, error)
+	ExpireSilence(id int) error
+	// Ping passes signal of a job to the heartbeat resource with the token
+	Ping(token string, signal resources.HeartbeatSignal, body string) error
+}
+
+// maxPingBody limits size of the ping request body
+const maxPingBody = 10 * 1024
+
+// Server is the built-in HTTP server. API endpoints are under /api/v1.
+type Server struct {
+	config  Config
+	backend Backend
+	mux     *http.ServeMux
+}
+
+func NewServer(config Config, backend Backend) *Server {
+	s := &Server{config: config, backend: backend, mux: http.NewServeMux()}
+
+	s.mux.Handle("GET /api/v1/statuses", s.authorized(s.statuses))
+	s.mux.Handle("GET /api/v1/incidents", s.authorized(s.incidents))
+	s.mux.Handle("GET /api/v1/gaps", s.authorized(s.gaps))
+	s.mux.Handle("POST /api/v1/resources/{name}/acknowledge", s.authorized(s.acknowledge))
+	s.mux.Handle("GET /api/v1/silences", s.authorized(s.silences))
+	s.mux.Handle("POST /api/v1/silences", s.authorized(s.addSilence))
+	s.mux.Handle("DELETE /api/v1/silences/{id}", s.authorized(s.expireSilence))
+
+	// pings are authorized by the heartbeat token in the path, so jobs
+	// don't need the API token
+	s.mux.HandleFunc("/ping/{token}", s.ping(resources.HeartbeatSuccess))
+	s.mux.HandleFunc("/ping/{token}/start", s.ping(resources.HeartbeatStart))
+	s.mux.HandleFunc("/ping/{token}/fail", s.ping(resources.HeartbeatFail))
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Run serves requests until ctx is done
+func (s *Server) Run(ctx context.Context) error {
+	server := &http.Server{
+		Addr:              s.config.Listen,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("Starting API server", "listen", s.config.Listen)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// authorized checks bearer token, if it is configured
+func (s *Server) authorized(handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.config.Token != "" {
+			expected := "Bearer " + s.config.Token
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) != 1 {
+				writeError(w, http.StatusUnauthorized, errors.New("invalid token"))
+				return
+			}
+		}
+		handler(w, r)
+	})
+}
+
+func (s *Server) statuses(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.backend.Statuses())
+}
+
+func (s *Server) incidents(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.backend.Incidents())
+}
+
+func (s *Server) gaps(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.backend.Gaps())
+}
+
+// ping records signal of a job, the request body is kept as the job log
+func (s *Server) ping(signal resources.HeartbeatSignal) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxPingBody))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := s.backend.Ping(r.PathValue("token"), signal, string(body)); err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+	}
+}
+
+// AcknowledgeRequest is the body of acknowledge request
+type AcknowledgeRequest struct {
+	By string `json:"by"`
+}
+
+func (s *Server) acknowledge(w http.ResponseWriter, r *http.Request) {
+	var request AcknowledgeRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if request.By == "" {
+		request.By = "api"
+	}
+
+	if err := s.backend.Acknowledge(r.PathValue("name"), request.By); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+func (s *Server) silences(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.backend.Silences())
+}
+
+// SilenceRequest is the body of silence creation request
+type SilenceRequest struct {
+	Resources       []string          `json:"resources,omitempty"`
+	Monitors        []string          `json:"monitors,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	DurationMinutes int               `json:"duration_minutes"`
+	Comment         string            `json:"comment,omitempty"`
+	By              string            `json:"by,omitempty"`
+}
+
+func (s *Server) addSilence(w http.ResponseWriter, r *http.Request) {
+	var request SilenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if request.DurationMinutes <= 0 {
+		writeError(w, http.StatusBadRequest, errors.New("duration_minutes must be positive"))
+		return
+	}
+
+	now := time.Now()
+	silence, err := s.backend.AddSilence(notificators.Silence{
+		Resources: request.Resources,
+		Monitors:  request.Monitors,
+		Labels:    request.Labels,
+		Comment:   request.Comment,
+		CreatedBy: request.By,
+		StartsAt:  now,
+		EndsAt:    now.Add(time.Duration(request.DurationMinutes) * time.Minute),
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, silence)
+}
+
+func (s *Server) expireSilence(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid silence id"))
+		return
+	}
+	if err := s.backend.ExpireSilence(id); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+// errorResponse is returned by failed requests
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Error writing API response", "error", err)
+	}
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewsapw/avalio/notificators"
+	"github.com/andrewsapw/avalio/resources"
+)
+
+type mockedBackend struct {
+	acknowledged map[string]string
+	silences     []notificators.Silence
+	pings        []string
+}
+
+func (b *mockedBackend) Statuses() []notificators.ResourceStatus {
+	return []notificators.ResourceStatus{{ResourceName: "api"}}
+}
+
+func (b *mockedBackend) Incidents() []notificators.Incident {
+	return nil
+}
+
+func (b *mockedBackend) Acknowledge(resourceName, by string) error {
+	if resourceName != "api" {
+		return errors.New("No open incidents")
+	}
+	b.acknowledged[resourceName] = by
+	return nil
+}
+
+func (b *mockedBackend) Gaps() []notificators.Gap {
+	return nil
+}
+
+func (b *mockedBackend) Silences() []notificators.Silence {
+	return b.silences
+}
+
+func (b *mockedBackend) AddSilence(silence notificators.Silence) (notificators.Silence, error) {
+	silence.ID = len(b.silences) + 1
+	b.silences = append(b.silences, silence)
+	return silence, nil
+}
+
+func (b *mockedBackend) ExpireSilence(id int) error {
+	if id != 1 {
+		return errors.New("Silence not found")
+	}
+	b.silences = nil
+	return nil
+}
+
+func (b *mockedBackend) Ping(token string, signal resources.HeartbeatSignal, body string) error {
+	if token != "b4ckup-token" {
+		return errors.New("Heartbeat not found")
+	}
+	b.pings = append(b.pings, fmt.Sprintf("%d %s", signal, body))
+	return nil
+}
+
+func TestServer_Acknowledge(t *testing.T) {
+	backend := &mockedBackend{acknowledged: make(map[string]string)}
+	server := httptest.NewServer(NewServer(Config{Token: "secret"}, backend))
+	defer server.Close()
+
+	if err := NewClient(server.URL, "secret").Acknowledge("api", "alice"); err != nil {
+		t.Fatalf("Expected acknowledge to succeed, got %v", err)
+	}
+	if backend.acknowledged["api"] != "alice" {
+		t.Errorf("Expected api to be acknowledged by alice, got %v", backend.acknowledged)
+	}
+
+	err := NewClient(server.URL, "secret").Acknowledge("db", "alice")
+	if err == nil || err.Error() != "API error: No open incidents" {
+		t.Errorf("Expected backend error, got %v", err)
+	}
+}
+
+func TestServer_Silences(t *testing.T) {
+	backend := &mockedBackend{}
+	server := httptest.NewServer(NewServer(Config{}, backend))
+	defer server.Close()
+	client := NewClient(server.URL, "")
+
+	silence, err := client.AddSilence(SilenceRequest{Resources: []string{"api"}, DurationMinutes: 30, By: "alice"})
+	if err != nil {
+		t.Fatalf("Expected silence to be created, got %v", err)
+	}
+	if silence.ID != 1 || silence.CreatedBy != "alice" || silence.EndsAt.Sub(silence.StartsAt) != 30*time.Minute {
+		t.Errorf("Unexpected silence %+v", silence)
+	}
+
+	if _, err := client.AddSilence(SilenceRequest{Resources: []string{"api"}}); err == nil {
+		t.Error("Expected silence without duration to be rejected")
+	}
+
+	silences, err := client.Silences()
+	if err != nil || len(silences) != 1 {
+		t.Fatalf("Expected one silence, got %v, %v", silences, err)
+	}
+
+	if err := client.ExpireSilence(1); err != nil {
+		t.Errorf("Expected silence to be expired, got %v", err)
+	}
+	if err := client.ExpireSilence(2); err == nil {
+		t.Error("Expected unknown silence to fail")
+	}
+}
+
+func TestServer_Token(t *testing.T) {
+	server := httptest.NewServer(NewServer(Config{Token: "secret"}, &mockedBackend{}))
+	defer server.Close()
+
+	if err := NewClient(server.URL, "wrong").Acknowledge("api", "alice"); err == nil {
+		t.Error("Expected request with invalid token to fail")
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/statuses", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected statuses to be returned, got %s", resp.Status)
+	}
+}
+
+func TestConfig_URL(t *testing.T) {
+	cases := map[string]string{
+		":8080":         "http://127.0.0.1:8080",
+		"0.0.0.0:9000":  "http://127.0.0.1:9000",
+		"10.0.0.1:8080": "http://10.0.0.1:8080",
+	}
+	for listen, expected := range cases {
+		if url := (Config{Listen: listen}).URL(); url != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, listen, url)
+		}
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cases := []struct {
+		config Config
+		valid  bool
+	}{
+		{Config{}, true},
+		{Config{Listen: "127.0.0.1:8080"}, true},
+		{Config{Listen: "[::1]:8080"}, true},
+		{Config{Listen: "localhost:8080"}, true},
+		{Config{Listen: ":8080"}, false},
+		{Config{Listen: "0.0.0.0:8080"}, false},
+		{Config{Listen: "0.0.0.0:8080", Token: "secret"}, true},
+		{Config{Listen: "10.0.0.1:8080", TokenFile: "/run/secrets/token", Token: "secret"}, true},
+		{Config{Listen: "10.0.0.1:8080", TokenFile: "/run/secrets/token"}, false},
+		{Config{Listen: "8080", Token: "secret"}, false},
+	}
+	for _, c := range cases {
+		if err := c.config.Validate(); (err == nil) != c.valid {
+			t.Errorf("Expected valid = %v for %+v, got %v", c.valid, c.config, err)
+		}
+	}
+}
+
+func TestServer_Ping(t *testing.T) {
+	backend := &mockedBackend{}
+	// pings don't require the API token
+	server := httptest.NewServer(NewServer(Config{Token: "secret"}, backend))
+	defer server.Close()
+
+	cases := []struct {
+		path   string
+		body   string
+		status int
+	}{
+		{"/ping/b4ckup-token", "", http.StatusOK},
+		{"/ping/b4ckup-token/start", "", http.StatusOK},
+		{"/ping/b4ckup-token/fail", "disk is full", http.StatusOK},
+		{"/ping/unknown-token", "", http.StatusNotFound},
+	}
+	for _, c := range cases {
+		resp, err := http.Post(server.URL+c.path, "text/plain", strings.NewReader(c.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != c.status {
+			t.Errorf("Expected status %d for %s, got %d", c.status, c.path, resp.StatusCode)
+		}
+	}
+
+	expected := []string{"0 ", "1 ", "2 disk is full"}
+	if strings.Join(backend.pings, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected pings %q, got %q", expected, backend.pings)
+	}
+}
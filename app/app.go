@@ -3,10 +3,14 @@ package app
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"reflect"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/andrewsapw/avalio/api"
 	"github.com/andrewsapw/avalio/monitors"
 	"github.com/andrewsapw/avalio/notificators"
 	"github.com/andrewsapw/avalio/resources"
@@ -17,117 +21,763 @@ type Application struct {
 	Resources    []resources.Resource
 	Notificators []notificators.Notificator
 	Monitors     []monitors.Monitor
+	State        *State
+	Options      Options
+
+	// mu guards running components and exported fields, which are replaced
+	// on reload
+	mu           sync.Mutex
+	ctx          context.Context
+	results      chan status.CheckResult
+	deadLetter   *DeadLetterLog
+	notificators map[string]*notificatorRuntime
+	runners      map[stateKey]*runnerRuntime
+	maintenance  *Maintenance
+	canary       *Canary
+	escalator    *Escalator
+	dispatcher   *dispatcher
+}
+
+// Options configure processing of check results after the checks
+type Options struct {
+	Delivery DeliveryConfig
+	Grouping GroupingConfig
+	Routing  RoutingConfig
+	// Escalations are policies referenced by MonitorEscalations, which maps
+	// monitor name to policy name
+	Escalations        []EscalationPolicyConfig
+	MonitorEscalations map[string]string
+	Maintenance        []MaintenanceWindowConfig
+	Canary             CanaryConfig
+	API                api.Config
+	// NotificatorLabels holds labels of notificators, keyed by name, they
+	// are matched by monitor notificator selectors
+	NotificatorLabels map[string]map[string]string
+	// ResourceConfigs, MonitorConfigs and NotificatorConfigs hold configs
+	// the components were built from, keyed by name. Reload restarts only
+	// components with changed configs.
+	ResourceConfigs    map[string]any
+	MonitorConfigs     map[string]any
+	NotificatorConfigs map[string]any
+}
+
+// apiBackend combines application state and silences for the API server
+type apiBackend struct {
+	*State
+	*Maintenance
+}
+
+// notificationSink receives check results for a notificator
+type notificationSink interface {
+	Enqueue(checkResult status.CheckResult)
+}
+
+// notificatorRuntime is a notificator with running delivery queue
+type notificatorRuntime struct {
+	queue  *DeliveryQueue
+	sink   notificationSink
+	cancel context.CancelFunc
+	// done is closed after the queue has stopped
+	done chan struct{}
+	// stopListen stops bot commands of interactive notificator
+	stopListen context.CancelFunc
+}
+
+// runnerRuntime is a running check of the resource by the monitor
+type runnerRuntime struct {
+	runner *monitors.MonitorRunner
+	cancel context.CancelFunc
+	// done is closed after the runner has stopped
+	done chan struct{}
+}
+
+// layout is the validated wiring of monitors, resources and notificators
+type layout struct {
+	router               *Router
+	monitorsResources    map[string][]resources.Resource
+	monitorsNotificators map[string][]string
+	canaryNotificators   []string
 }
 
 func NewApplication(
 	resources []resources.Resource,
 	notificators []notificators.Notificator,
 	monitors []monitors.Monitor,
+	options Options,
 ) *Application {
 	return &Application{
 		Resources:    resources,
 		Notificators: notificators,
 		Monitors:     monitors,
+		State:        NewState(resources),
+		Options:      options,
 	}
 }
 
-func (app *Application) Run() error {
-	var wg sync.WaitGroup
-	ctx, cancel := context.WithCancel(context.Background())
+// Run starts monitors and notificators and blocks until ctx is done
+func (app *Application) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	// create channels for each notificator
-	notificatorsChannels := make(map[string]chan status.CheckResult)
-	for _, n := range app.Notificators {
-		nChannel := make(chan status.CheckResult)
-		notificatorsChannels[n.GetName()] = nChannel
+	if err := app.start(ctx); err != nil {
+		return err
 	}
+	slog.Info("Application started")
 
-	// create resource name to objects mapping
-	nameToResource := make(map[string]resources.Resource)
-	for _, r := range app.Resources {
-		nameToResource[r.GetName()] = r
+	<-ctx.Done()
+	return nil
+}
+
+func (app *Application) start(ctx context.Context) error {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	layout, err := app.layout()
+	if err != nil {
+		return err
+	}
+	maintenance, err := NewMaintenance(app.Options.Maintenance)
+	if err != nil {
+		return err
 	}
 
-	// start notificators listen
-	for _, notificator := range app.Notificators {
-		channel := notificatorsChannels[notificator.GetName()]
+	app.ctx = ctx
+	// all runners send results to the dispatcher, which records them in
+	// the application state and forwards to notificators
+	app.results = make(chan status.CheckResult)
+	app.deadLetter = NewDeadLetterLog(app.Options.Delivery.DeadLetterFile)
+	app.notificators = make(map[string]*notificatorRuntime)
+	app.runners = make(map[stateKey]*runnerRuntime)
 
-		wg.Add(1)
+	// start delivery queues for each notificator
+	sinks := make(map[string]notificationSink)
+	for _, n := range app.Notificators {
+		started, err := app.startNotificator(n, app.Options)
+		if err != nil {
+			return err
+		}
+		app.notificators[n.GetName()] = started
+		sinks[n.GetName()] = started.sink
+	}
+
+	app.maintenance = maintenance
+	app.canary = NewCanary(app.Options.Canary)
+	app.escalator = NewEscalator(app.State, maintenance, sinks, app.Options.Escalations, app.Options.MonitorEscalations)
+	app.dispatcher = &dispatcher{
+		state:       app.State,
+		maintenance: maintenance,
+		escalator:   app.escalator,
+	}
+	app.dispatcher.configure(layout.router, layout.monitorsNotificators, sinks, app.canary, layout.canaryNotificators)
+
+	if app.Options.API.Listen != "" {
+		server := api.NewServer(app.Options.API, apiBackend{State: app.State, Maintenance: maintenance})
 		go func() {
-			go app.listenNotificator(notificator, channel, ctx)
-			wg.Done()
+			if err := server.Run(ctx); err != nil {
+				slog.Error("API server failed", "error", err)
+			}
 		}()
 	}
 
 	// start monitors
 	for _, m := range app.Monitors {
-		// filter Resources
-		var monitorResources []resources.Resource
-		for _, rName := range m.GetResourcesNames() {
-			resource, exists := nameToResource[rName]
-			if exists {
-				monitorResources = append(monitorResources, resource)
-			} else {
-				return fmt.Errorf("Resource '%s' not found", rName)
+		for _, r := range layout.monitorsResources[m.GetName()] {
+			app.startRunner(m, r, nil)
+		}
+	}
+
+	summaries := make(chan status.CheckResult)
+	go maintenance.Run(ctx, summaries)
+	go app.dispatcher.run(ctx, app.results, summaries)
+	return nil
+}
+
+// Reload applies new configuration to the running application. Runners of
+// unchanged monitors and resources keep running, changed ones are restarted
+// with their outage state. If the configuration is invalid, it is rejected
+// and the running one is kept.
+func (app *Application) Reload(
+	resourcesList []resources.Resource,
+	notificatorsList []notificators.Notificator,
+	monitorsList []monitors.Monitor,
+	options Options,
+) error {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if app.ctx == nil {
+		return fmt.Errorf("Application is not started")
+	}
+
+	keepHeartbeats(app.Resources, resourcesList)
+	next := &Application{
+		Resources:    resourcesList,
+		Notificators: notificatorsList,
+		Monitors:     monitorsList,
+		Options:      options,
+	}
+	layout, err := next.layout()
+	if err != nil {
+		return err
+	}
+
+	// these settings are used by started components only
+	if !reflect.DeepEqual(options.API, app.Options.API) {
+		slog.Warn("API settings have changed, restart is required to apply them")
+		options.API = app.Options.API
+	}
+	if options.Delivery.QueueDir != app.Options.Delivery.QueueDir ||
+		options.Delivery.DeadLetterFile != app.Options.Delivery.DeadLetterFile {
+		slog.Warn("Delivery queue_dir and dead_letter_file have changed, restart is required to apply them")
+		options.Delivery.QueueDir = app.Options.Delivery.QueueDir
+		options.Delivery.DeadLetterFile = app.Options.Delivery.DeadLetterFile
+	}
+
+	// new queues are created first, as they may fail to load
+	added := make(map[string]*notificatorRuntime)
+	for _, n := range notificatorsList {
+		if _, exists := app.notificators[n.GetName()]; exists {
+			continue
+		}
+		started, err := app.startNotificator(n, options)
+		if err != nil {
+			for _, n := range added {
+				n.stop()
 			}
+			return err
+		}
+		added[n.GetName()] = started
+	}
+	if err := app.maintenance.SetWindows(options.Maintenance); err != nil {
+		for _, n := range added {
+			n.stop()
+		}
+		return err
+	}
 
+	// unchanged notificators keep their instances, which are used by sinks
+	sinks := make(map[string]notificationSink)
+	current := make([]notificators.Notificator, 0, len(notificatorsList))
+	for _, n := range notificatorsList {
+		name := n.GetName()
+		if started, exists := added[name]; exists {
+			slog.Info("Notificator added", "notificator_name", name)
+			app.notificators[name] = started
+		} else if app.notificatorChanged(name, options) {
+			slog.Info("Notificator changed", "notificator_name", name)
+			app.notificators[name].replace(app.ctx, n, options, app.State)
+		} else {
+			n = app.notificators[name].queue.Notificator()
+		}
+		sinks[name] = app.notificators[name].sink
+		current = append(current, n)
+	}
+	for name, started := range app.notificators {
+		if _, exists := sinks[name]; !exists {
+			slog.Info("Notificator removed", "notificator_name", name)
+			started.stop()
+			delete(app.notificators, name)
 		}
+	}
+
+	app.State.SetResources(resourcesList)
+	app.escalator.Reconfigure(sinks, options.Escalations, options.MonitorEscalations)
+	if !reflect.DeepEqual(options.Canary, app.Options.Canary) {
+		app.canary = NewCanary(options.Canary)
+	}
+	app.dispatcher.configure(layout.router, layout.monitorsNotificators, sinks, app.canary, layout.canaryNotificators)
+
+	previousOptions := app.Options
+	app.Resources = resourcesList
+	app.Notificators = current
+	app.Monitors = monitorsList
+	app.Options = options
+
+	kept := make(map[stateKey]bool)
+	for _, m := range monitorsList {
+		monitorChanged := !reflect.DeepEqual(previousOptions.MonitorConfigs[m.GetName()], options.MonitorConfigs[m.GetName()])
+		for _, r := range layout.monitorsResources[m.GetName()] {
+			key := stateKey{monitorName: m.GetName(), resourceName: r.GetName()}
+			kept[key] = true
 
-		// filter notificaotr channels
-		var monitorChannels []chan status.CheckResult
-		for _, nName := range m.GetNotificatorsNames() {
-			notificator, exists := notificatorsChannels[nName]
+			previous, exists := app.runners[key]
+			resourceChanged := !reflect.DeepEqual(previousOptions.ResourceConfigs[r.GetName()], options.ResourceConfigs[r.GetName()])
+			if exists && !monitorChanged && !resourceChanged {
+				continue
+			}
 			if exists {
-				monitorChannels = append(monitorChannels, notificator)
-			} else {
-				return fmt.Errorf("Notificator '%s' not found", nName)
+				previous.cancel()
 			}
+			app.startRunner(m, r, previous)
+		}
+	}
+	for key, started := range app.runners {
+		if kept[key] {
+			continue
 		}
+		slog.Info("Stopping resource monitor", "monitor_name", key.monitorName, "resource_name", key.resourceName)
+		started.cancel()
+		delete(app.runners, key)
+		app.State.Forget(key.monitorName, key.resourceName)
+	}
 
-		for _, r := range monitorResources {
-			runner := monitors.NewMonitorRunner(
-				m,
-				r,
-				monitorChannels,
-				ctx,
-			)
+	slog.Info("Configuration reloaded")
+	return nil
+}
 
-			wg.Add(1)
-			go func() {
-				runner.Run()
-				wg.Done()
-			}()
+// keepHeartbeats passes pings received by previous heartbeat resources to
+// the new ones with the same name, including members of groups
+func keepHeartbeats(previous, next []resources.Resource) {
+	heartbeats := make(map[string]resources.HeartbeatResource)
+	for _, r := range previous {
+		if h, ok := r.(resources.HeartbeatResource); ok {
+			heartbeats[h.GetName()] = h
 		}
+	}
 
+	var keep func(r resources.Resource) resources.Resource
+	keep = func(r resources.Resource) resources.Resource {
+		switch r := r.(type) {
+		case resources.HeartbeatResource:
+			if p, exists := heartbeats[r.GetName()]; exists {
+				return r.KeepState(p)
+			}
+		case resources.GroupResource:
+			members := r.Members()
+			for i := range members {
+				members[i] = keep(members[i])
+			}
+		}
+		return r
+	}
+	for i := range next {
+		next[i] = keep(next[i])
 	}
+}
 
-	slog.Info("Application started")
+// notificatorChanged reports whether the notificator config or its delivery
+// options differ from the running ones
+func (app *Application) notificatorChanged(name string, options Options) bool {
+	return !reflect.DeepEqual(app.Options.NotificatorConfigs[name], options.NotificatorConfigs[name]) ||
+		!reflect.DeepEqual(app.Options.Delivery.Options(name), options.Delivery.Options(name)) ||
+		!reflect.DeepEqual(app.Options.Grouping.Options(name), options.Grouping.Options(name))
+}
 
-	wg.Wait()
-	return nil
+// startNotificator creates delivery queue of the notificator and starts it
+func (app *Application) startNotificator(n notificators.Notificator, options Options) (*notificatorRuntime, error) {
+	queue, err := NewDeliveryQueue(n, options.Delivery.Options(n.GetName()), options.Delivery.QueueDir, app.deadLetter)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(app.ctx)
+	started := &notificatorRuntime{queue: queue, cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(started.done)
+		queue.Run(ctx)
+	}()
+
+	started.configure(ctx, n, options, app.State)
+	return started, nil
+}
+
+// configure replaces notificator of the queue, queued notifications are
+// kept
+func (n *notificatorRuntime) configure(ctx context.Context, notificator notificators.Notificator, options Options, state *State) {
+	if n.stopListen != nil {
+		n.stopListen()
+		n.stopListen = nil
+	}
+	n.queue.Reconfigure(notificator, options.Delivery.Options(notificator.GetName()))
+
+	n.sink = n.queue
+	if grouping := options.Grouping.Options(notificator.GetName()); grouping.GroupWaitSeconds > 0 {
+		n.sink = NewGrouper(n.queue, grouping)
+	}
+
+	if interactive, ok := notificator.(notificators.Interactive); ok {
+		listenCtx, stopListen := context.WithCancel(ctx)
+		n.stopListen = stopListen
+		go interactive.Listen(listenCtx, state)
+	}
+}
+
+// replace configures the runtime with the changed notificator, the
+// previous one is closed after its send in progress
+func (n *notificatorRuntime) replace(ctx context.Context, notificator notificators.Notificator, options Options, state *State) {
+	previous := n.queue.Notificator()
+	n.configure(ctx, notificator, options, state)
+	go func() {
+		n.queue.waitSend()
+		closeNotificator(previous)
+	}()
+}
+
+// stop stops the queue and closes the notificator after the queue has
+// stopped, so it isn't closed while sending
+func (n *notificatorRuntime) stop() {
+	if n.stopListen != nil {
+		n.stopListen()
+	}
+	n.cancel()
+	go func() {
+		<-n.done
+		closeNotificator(n.queue.Notificator())
+	}()
+}
+
+// closeNotificator releases files and connections of the notificator, if
+// it holds them
+func closeNotificator(n notificators.Notificator) {
+	closer, ok := n.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := closer.Close(); err != nil {
+		slog.Warn("Error closing notificator", "notificator_name", n.GetName(), "error", err)
+	}
+}
+
+// startRunner starts checks of the resource by the monitor. If previous
+// runner is set, the new one continues its outage after it has stopped.
+func (app *Application) startRunner(m monitors.Monitor, r resources.Resource, previous *runnerRuntime) {
+	ctx, cancel := context.WithCancel(app.ctx)
+	started := &runnerRuntime{
+		runner: monitors.NewMonitorRunner(m, r, []chan status.CheckResult{app.results}, ctx),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	app.runners[stateKey{monitorName: m.GetName(), resourceName: r.GetName()}] = started
+
+	go func() {
+		defer close(started.done)
+		if previous != nil {
+			<-previous.done
+			started.runner.Resume(previous.runner)
+		}
+		started.runner.Run()
+	}()
 }
 
-func (app Application) listenNotificator(
-	notificator notificators.Notificator,
-	channel <-chan status.CheckResult,
-	ctx context.Context,
+// layout checks that monitors, routes, escalations and canary refer to
+// existing resources and notificators and resolves label selectors
+func (app *Application) layout() (*layout, error) {
+	nameToResource := make(map[string]resources.Resource)
+	for _, r := range app.Resources {
+		nameToResource[r.GetName()] = r
+	}
+	nameToNotificator := make(map[string]notificators.Notificator)
+	for _, n := range app.Notificators {
+		nameToNotificator[n.GetName()] = n
+	}
+
+	router, err := NewRouter(app.Options.Routing)
+	if err != nil {
+		return nil, err
+	}
+	for _, nName := range app.Options.Routing.notificatorsNames() {
+		if _, exists := nameToNotificator[nName]; !exists {
+			return nil, fmt.Errorf("Notificator '%s' not found", nName)
+		}
+	}
+
+	policies := make(map[string]bool)
+	for _, policy := range app.Options.Escalations {
+		policies[policy.Name] = true
+		for _, step := range policy.Steps {
+			for _, nName := range step.Notificators {
+				if _, exists := nameToNotificator[nName]; !exists {
+					return nil, fmt.Errorf("Notificator '%s' not found", nName)
+				}
+			}
+		}
+	}
+	for _, policyName := range app.Options.MonitorEscalations {
+		if !policies[policyName] {
+			return nil, fmt.Errorf("Escalation policy '%s' not found", policyName)
+		}
+	}
+
+	if err := ValidateMaintenance(app.Options.Maintenance); err != nil {
+		return nil, err
+	}
+
+	canaryNotificators := app.Options.Canary.Notificators
+	if len(canaryNotificators) == 0 {
+		for _, n := range app.Notificators {
+			canaryNotificators = append(canaryNotificators, n.GetName())
+		}
+	}
+	for _, nName := range canaryNotificators {
+		if _, exists := nameToNotificator[nName]; !exists {
+			return nil, fmt.Errorf("Notificator '%s' not found", nName)
+		}
+	}
+
+	result := &layout{
+		router:               router,
+		monitorsResources:    make(map[string][]resources.Resource),
+		monitorsNotificators: make(map[string][]string),
+		canaryNotificators:   canaryNotificators,
+	}
+	for _, m := range app.Monitors {
+		monitorResources, err := app.monitorResources(m, nameToResource)
+		if err != nil {
+			return nil, err
+		}
+		if len(monitorResources) == 0 {
+			slog.Warn("Monitor has no resources", "monitor_name", m.GetName())
+		}
+		result.monitorsResources[m.GetName()] = monitorResources
+
+		notificatorsNames, err := app.monitorNotificators(m, nameToNotificator)
+		if err != nil {
+			return nil, err
+		}
+		result.monitorsNotificators[m.GetName()] = notificatorsNames
+	}
+	return result, nil
+}
+
+// MonitorsTargets returns resources and names of notificators of each
+// monitor, resolving label selectors
+func (app *Application) MonitorsTargets() (map[string][]resources.Resource, map[string][]string, error) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	layout, err := app.layout()
+	if err != nil {
+		return nil, nil, err
+	}
+	return layout.monitorsResources, layout.monitorsNotificators, nil
+}
+
+// monitorResources returns resources named by the monitor followed by
+// resources selected by labels
+func (app *Application) monitorResources(
+	m monitors.Monitor,
+	nameToResource map[string]resources.Resource,
+) ([]resources.Resource, error) {
+	var names []string
+	for _, rName := range m.GetResourcesNames() {
+		if _, exists := nameToResource[rName]; !exists {
+			return nil, fmt.Errorf("Resource '%s' not found", rName)
+		}
+		if !slices.Contains(names, rName) {
+			names = append(names, rName)
+		}
+	}
+
+	if selector := m.GetResourceSelector(); !selector.Empty() {
+		for _, r := range app.Resources {
+			if selector.Matches(r.GetLabels()) && !slices.Contains(names, r.GetName()) {
+				names = append(names, r.GetName())
+			}
+		}
+	}
+
+	monitorResources := make([]resources.Resource, 0, len(names))
+	for _, rName := range names {
+		monitorResources = append(monitorResources, nameToResource[rName])
+	}
+	return monitorResources, nil
+}
+
+// monitorNotificators returns names of notificators named by the monitor
+// followed by notificators selected by labels
+func (app *Application) monitorNotificators(
+	m monitors.Monitor,
+	nameToNotificator map[string]notificators.Notificator,
+) ([]string, error) {
+	var names []string
+	for _, nName := range m.GetNotificatorsNames() {
+		if _, exists := nameToNotificator[nName]; !exists {
+			return nil, fmt.Errorf("Notificator '%s' not found", nName)
+		}
+		if !slices.Contains(names, nName) {
+			names = append(names, nName)
+		}
+	}
+
+	if selector := m.GetNotificatorSelector(); !selector.Empty() {
+		for _, n := range app.Notificators {
+			if selector.Matches(app.Options.NotificatorLabels[n.GetName()]) && !slices.Contains(names, n.GetName()) {
+				names = append(names, n.GetName())
+			}
+		}
+	}
+	return names, nil
+}
+
+// dispatcher passes check results from runners to notificators
+type dispatcher struct {
+	state       *State
+	maintenance *Maintenance
+	escalator   *Escalator
+
+	// mu guards routing, which is replaced on reload, and pending results
+	mu                   sync.Mutex
+	canary               *Canary
+	canaryNotificators   []string
+	router               *Router
+	monitorsNotificators map[string][]string
+	notificatorsSinks    map[string]notificationSink
+	// pending holds results received during canary check
+	pending []status.CheckResult
+}
+
+func (d *dispatcher) configure(
+	router *Router,
+	monitorsNotificators map[string][]string,
+	notificatorsSinks map[string]notificationSink,
+	canary *Canary,
+	canaryNotificators []string,
 ) {
-	notificatorName := notificator.GetName()
-	slog.Info("Starting notificator", "notificator_name", notificator.GetName())
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.router = router
+	d.monitorsNotificators = monitorsNotificators
+	d.notificatorsSinks = notificatorsSinks
+	d.canary = canary
+	d.canaryNotificators = canaryNotificators
+}
+
+// canaryVerdict is the result of canary check, which is run apart from
+// the dispatcher goroutine
+type canaryVerdict struct {
+	online bool
+	gap    *notificators.Gap
+}
+
+func (d *dispatcher) run(ctx context.Context, results, summaries <-chan status.CheckResult) {
+	// one canary check is in flight at a time, so its goroutine never
+	// blocks on send
+	verdicts := make(chan canaryVerdict, 1)
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case checkResult := <-channel:
-			if err := notificator.Send(checkResult); err != nil {
-				slog.Error(
-					"Error sending notification",
-					"notificator_name", notificatorName,
-					"error", err,
-				)
-			}
+		case checkResult := <-results:
+			d.handle(checkResult, verdicts)
+		case verdict := <-verdicts:
+			d.applyVerdict(verdict)
+		case checkResult := <-summaries:
+			slog.Info(
+				"Maintenance has ended, resource is still not available",
+				"resource_name", checkResult.ResourceName,
+				"monitor_name", checkResult.MonitorName,
+			)
+			d.mu.Lock()
+			d.escalator.Handle(checkResult)
+			d.notify(checkResult)
+			d.mu.Unlock()
+		}
+	}
+}
+
+// handle records check result and sends it to notificators, unless it is
+// suppressed. If connectivity of the host must be checked first, canaries
+// are pinged in background and the verdict is sent to verdicts. Results
+// received meanwhile are held, so their order is kept.
+func (d *dispatcher) handle(checkResult status.CheckResult, verdicts chan<- canaryVerdict) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.pending) > 0 {
+		d.pending = append(d.pending, checkResult)
+		return
+	}
+	if !d.needsConnectivity(checkResult) {
+		d.process(checkResult)
+		return
+	}
+
+	d.pending = append(d.pending, checkResult)
+	canary := d.canary
+	go func() {
+		online, gap := canary.Check(time.Now())
+		verdicts <- canaryVerdict{online: online, gap: gap}
+	}()
+}
+
+// applyVerdict processes results held until the canary check is done
+func (d *dispatcher) applyVerdict(verdict canaryVerdict) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if verdict.gap != nil {
+		d.state.AddGap(*verdict.gap)
+		hostOnline := hostOnlineResult(*verdict.gap)
+		for _, name := range d.canaryNotificators {
+			d.notificatorsSinks[name].Enqueue(hostOnline)
+		}
+	}
+
+	pending := d.pending
+	d.pending = nil
+	for _, checkResult := range pending {
+		if !verdict.online && d.needsConnectivity(checkResult) {
+			slog.Debug(
+				"Check result dropped, monitoring host is offline",
+				"resource_name", checkResult.ResourceName,
+				"state", checkResult.State,
+			)
+			continue
+		}
+		d.process(checkResult)
+	}
+}
+
+// needsConnectivity reports whether canaries must be checked before the
+// result is processed: on failures and while the host is offline
+func (d *dispatcher) needsConnectivity(checkResult status.CheckResult) bool {
+	if !d.canary.Enabled() {
+		return false
+	}
+	failed := checkResult.State == status.StateNotAvailable || checkResult.State == status.StateStillNotAvailable
+	return failed || d.canary.Offline()
+}
+
+func (d *dispatcher) process(checkResult status.CheckResult) {
+	checkResult = d.state.ResolveDependencies(checkResult)
+
+	// the first failure may have been dropped while the host was offline,
+	// so the outage is reported by the next one
+	if checkResult.State == status.StateStillNotAvailable {
+		if _, exists := d.state.openIncident(checkResult.MonitorName, checkResult.ResourceName); !exists {
+			checkResult.State = status.StateNotAvailable
 		}
-		time.Sleep(time.Second * 1)
+	}
+	sent := d.state.Update(checkResult)
+
+	checkResult, inMaintenance := d.maintenance.Filter(checkResult, time.Now())
+	if inMaintenance {
+		slog.Debug(
+			"Notification suppressed by maintenance",
+			"resource_name", checkResult.ResourceName,
+			"state", checkResult.State,
+		)
+		return
+	}
+
+	// escalation tracks outages even if their reminders are suppressed
+	d.escalator.Handle(checkResult)
+	if !sent {
+		slog.Debug(
+			"Notification suppressed",
+			"resource_name", checkResult.ResourceName,
+			"state", checkResult.State,
+		)
+		return
+	}
+	d.notify(checkResult)
+}
+
+func (d *dispatcher) notify(checkResult status.CheckResult) {
+	// sinks never block, so a slow notificator doesn't delay checks and
+	// other notificators
+	names := d.router.Route(checkResult, d.monitorsNotificators[checkResult.MonitorName], time.Now())
+	for _, name := range names {
+		d.notificatorsSinks[name].Enqueue(checkResult)
 	}
 }
@@ -0,0 +1,207 @@
+package app
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/andrewsapw/avalio/monitors"
+	"github.com/andrewsapw/avalio/notificators"
+	"github.com/andrewsapw/avalio/resources"
+)
+
+func TestApplication_MonitorSelectors(t *testing.T) {
+	monitor, err := monitors.NewCronMonitor(monitors.CronMonitorConfig{
+		MonitorConfig: monitors.MonitorConfig{
+			Name:                "prod",
+			Resources:           []string{"legacy"},
+			Notificators:        []string{"mail"},
+			ResourceSelector:    "env=prod,team notin (infra)",
+			NotificatorSelector: "team=payments",
+		},
+		Cron: "* * * * *",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resourcesList := []resources.Resource{
+		mockedResource{name: "legacy"},
+		mockedResource{name: "api", labels: map[string]string{"env": "prod", "team": "payments"}},
+		mockedResource{name: "gateway", labels: map[string]string{"env": "prod", "team": "infra"}},
+		mockedResource{name: "stage", labels: map[string]string{"env": "stage"}},
+	}
+	notificatorsList := []notificators.Notificator{
+		&mockedNotificator{},
+	}
+	application := NewApplication(resourcesList, notificatorsList, []monitors.Monitor{monitor}, Options{
+		NotificatorLabels: map[string]map[string]string{"mock": {"team": "payments"}},
+	})
+
+	nameToResource := make(map[string]resources.Resource)
+	for _, r := range resourcesList {
+		nameToResource[r.GetName()] = r
+	}
+	selected, err := application.monitorResources(monitor, nameToResource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, r := range selected {
+		names = append(names, r.GetName())
+	}
+	if !slices.Equal(names, []string{"legacy", "api"}) {
+		t.Errorf("Expected named and selected resources, got %v", names)
+	}
+
+	nameToNotificator := map[string]notificators.Notificator{"mail": &mockedNotificator{}, "mock": &mockedNotificator{}}
+	notificatorsNames, err := application.monitorNotificators(monitor, nameToNotificator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(notificatorsNames, []string{"mail", "mock"}) {
+		t.Errorf("Expected named and selected notificators, got %v", notificatorsNames)
+	}
+}
+
+func TestApplication_Reload(t *testing.T) {
+	newMonitor := func(resourcesNames ...string) monitors.Monitor {
+		monitor, err := monitors.NewCronMonitor(monitors.CronMonitorConfig{
+			MonitorConfig: monitors.MonitorConfig{
+				Name:         "every-minute",
+				Resources:    resourcesNames,
+				Notificators: []string{"mock"},
+			},
+			Cron: "* * * * *",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return monitor
+	}
+	resourcesList := []resources.Resource{mockedResource{name: "api"}, mockedResource{name: "db"}}
+	notificatorsList := []notificators.Notificator{&mockedNotificator{}}
+	options := Options{
+		ResourceConfigs:    map[string]any{"api": "https://api", "db": "db:5432"},
+		MonitorConfigs:     map[string]any{"every-minute": "* * * * *"},
+		NotificatorConfigs: map[string]any{"mock": "mock"},
+	}
+
+	application := NewApplication(resourcesList, notificatorsList, []monitors.Monitor{newMonitor("api", "db")}, options)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := application.start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	runners := func() map[stateKey]*runnerRuntime {
+		application.mu.Lock()
+		defer application.mu.Unlock()
+		return maps.Clone(application.runners)
+	}
+	api := stateKey{monitorName: "every-minute", resourceName: "api"}
+	db := stateKey{monitorName: "every-minute", resourceName: "db"}
+	started := runners()
+
+	// unknown notificator is rejected and running configuration is kept
+	invalid := options
+	invalid.Routing = RoutingConfig{Routes: []RouteConfig{{Name: "all", Notificators: []string{"unknown"}}}}
+	if err := application.Reload(resourcesList, notificatorsList, []monitors.Monitor{newMonitor("api", "db")}, invalid); err == nil {
+		t.Fatal("Expected reload with unknown notificator to fail")
+	}
+	if !maps.Equal(runners(), started) {
+		t.Fatal("Expected runners to be kept after failed reload")
+	}
+
+	// db address has changed, api is kept, removed resource is stopped
+	changed := options
+	changed.ResourceConfigs = map[string]any{"api": "https://api", "db": "db:6432"}
+	if err := application.Reload(resourcesList, notificatorsList, []monitors.Monitor{newMonitor("api", "db")}, changed); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := runners()
+	if reloaded[api] != started[api] {
+		t.Error("Expected runner of unchanged resource to be kept")
+	}
+	if reloaded[db] == started[db] {
+		t.Error("Expected runner of changed resource to be restarted")
+	}
+
+	if err := application.Reload(resourcesList[:1], notificatorsList, []monitors.Monitor{newMonitor("api")}, changed); err != nil {
+		t.Fatal(err)
+	}
+	reloaded = runners()
+	if _, exists := reloaded[db]; exists || len(reloaded) != 1 {
+		t.Errorf("Expected only api runner after db removal, got %v", slices.Collect(maps.Keys(reloaded)))
+	}
+	select {
+	case <-started[db].done:
+	case <-time.After(time.Second):
+		t.Error("Expected runner of removed resource to stop")
+	}
+}
+
+// closingNotificator records that it was closed
+type closingNotificator struct {
+	mockedNotificator
+	closed chan struct{}
+}
+
+func (n *closingNotificator) Close() error {
+	close(n.closed)
+	return nil
+}
+
+func TestApplication_ReloadClosesNotificators(t *testing.T) {
+	monitor, err := monitors.NewCronMonitor(monitors.CronMonitorConfig{
+		MonitorConfig: monitors.MonitorConfig{Name: "every-minute", Resources: []string{"api"}},
+		Cron:          "* * * * *",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resourcesList := []resources.Resource{mockedResource{name: "api"}}
+	options := Options{NotificatorConfigs: map[string]any{"mock": "v1"}}
+
+	first := &closingNotificator{closed: make(chan struct{})}
+	application := NewApplication(resourcesList, []notificators.Notificator{first}, []monitors.Monitor{monitor}, options)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := application.start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// changed notificator replaces the running one
+	second := &closingNotificator{closed: make(chan struct{})}
+	changed := options
+	changed.NotificatorConfigs = map[string]any{"mock": "v2"}
+	if err := application.Reload(resourcesList, []notificators.Notificator{second}, []monitors.Monitor{monitor}, changed); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-first.closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected replaced notificator to be closed")
+	}
+
+	// unchanged notificator keeps the running instance
+	third := &closingNotificator{closed: make(chan struct{})}
+	if err := application.Reload(resourcesList, []notificators.Notificator{third}, []monitors.Monitor{monitor}, changed); err != nil {
+		t.Fatal(err)
+	}
+	if len(application.Notificators) != 1 || application.Notificators[0] != second {
+		t.Errorf("Expected running notificator to be kept, got %v", application.Notificators)
+	}
+
+	changed.NotificatorConfigs = nil
+	if err := application.Reload(resourcesList, nil, []monitors.Monitor{monitor}, changed); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-second.closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected removed notificator to be closed")
+	}
+}
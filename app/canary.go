@@ -0,0 +1,162 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrewsapw/avalio/notificators"
+	"github.com/andrewsapw/avalio/resources"
+	"github.com/andrewsapw/avalio/status"
+)
+
+const (
+	defaultCanaryTimeoutSeconds = 5
+	defaultCanaryCacheSeconds   = 30
+)
+
+// [canary]
+// targets = ['1.1.1.1', '8.8.8.8', '9.9.9.9']
+// timeout_seconds = 5
+// notificators = ['bot']
+//
+// Canary targets are pinged before resource failures are reported. If all
+// of them fail, the monitoring host is considered offline.
+type CanaryConfig struct {
+	Targets        []string `toml:"targets"`
+	TimeoutSeconds int      `toml:"timeout_seconds"`
+	// CacheSeconds is how long the result of canary check is reused
+	CacheSeconds int `toml:"cache_seconds"`
+	// Notificators receive notification when the host is online again,
+	// all notificators are used if it is empty
+	Notificators []string `toml:"notificators"`
+}
+
+func (c CanaryConfig) Validate() error {
+	for _, target := range c.Targets {
+		if strings.TrimSpace(target) == "" {
+			return fmt.Errorf("[canary] - targets can't contain empty addresses")
+		}
+	}
+	if c.TimeoutSeconds < 0 {
+		return fmt.Errorf("[canary] - timeout_seconds must be non-negative")
+	}
+	if c.CacheSeconds < 0 {
+		return fmt.Errorf("[canary] - cache_seconds must be non-negative")
+	}
+	return nil
+}
+
+// Canary checks connectivity of the monitoring host. It is disabled if
+// there are no targets.
+type Canary struct {
+	targets  []string
+	timeout  time.Duration
+	cacheTTL time.Duration
+	// probe reports whether the target is reachable, it is replaced by tests
+	probe func(target string, timeout time.Duration) bool
+
+	mu           sync.Mutex
+	checkedAt    time.Time
+	online       bool
+	offlineSince time.Time
+}
+
+func NewCanary(config CanaryConfig) *Canary {
+	timeout := config.TimeoutSeconds
+	if timeout == 0 {
+		timeout = defaultCanaryTimeoutSeconds
+	}
+	cacheSeconds := config.CacheSeconds
+	if cacheSeconds == 0 {
+		cacheSeconds = defaultCanaryCacheSeconds
+	}
+
+	return &Canary{
+		targets:  config.Targets,
+		timeout:  time.Duration(timeout) * time.Second,
+		cacheTTL: time.Duration(cacheSeconds) * time.Second,
+		probe: func(target string, timeout time.Duration) bool {
+			return resources.Ping(context.Background(), target, timeout).Reachable
+		},
+		online: true,
+	}
+}
+
+func (c *Canary) Enabled() bool {
+	return len(c.targets) > 0
+}
+
+// Offline reports whether the last check has failed
+func (c *Canary) Offline() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.online
+}
+
+// Check reports whether the host is online. When the host gets online
+// after being offline, the offline period is returned.
+func (c *Canary) Check(now time.Time) (bool, *notificators.Gap) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.checkedAt.IsZero() && now.Sub(c.checkedAt) < c.cacheTTL {
+		return c.online, nil
+	}
+
+	online := c.anyReachable()
+	c.checkedAt = now
+
+	switch {
+	case !online && c.online:
+		slog.Warn("All canary targets are unreachable, resource alerts are suppressed", "targets", c.targets)
+		c.offlineSince = now
+	case online && !c.online:
+		slog.Info("Canary targets are reachable again", "offline_since", c.offlineSince)
+		c.online = true
+		return true, &notificators.Gap{StartedAt: c.offlineSince, EndedAt: now}
+	}
+	c.online = online
+	return online, nil
+}
+
+// anyReachable pings targets concurrently until the first success
+func (c *Canary) anyReachable() bool {
+	results := make(chan bool, len(c.targets))
+	for _, target := range c.targets {
+		go func() {
+			results <- c.probe(target, c.timeout)
+		}()
+	}
+	for range c.targets {
+		if <-results {
+			return true
+		}
+	}
+	return false
+}
+
+// hostOnlineResult is the notification about the end of the offline period
+func hostOnlineResult(gap notificators.Gap) status.CheckResult {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "avalio"
+	}
+
+	downtime := gap.EndedAt.Sub(gap.StartedAt).Round(time.Second)
+	checkResult := status.NewCheckResult(
+		hostname,
+		"canary",
+		[]status.CheckDetails{
+			status.NewCheckDetails(status.Msg(status.MsgCanary), status.Msg(status.MsgHostOffline, downtime.String())),
+		},
+		status.StateRecovered,
+	)
+	checkResult.CheckedAt = gap.EndedAt
+	checkResult.DownSince = gap.StartedAt
+	return checkResult
+}
@@ -0,0 +1,157 @@
+package app
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+func newTestCanary(online *atomic.Bool) *Canary {
+	canary := NewCanary(CanaryConfig{Targets: []string{"1.1.1.1", "8.8.8.8"}})
+	canary.cacheTTL = 0
+	canary.probe = func(target string, timeout time.Duration) bool {
+		return online.Load() && target == "8.8.8.8"
+	}
+	return canary
+}
+
+func TestCanary_Check(t *testing.T) {
+	var online atomic.Bool
+	online.Store(true)
+	canary := newTestCanary(&online)
+
+	start := time.Now()
+	if ok, gap := canary.Check(start); !ok || gap != nil {
+		t.Fatalf("Expected host to be online, got %v, %v", ok, gap)
+	}
+
+	online.Store(false)
+	if ok, _ := canary.Check(start.Add(time.Minute)); ok || !canary.Offline() {
+		t.Fatal("Expected host to be offline when all targets fail")
+	}
+
+	online.Store(true)
+	ok, gap := canary.Check(start.Add(3 * time.Minute))
+	if !ok || gap == nil {
+		t.Fatalf("Expected offline period after the host is online, got %v, %v", ok, gap)
+	}
+	if gap.EndedAt.Sub(gap.StartedAt) != 2*time.Minute {
+		t.Errorf("Unexpected offline period %+v", gap)
+	}
+}
+
+func TestCanary_Cache(t *testing.T) {
+	var online atomic.Bool
+	canary := newTestCanary(&online)
+	canary.cacheTTL = time.Minute
+
+	start := time.Now()
+	canary.Check(start)
+	online.Store(true)
+	if ok, _ := canary.Check(start.Add(time.Second)); ok {
+		t.Error("Expected cached offline result")
+	}
+	if ok, _ := canary.Check(start.Add(time.Minute)); !ok {
+		t.Error("Expected canaries to be checked after cache expiration")
+	}
+}
+
+func newTestDispatcher(online *atomic.Bool) (*dispatcher, *State, *recordingSink) {
+	state := NewState(nil)
+	maintenance, _ := NewMaintenance(nil)
+	router, _ := NewRouter(RoutingConfig{})
+	sink := &recordingSink{}
+	sinks := map[string]notificationSink{"bot": sink}
+
+	d := &dispatcher{
+		state:                state,
+		canary:               newTestCanary(online),
+		canaryNotificators:   []string{"bot"},
+		maintenance:          maintenance,
+		escalator:            NewEscalator(state, maintenance, sinks, nil, nil),
+		router:               router,
+		monitorsNotificators: map[string][]string{"every-minute": {"bot"}},
+		notificatorsSinks:    sinks,
+	}
+	return d, state, sink
+}
+
+// dispatch handles the result and waits for the canary check, if it was
+// started
+func dispatch(d *dispatcher, checkResult status.CheckResult) {
+	verdicts := make(chan canaryVerdict, 1)
+	d.handle(checkResult, verdicts)
+
+	d.mu.Lock()
+	pending := len(d.pending) > 0
+	d.mu.Unlock()
+	if pending {
+		d.applyVerdict(<-verdicts)
+	}
+}
+
+func TestDispatcher_HostOffline(t *testing.T) {
+	var online atomic.Bool
+	d, state, sink := newTestDispatcher(&online)
+
+	dispatch(d, newResult(status.StateNotAvailable))
+	dispatch(d, newResult(status.StateStillNotAvailable))
+	if states := sink.states(); len(states) != 0 {
+		t.Fatalf("Expected failures to be dropped while offline, got %v", states)
+	}
+	if incidents := state.Incidents(); len(incidents) != 0 {
+		t.Errorf("Expected no incidents while offline, got %+v", incidents)
+	}
+
+	online.Store(true)
+	dispatch(d, newResult(status.StateRecovered))
+
+	states := sink.states()
+	if len(states) != 1 || states[0] != status.StateRecovered || sink.results[0].ResourceType != "canary" {
+		t.Fatalf("Expected only host online notification, got %+v", sink.results)
+	}
+	if gaps := state.Gaps(); len(gaps) != 1 {
+		t.Errorf("Expected offline period to be recorded, got %+v", gaps)
+	}
+}
+
+func TestDispatcher_OutageDuringOffline(t *testing.T) {
+	var online atomic.Bool
+	d, state, sink := newTestDispatcher(&online)
+
+	dispatch(d, newResult(status.StateNotAvailable))
+
+	online.Store(true)
+	dispatch(d, newResult(status.StateStillNotAvailable))
+
+	states := sink.states()
+	if len(states) != 2 || states[0] != status.StateRecovered || states[1] != status.StateNotAvailable {
+		t.Fatalf("Expected host online and outage notifications, got %v", states)
+	}
+	if incidents := state.Incidents(); len(incidents) != 1 {
+		t.Errorf("Expected incident to be opened, got %+v", incidents)
+	}
+}
+
+func TestDispatcher_PendingDuringCanaryCheck(t *testing.T) {
+	var online atomic.Bool
+	online.Store(true)
+	d, _, sink := newTestDispatcher(&online)
+
+	verdicts := make(chan canaryVerdict, 1)
+	d.handle(newResult(status.StateNotAvailable), verdicts)
+	// the result received during canary check is processed after the
+	// failure, not before it
+	d.handle(newResult(status.StateRecovered), verdicts)
+	if states := sink.states(); len(states) != 0 {
+		t.Fatalf("Expected results to be held during canary check, got %v", states)
+	}
+
+	d.applyVerdict(<-verdicts)
+	states := sink.states()
+	if len(states) != 2 || states[0] != status.StateNotAvailable || states[1] != status.StateRecovered {
+		t.Errorf("Expected results in order of receiving, got %v", states)
+	}
+}
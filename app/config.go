@@ -1,26 +1,175 @@
 package app
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
 	"github.com/BurntSushi/toml"
+	"github.com/andrewsapw/avalio/api"
 	"github.com/andrewsapw/avalio/monitors"
 	"github.com/andrewsapw/avalio/notificators"
 	"github.com/andrewsapw/avalio/resources"
 )
 
+// mergedSections are config sections, whose arrays are combined from all
+// config files. Other values may be defined in one file only.
+var mergedSections = []string{"resources", "notificators", "monitors"}
+
 type Config struct {
+	// Include holds glob patterns of files, which are merged into the root
+	// config, relative paths are resolved from the root config directory
+	Include      []string                        `toml:"include"`
 	LogLevel     string                          `toml:"log_level"`
+	Language     string                          `toml:"language"`
 	Resources    resources.ResourcesConfig       `toml:"resources"`
 	Notificators notificators.NotificatorsConfig `toml:"notificators"`
 	Monitors     monitors.MonitorsConfig         `toml:"monitors"`
+	Delivery     DeliveryConfig                  `toml:"delivery"`
+	Grouping     GroupingConfig                  `toml:"grouping"`
+	Routing      RoutingConfig                   `toml:"routing"`
+	Escalations  []EscalationPolicyConfig        `toml:"escalations"`
+	Maintenance  []MaintenanceWindowConfig       `toml:"maintenance"`
+	Canary       CanaryConfig                    `toml:"canary"`
+	API          api.Config                      `toml:"api"`
+
+	// source holds positions of values in config files for errors
+	source *configSource
 }
 
-func ParseConfig(configPath string) (*Config, error) {
-	var config Config
+// ConfigFiles returns files of the config. If configPath is a directory,
+// these are its *.toml files in name order. Otherwise these are the file
+// followed by its includes.
+func ConfigFiles(configPath string) ([]string, error) {
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		files, err := filepath.Glob(filepath.Join(configPath, "*.toml"))
+		if err == nil && len(files) == 0 {
+			err = fmt.Errorf("%s: no *.toml files in the config directory", configPath)
+		}
+		return files, err
+	}
+
+	var root struct {
+		Include []string `toml:"include"`
+	}
+	if _, err := toml.DecodeFile(configPath, &root); err != nil {
+		return nil, fmt.Errorf("%s: %v", configPath, err)
+	}
 
-	_, err := toml.DecodeFile(configPath, &config)
+	files := []string{configPath}
+	for _, pattern := range root.Include {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(configPath), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid include '%s': %v", configPath, pattern, err)
+		}
+		for _, match := range matches {
+			if !slices.Contains(files, match) {
+				files = append(files, match)
+			}
+		}
+	}
+	return files, nil
+}
+
+// ParseConfig reads config files, replaces environment variables
+// references and reads secret files. Resources, notificators and monitors
+// of all files are merged. Unknown keys and values defined in several files
+// of all files are returned together, errors name the file and line.
+func ParseConfig(configPath string) (*Config, error) {
+	files, err := ConfigFiles(configPath)
 	if err != nil {
 		return nil, err
 	}
 
+	config := Config{source: newConfigSource()}
+	var errs []error
+	// definedIn maps keys of not merged values to files, where they are
+	// defined
+	definedIn := make(map[string]string)
+	for i, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var part Config
+		meta, err := toml.Decode(string(data), &part)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		source := newConfigSource()
+		source.index(file, data)
+		config.source.merge(source)
+
+		if len(part.Include) > 0 && (i > 0 || configPath != file) {
+			errs = append(errs, located(source.key("include"), fmt.Errorf("include is allowed only in the root config file")))
+		}
+		var undecoded []string
+		for _, key := range meta.Undecoded() {
+			undecoded = append(undecoded, key.String())
+		}
+		errs = append(errs, unknownKeys(undecoded, source)...)
+
+		if err := interpolate(reflect.ValueOf(&part), ""); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", file, err))
+			continue
+		}
+		if err := part.loadSecrets(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", file, err))
+			continue
+		}
+
+		errs = append(errs, mergeConfig(reflect.ValueOf(&config).Elem(), reflect.ValueOf(part), meta, nil, source, definedIn)...)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
 	return &config, nil
 }
+
+// mergeConfig copies values defined in the part to the config. Arrays of
+// merged sections are appended, other values must not be defined twice.
+func mergeConfig(
+	config, part reflect.Value,
+	meta toml.MetaData,
+	key []string,
+	source *configSource,
+	definedIn map[string]string,
+) []error {
+	var errs []error
+	for i := 0; i < config.NumField(); i++ {
+		name, _, _ := strings.Cut(config.Type().Field(i).Tag.Get("toml"), ",")
+		fieldKey := append(slices.Clone(key), name)
+		if name == "include" || !meta.IsDefined(fieldKey...) {
+			continue
+		}
+
+		field := config.Field(i)
+		switch {
+		case len(key) == 0 && slices.Contains(mergedSections, name):
+			errs = append(errs, mergeConfig(field, part.Field(i), meta, fieldKey, source, definedIn)...)
+		case len(key) > 0 && field.Kind() == reflect.Slice:
+			field.Set(reflect.AppendSlice(field, part.Field(i)))
+		default:
+			id := strings.Join(fieldKey, ".")
+			if previous, exists := definedIn[id]; exists {
+				errs = append(errs, fmt.Errorf("%s: '%s' is already defined at %s", source.key(id), id, previous))
+				continue
+			}
+			definedIn[id] = source.key(id)
+			field.Set(part.Field(i))
+		}
+	}
+	return errs
+}
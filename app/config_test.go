@@ -0,0 +1,227 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseConfig_Interpolation(t *testing.T) {
+	t.Setenv("AVALIO_TEST_HOST", "example.com")
+	t.Setenv("AVALIO_TEST_EMPTY", "")
+
+	path := writeConfig(t, `
+[[resources.http]]
+name = 'site'
+url = 'https://${AVALIO_TEST_HOST}/health'
+labels = { env = '${AVALIO_TEST_ENV:-prod}', team = '${AVALIO_TEST_EMPTY:-infra}' }
+
+[[monitors.cron]]
+name = 'every-minute'
+resources = ['site']
+cron = '$${not interpolated}'
+`)
+	config, err := ParseConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource := config.Resources.Http[0]
+	if resource.Url != "https://example.com/health" {
+		t.Errorf("Expected url to be interpolated, got %s", resource.Url)
+	}
+	if resource.Labels["env"] != "prod" || resource.Labels["team"] != "infra" {
+		t.Errorf("Expected defaults for unset and empty variables, got %v", resource.Labels)
+	}
+	if cron := config.Monitors.Cron[0].Cron; cron != "${not interpolated}" {
+		t.Errorf("Expected escaped reference to be kept, got %s", cron)
+	}
+}
+
+func TestParseConfig_MissingVariable(t *testing.T) {
+	path := writeConfig(t, `
+[[notificators.telegram]]
+name = 'bot'
+chat_id = '1'
+token = '${AVALIO_TEST_MISSING}'
+`)
+	_, err := ParseConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "notificators.telegram[0].token") ||
+		!strings.Contains(err.Error(), "'AVALIO_TEST_MISSING' is not set") {
+		t.Errorf("Expected error about missing variable with its location, got %v", err)
+	}
+}
+
+func TestParseConfig_SecretFiles(t *testing.T) {
+	secretPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(secretPath, []byte("123:secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AVALIO_TEST_SECRET", secretPath)
+
+	path := writeConfig(t, `
+[[notificators.telegram]]
+name = 'bot'
+chat_id = '1'
+token_file = '${AVALIO_TEST_SECRET}'
+
+[api]
+token = 'api-token'
+`)
+	config, err := ParseConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token := config.Notificators.Telegram[0].Token; token != "123:secret" {
+		t.Errorf("Expected token to be read from file, got %q", token)
+	}
+	secrets := config.Secrets()
+	if len(secrets) != 2 || secrets[0] != "123:secret" || secrets[1] != "api-token" {
+		t.Errorf("Expected telegram and API tokens as secrets, got %v", secrets)
+	}
+
+	path = writeConfig(t, `
+[[notificators.matrix]]
+name = 'ops'
+password_file = '/nonexistent/password'
+`)
+	if _, err := ParseConfig(path); err == nil || !strings.Contains(err.Error(), "[[notificator.matrix]] ops - password") {
+		t.Errorf("Expected error about missing secret file, got %v", err)
+	}
+
+	path = writeConfig(t, `
+[api]
+token = 'api-token'
+token_file = '`+secretPath+`'
+`)
+	if _, err := ParseConfig(path); err == nil || !strings.Contains(err.Error(), "can't be both set") {
+		t.Errorf("Expected error about both token and token_file, got %v", err)
+	}
+
+	emptyPath := filepath.Join(t.TempDir(), "empty")
+	if err := os.WriteFile(emptyPath, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path = writeConfig(t, `
+[api]
+listen = '0.0.0.0:8080'
+token_file = '`+emptyPath+`'
+`)
+	if _, err := ParseConfig(path); err == nil || !strings.Contains(err.Error(), "[api] - token - secret file") {
+		t.Errorf("Expected error about empty secret file, got %v", err)
+	}
+}
+
+func TestParseConfig_Include(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.toml": `
+include = ['conf.d/*.toml']
+log_level = 'debug'
+
+[[notificators.console]]
+name = 'console'
+`,
+		"conf.d/api.toml": `
+[[resources.http]]
+name = 'api'
+url = 'https://api.example.com'
+
+[[monitors.cron]]
+name = 'api'
+resources = ['api']
+notificators = ['console']
+cron = '* * * * *'
+`,
+		"conf.d/db.toml": `
+[[resources.ping]]
+name = 'db'
+address = 'db.example.com'
+
+[[resources.http]]
+name = 'admin'
+url = 'https://admin.example.com'
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config, err := ParseConfig(filepath.Join(dir, "config.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := config.Resources.Names(); !slices.Equal(names, []string{"api", "admin", "db"}) {
+		t.Errorf("Expected resources of all files, got %v", names)
+	}
+	if config.LogLevel != "debug" || len(config.Monitors.Cron) != 1 || len(config.Notificators.Console) != 1 {
+		t.Errorf("Expected root and included values, got %+v", config)
+	}
+
+	// duplicate names and values name both files
+	duplicate := filepath.Join(dir, "conf.d/web.toml")
+	if err := os.WriteFile(duplicate, []byte("[[resources.ping]]\nname = 'api'\naddress = 'web'\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config, err = ParseConfig(filepath.Join(dir, "config.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = config.Validate()
+	if err == nil || !strings.Contains(err.Error(), "web.toml:1: duplicate resource name 'api', it is already defined at "+filepath.Join(dir, "conf.d/api.toml:2")) {
+		t.Errorf("Expected duplicate name error, got %v", err)
+	}
+
+	if err := os.WriteFile(duplicate, []byte("log_level = 'error'\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ParseConfig(filepath.Join(dir, "config.toml"))
+	if err == nil || !strings.Contains(err.Error(), "web.toml:1: 'log_level' is already defined at "+filepath.Join(dir, "config.toml:3")) {
+		t.Errorf("Expected conflict error, got %v", err)
+	}
+}
+
+func TestParseConfig_Directory(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.toml": "[[notificators.console]]\nname = 'console'\n",
+		"b.toml": "[[notificators.console]]\nname = 'audit'\n",
+		"c.txt":  "not a config",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config, err := ParseConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := config.Notificators.Names(); !slices.Equal(names, []string{"console", "audit"}) {
+		t.Errorf("Expected notificators of both files in name order, got %v", names)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "d.toml"), []byte("include = ['*.conf']\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseConfig(dir); err == nil || !strings.Contains(err.Error(), "include is allowed only in the root config file") {
+		t.Errorf("Expected include error, got %v", err)
+	}
+}
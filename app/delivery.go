@@ -0,0 +1,499 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/andrewsapw/avalio/notificators"
+	"github.com/andrewsapw/avalio/status"
+)
+
+const (
+	defaultQueueSize             = 100
+	defaultMaxAttempts           = 5
+	defaultInitialBackoffSeconds = 5
+	defaultMaxBackoffSeconds     = 300
+	defaultRateLimitPerMinute    = 60
+)
+
+// DeliveryOptions configure delivery of notifications to a notificator.
+// Zero values are replaced with defaults.
+type DeliveryOptions struct {
+	QueueSize             int `toml:"queue_size"`
+	MaxAttempts           int `toml:"max_attempts"`
+	InitialBackoffSeconds int `toml:"initial_backoff_seconds"`
+	MaxBackoffSeconds     int `toml:"max_backoff_seconds"`
+	RateLimitPerMinute    int `toml:"rate_limit_per_minute"`
+}
+
+func (o DeliveryOptions) Validate() error {
+	if o.QueueSize < 0 {
+		return fmt.Errorf("queue_size must be non-negative")
+	}
+	if o.MaxAttempts < 0 {
+		return fmt.Errorf("max_attempts must be non-negative")
+	}
+	if o.InitialBackoffSeconds < 0 || o.MaxBackoffSeconds < 0 {
+		return fmt.Errorf("backoff must be non-negative")
+	}
+	if o.RateLimitPerMinute < 0 {
+		return fmt.Errorf("rate_limit_per_minute must be non-negative")
+	}
+	return nil
+}
+
+// merge returns options with zero values taken from base
+func (o DeliveryOptions) merge(base DeliveryOptions) DeliveryOptions {
+	if o.QueueSize == 0 {
+		o.QueueSize = base.QueueSize
+	}
+	if o.MaxAttempts == 0 {
+		o.MaxAttempts = base.MaxAttempts
+	}
+	if o.InitialBackoffSeconds == 0 {
+		o.InitialBackoffSeconds = base.InitialBackoffSeconds
+	}
+	if o.MaxBackoffSeconds == 0 {
+		o.MaxBackoffSeconds = base.MaxBackoffSeconds
+	}
+	if o.RateLimitPerMinute == 0 {
+		o.RateLimitPerMinute = base.RateLimitPerMinute
+	}
+	return o
+}
+
+// [delivery]
+// max_attempts = 5
+// queue_dir = '/var/lib/avalio/queue'
+// dead_letter_file = '/var/lib/avalio/dead-letter.jsonl'
+//
+// [delivery.notificators.bot]
+// rate_limit_per_minute = 20
+type DeliveryConfig struct {
+	DeliveryOptions
+	// QueueDir enables persistence of queued notifications between restarts
+	QueueDir string `toml:"queue_dir"`
+	// DeadLetterFile receives notifications, which were not delivered
+	DeadLetterFile string `toml:"dead_letter_file"`
+	// Notificators override options per notificator name
+	Notificators map[string]DeliveryOptions `toml:"notificators"`
+}
+
+func (c DeliveryConfig) Validate() error {
+	if err := c.DeliveryOptions.Validate(); err != nil {
+		return fmt.Errorf("[delivery] - %v", err)
+	}
+	for name, options := range c.Notificators {
+		if err := options.Validate(); err != nil {
+			return fmt.Errorf("[delivery.notificators.%s] - %v", name, err)
+		}
+	}
+	return nil
+}
+
+// Options returns delivery options of the notificator with defaults applied
+func (c DeliveryConfig) Options(notificatorName string) DeliveryOptions {
+	defaults := DeliveryOptions{
+		QueueSize:             defaultQueueSize,
+		MaxAttempts:           defaultMaxAttempts,
+		InitialBackoffSeconds: defaultInitialBackoffSeconds,
+		MaxBackoffSeconds:     defaultMaxBackoffSeconds,
+		RateLimitPerMinute:    defaultRateLimitPerMinute,
+	}
+	return c.Notificators[notificatorName].merge(c.DeliveryOptions.merge(defaults))
+}
+
+// deliveryItem is a queued notification, either a single check result or
+// a digest of grouped results
+type deliveryItem struct {
+	ID          int                  `json:"id"`
+	CheckResult status.CheckResult   `json:"check_result,omitzero"`
+	Digest      *notificators.Digest `json:"digest,omitempty"`
+	Attempts    int                  `json:"attempts"`
+	NextAttempt time.Time            `json:"next_attempt,omitzero"`
+	LastError   string               `json:"last_error,omitempty"`
+}
+
+// DeliveryQueue delivers notifications to a single notificator in order of
+// arrival. Failed notification is retried with exponential backoff before
+// the next one is sent, so a recovery never overtakes its outage.
+type DeliveryQueue struct {
+	name       string
+	path       string
+	deadLetter *DeadLetterLog
+
+	// sending is held while the notificator sends, so the replaced one is
+	// closed after its last send
+	sending sync.Mutex
+	// mu guards notificator and options too, they are replaced on reload
+	mu          sync.Mutex
+	notificator notificators.Notificator
+	size        int
+	maxAttempts int
+	// initialBackoff is doubled after every failed attempt up to maxBackoff
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	// interval is the minimal time between two sends
+	interval time.Duration
+	items    []deliveryItem
+	nextID   int
+	// inFlight is ID of the item being sent, if isSending is set. It is not
+	// dropped on overflow, as it may be already delivered.
+	inFlight  int
+	isSending bool
+	lastSent  time.Time
+	wakeup    chan struct{}
+}
+
+// NewDeliveryQueue creates queue for the notificator. If queueDir is set,
+// notifications queued before restart are loaded from it.
+func NewDeliveryQueue(
+	notificator notificators.Notificator,
+	options DeliveryOptions,
+	queueDir string,
+	deadLetter *DeadLetterLog,
+) (*DeliveryQueue, error) {
+	q := &DeliveryQueue{
+		name:       notificator.GetName(),
+		deadLetter: deadLetter,
+		nextID:     1,
+		wakeup:     make(chan struct{}, 1),
+	}
+	q.configure(notificator, options)
+
+	if queueDir == "" {
+		return q, nil
+	}
+
+	if err := os.MkdirAll(queueDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create queue directory: %v", err)
+	}
+	q.path = filepath.Join(queueDir, url.PathEscape(notificator.GetName())+".json")
+
+	data, err := os.ReadFile(q.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue of '%s': %v", notificator.GetName(), err)
+	}
+	if err := json.Unmarshal(data, &q.items); err != nil {
+		return nil, fmt.Errorf("failed to decode queue of '%s': %v", notificator.GetName(), err)
+	}
+	for i := range q.items {
+		q.items[i].ID = q.nextID
+		q.nextID++
+	}
+	if len(q.items) > 0 {
+		slog.Info("Loaded queued notifications", "notificator_name", notificator.GetName(), "count", len(q.items))
+	}
+	return q, nil
+}
+
+// Reconfigure replaces notificator of the same name and delivery options,
+// queued notifications are kept
+func (q *DeliveryQueue) Reconfigure(notificator notificators.Notificator, options DeliveryOptions) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.configure(notificator, options)
+}
+
+// Notificator returns the current notificator of the queue
+func (q *DeliveryQueue) Notificator() notificators.Notificator {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.notificator
+}
+
+// waitSend blocks until the send in progress, if any, is finished
+func (q *DeliveryQueue) waitSend() {
+	q.sending.Lock()
+	q.sending.Unlock()
+}
+
+func (q *DeliveryQueue) configure(notificator notificators.Notificator, options DeliveryOptions) {
+	q.notificator = notificator
+	q.size = options.QueueSize
+	q.maxAttempts = options.MaxAttempts
+	q.initialBackoff = time.Duration(options.InitialBackoffSeconds) * time.Second
+	q.maxBackoff = time.Duration(options.MaxBackoffSeconds) * time.Second
+	q.interval = 0
+	if options.RateLimitPerMinute > 0 {
+		q.interval = time.Minute / time.Duration(options.RateLimitPerMinute)
+	}
+}
+
+// Enqueue adds notification to the queue without blocking. If the queue is
+// full, the oldest notification, which is not being sent, is dropped to the
+// dead letter log.
+func (q *DeliveryQueue) Enqueue(checkResult status.CheckResult) {
+	q.enqueue(deliveryItem{CheckResult: checkResult})
+}
+
+// EnqueueDigest adds digest to the queue, the notificator must implement
+// notificators.DigestSender
+func (q *DeliveryQueue) EnqueueDigest(digest notificators.Digest) {
+	q.enqueue(deliveryItem{Digest: &digest})
+}
+
+func (q *DeliveryQueue) enqueue(item deliveryItem) {
+	q.mu.Lock()
+	item.ID = q.nextID
+	q.nextID++
+	q.items = append(q.items, item)
+	var dropped []deliveryItem
+	if overflow := len(q.items) - q.size; overflow > 0 {
+		kept := make([]deliveryItem, 0, q.size)
+		for _, queued := range q.items {
+			if overflow > 0 && !(q.isSending && queued.ID == q.inFlight) {
+				dropped = append(dropped, queued)
+				overflow--
+				continue
+			}
+			kept = append(kept, queued)
+		}
+		q.items = kept
+	}
+	q.persist()
+	q.mu.Unlock()
+
+	for _, item := range dropped {
+		q.fail(item, "queue is full")
+	}
+
+	select {
+	case q.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// Len returns number of queued notifications
+func (q *DeliveryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Run delivers queued notifications until ctx is done
+func (q *DeliveryQueue) Run(ctx context.Context) {
+	slog.Info("Starting notificator", "notificator_name", q.name)
+
+	for ctx.Err() == nil {
+		wait, ok := q.nextWait()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.wakeup:
+				continue
+			}
+		}
+
+		if wait > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.wakeup:
+				// the queue may be trimmed, recalculate the wait
+				continue
+			case <-time.After(wait):
+			}
+		}
+
+		q.deliver()
+	}
+}
+
+// nextWait returns time to wait before the head of the queue can be sent,
+// ok is false if the queue is empty
+func (q *DeliveryQueue) nextWait() (time.Duration, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) == 0 {
+		return 0, false
+	}
+	next := q.items[0].NextAttempt
+	if rateLimited := q.lastSent.Add(q.interval); rateLimited.After(next) {
+		next = rateLimited
+	}
+	return time.Until(next), true
+}
+
+// deliver sends the head of the queue and reschedules it on failure
+func (q *DeliveryQueue) deliver() {
+	q.mu.Lock()
+	if len(q.items) == 0 {
+		q.mu.Unlock()
+		return
+	}
+	item := q.items[0]
+	notificator := q.notificator
+	q.inFlight, q.isSending = item.ID, true
+	q.sending.Lock()
+	q.mu.Unlock()
+
+	var err error
+	if item.Digest != nil {
+		err = sendDigest(notificator, *item.Digest)
+	} else {
+		err = notificator.Send(item.CheckResult)
+	}
+	q.sending.Unlock()
+
+	q.mu.Lock()
+	q.isSending = false
+	q.lastSent = time.Now()
+	// Enqueue keeps the item being sent, it is at the head still
+	if len(q.items) == 0 || q.items[0].ID != item.ID {
+		q.mu.Unlock()
+		return
+	}
+
+	if err == nil {
+		q.items = q.items[1:]
+		q.persist()
+		q.mu.Unlock()
+		return
+	}
+
+	item.Attempts++
+	item.LastError = err.Error()
+	if item.Attempts >= q.maxAttempts {
+		q.items = q.items[1:]
+		q.persist()
+		q.mu.Unlock()
+		q.fail(item, item.LastError)
+		return
+	}
+
+	delay := q.backoff(item.Attempts)
+	item.NextAttempt = time.Now().Add(delay)
+	q.items[0] = item
+	q.persist()
+	q.mu.Unlock()
+
+	slog.Warn(
+		"Error sending notification, will retry",
+		"notificator_name", q.name,
+		"attempt", item.Attempts,
+		"retry_in", delay.Round(time.Millisecond),
+		"error", err,
+	)
+}
+
+// sendDigest sends digest as a single message, or result by result, if the
+// notificator doesn't support digests, e.g. after it was replaced on reload
+func sendDigest(notificator notificators.Notificator, digest notificators.Digest) error {
+	if sender, ok := notificator.(notificators.DigestSender); ok {
+		return sender.SendDigest(digest)
+	}
+	for _, checkResult := range digest.Results {
+		if err := notificator.Send(checkResult); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backoff returns delay before the next attempt with random jitter, so
+// notificators failed at the same time don't retry simultaneously
+func (q *DeliveryQueue) backoff(attempts int) time.Duration {
+	delay := q.initialBackoff
+	for i := 1; i < attempts && delay < q.maxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, q.maxBackoff)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// fail records permanently failed notification
+func (q *DeliveryQueue) fail(item deliveryItem, reason string) {
+	resourceName := item.CheckResult.ResourceName
+	if item.Digest != nil {
+		resourceName = fmt.Sprintf("%d resources", len(item.Digest.Results))
+	}
+	slog.Error(
+		"Notification was not delivered",
+		"notificator_name", q.name,
+		"resource_name", resourceName,
+		"attempts", item.Attempts,
+		"error", reason,
+	)
+	if q.deadLetter != nil {
+		if err := q.deadLetter.Write(q.name, item, reason); err != nil {
+			slog.Error("Error writing dead letter", "notificator_name", q.name, "error", err)
+		}
+	}
+}
+
+// persist writes queue to disk, it must be called with mu held
+func (q *DeliveryQueue) persist() {
+	if q.path == "" {
+		return
+	}
+
+	data, err := json.Marshal(q.items)
+	if err == nil {
+		// write to temporary file first, so the queue is never truncated
+		tmpPath := q.path + ".tmp"
+		if err = os.WriteFile(tmpPath, data, 0o600); err == nil {
+			err = os.Rename(tmpPath, q.path)
+		}
+	}
+	if err != nil {
+		slog.Error("Error saving notification queue", "notificator_name", q.name, "error", err)
+	}
+}
+
+// DeadLetterLog appends notifications, which were not delivered, to a file
+// as JSON lines
+type DeadLetterLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewDeadLetterLog(path string) *DeadLetterLog {
+	if path == "" {
+		return nil
+	}
+	return &DeadLetterLog{path: path}
+}
+
+func (l *DeadLetterLog) Write(notificatorName string, item deliveryItem, reason string) error {
+	line, err := json.Marshal(struct {
+		NotificatorName string               `json:"notificator_name"`
+		CheckResult     status.CheckResult   `json:"check_result,omitzero"`
+		Digest          *notificators.Digest `json:"digest,omitempty"`
+		Attempts        int                  `json:"attempts"`
+		Error           string               `json:"error"`
+		FailedAt        time.Time            `json:"failed_at"`
+	}{notificatorName, item.CheckResult, item.Digest, item.Attempts, reason, time.Now()})
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,270 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+// mockedNotificator fails the given number of sends before succeeding
+type mockedNotificator struct {
+	mu       sync.Mutex
+	failures int
+	sent     []status.CheckResult
+}
+
+func (n *mockedNotificator) GetName() string { return "mock" }
+
+func (n *mockedNotificator) Send(checkResult status.CheckResult) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.failures > 0 {
+		n.failures--
+		return errors.New("service unavailable")
+	}
+	n.sent = append(n.sent, checkResult)
+	return nil
+}
+
+func (n *mockedNotificator) sentStates() []status.ResourceState {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var states []status.ResourceState
+	for _, checkResult := range n.sent {
+		states = append(states, checkResult.State)
+	}
+	return states
+}
+
+func newTestQueue(t *testing.T, notificator *mockedNotificator, options DeliveryOptions, queueDir string) (*DeliveryQueue, string) {
+	deadLetterPath := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	queue, err := NewDeliveryQueue(notificator, DeliveryConfig{DeliveryOptions: options}.Options("mock"), queueDir, NewDeadLetterLog(deadLetterPath))
+	if err != nil {
+		t.Fatalf("Expected NewDeliveryQueue() to succeed, got %v", err)
+	}
+	queue.initialBackoff = time.Millisecond
+	queue.maxBackoff = 5 * time.Millisecond
+	queue.interval = 0
+	return queue, deadLetterPath
+}
+
+func readDeadLetters(t *testing.T, path string) []map[string]any {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var letters []map[string]any
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var letter map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &letter); err != nil {
+			t.Fatal(err)
+		}
+		letters = append(letters, letter)
+	}
+	return letters
+}
+
+func TestDeliveryQueue_RetriesInOrder(t *testing.T) {
+	notificator := &mockedNotificator{failures: 2}
+	queue, _ := newTestQueue(t, notificator, DeliveryOptions{}, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go queue.Run(ctx)
+
+	queue.Enqueue(newResult(status.StateNotAvailable))
+	queue.Enqueue(newResult(status.StateRecovered))
+
+	deadline := time.Now().Add(2 * time.Second)
+	for queue.Len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	states := notificator.sentStates()
+	if len(states) != 2 || states[0] != status.StateNotAvailable || states[1] != status.StateRecovered {
+		t.Errorf("Expected outage and recovery to be delivered in order, got %v", states)
+	}
+}
+
+func TestDeliveryQueue_Reconfigure(t *testing.T) {
+	previous := &mockedNotificator{}
+	queue, _ := newTestQueue(t, previous, DeliveryOptions{}, "")
+	queue.Enqueue(newResult(status.StateNotAvailable))
+
+	// queued notifications are delivered by the new notificator
+	notificator := &mockedNotificator{}
+	queue.Reconfigure(notificator, DeliveryConfig{}.Options("mock"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go queue.Run(ctx)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for queue.Len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if states := previous.sentStates(); len(states) != 0 {
+		t.Errorf("Expected previous notificator to receive nothing, got %v", states)
+	}
+	if states := notificator.sentStates(); len(states) != 1 || states[0] != status.StateNotAvailable {
+		t.Errorf("Expected queued outage to be delivered, got %v", states)
+	}
+}
+
+func TestDeliveryQueue_DeadLetter(t *testing.T) {
+	notificator := &mockedNotificator{failures: 10}
+	queue, deadLetterPath := newTestQueue(t, notificator, DeliveryOptions{MaxAttempts: 2, QueueSize: 2}, "")
+
+	queue.Enqueue(newResult(status.StateNotAvailable))
+	queue.deliver()
+	if queue.Len() != 1 {
+		t.Fatalf("Expected notification to stay queued after the first failure")
+	}
+	queue.deliver()
+	if queue.Len() != 0 {
+		t.Fatalf("Expected notification to be removed after the last attempt")
+	}
+
+	queue.Enqueue(newResult(status.StateStillNotAvailable))
+	queue.Enqueue(newResult(status.StateStillNotAvailable))
+	queue.Enqueue(newResult(status.StateRecovered))
+	if queue.Len() != 2 {
+		t.Errorf("Expected queue to be bounded by 2, got %d", queue.Len())
+	}
+
+	letters := readDeadLetters(t, deadLetterPath)
+	if len(letters) != 2 {
+		t.Fatalf("Expected 2 dead letters, got %d", len(letters))
+	}
+	if letters[0]["error"] != "service unavailable" || letters[0]["attempts"] != float64(2) {
+		t.Errorf("Unexpected dead letter %v", letters[0])
+	}
+	if letters[1]["error"] != "queue is full" {
+		t.Errorf("Expected dropped notification in dead letters, got %v", letters[1])
+	}
+}
+
+// blockingNotificator blocks Send until release is closed
+type blockingNotificator struct {
+	mockedNotificator
+	started chan struct{}
+	release chan struct{}
+}
+
+func (n *blockingNotificator) Send(checkResult status.CheckResult) error {
+	close(n.started)
+	<-n.release
+	return n.mockedNotificator.Send(checkResult)
+}
+
+func TestDeliveryQueue_OverflowKeepsInFlight(t *testing.T) {
+	notificator := &blockingNotificator{started: make(chan struct{}), release: make(chan struct{})}
+	deadLetterPath := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	queue, err := NewDeliveryQueue(notificator, DeliveryConfig{DeliveryOptions: DeliveryOptions{QueueSize: 2}}.Options("mock"), "", NewDeadLetterLog(deadLetterPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queue.Enqueue(newResult(status.StateNotAvailable))
+	queue.Enqueue(newResult(status.StateStillNotAvailable))
+	done := make(chan struct{})
+	go func() {
+		queue.deliver()
+		close(done)
+	}()
+	<-notificator.started
+
+	// the head is being sent, so the next one is dropped
+	queue.Enqueue(newResult(status.StateRecovered))
+	close(notificator.release)
+	<-done
+
+	if states := notificator.sentStates(); len(states) != 1 || states[0] != status.StateNotAvailable {
+		t.Fatalf("Expected in-flight notification to be sent, got %v", states)
+	}
+	if queue.Len() != 1 {
+		t.Errorf("Expected only the newest notification to stay queued, got %d", queue.Len())
+	}
+	letters := readDeadLetters(t, deadLetterPath)
+	if len(letters) != 1 || letters[0]["error"] != "queue is full" {
+		t.Fatalf("Expected single dropped notification, got %v", letters)
+	}
+	if result, _ := letters[0]["check_result"].(map[string]any); result["state"] != "still not available" {
+		t.Errorf("Expected the oldest not in-flight notification to be dropped, got %v", letters[0])
+	}
+}
+
+func TestDeliveryQueue_Persistence(t *testing.T) {
+	queueDir := t.TempDir()
+
+	failing := &mockedNotificator{failures: 1}
+	queue, _ := newTestQueue(t, failing, DeliveryOptions{}, queueDir)
+	queue.Enqueue(newResult(status.StateNotAvailable))
+	queue.Enqueue(newResult(status.StateRecovered))
+	queue.deliver()
+
+	// notifications survive restart, including retry state
+	notificator := &mockedNotificator{}
+	restored, _ := newTestQueue(t, notificator, DeliveryOptions{}, queueDir)
+	if restored.Len() != 2 {
+		t.Fatalf("Expected 2 restored notifications, got %d", restored.Len())
+	}
+	if restored.items[0].Attempts != 1 || restored.items[0].CheckResult.MonitorName != "every-minute" {
+		t.Errorf("Unexpected restored notification %+v", restored.items[0])
+	}
+
+	restored.deliver()
+	restored.deliver()
+	if states := notificator.sentStates(); len(states) != 2 || states[1] != status.StateRecovered {
+		t.Errorf("Expected restored notifications to be delivered, got %v", states)
+	}
+
+	empty, _ := newTestQueue(t, notificator, DeliveryOptions{}, queueDir)
+	if empty.Len() != 0 {
+		t.Errorf("Expected delivered notifications to be removed from disk, got %d", empty.Len())
+	}
+}
+
+func TestDeliveryQueue_Backoff(t *testing.T) {
+	queue := &DeliveryQueue{initialBackoff: time.Second, maxBackoff: 10 * time.Second}
+
+	cases := map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 10: 10 * time.Second}
+	for attempts, expected := range cases {
+		delay := queue.backoff(attempts)
+		if delay < expected/2 || delay > expected {
+			t.Errorf("Expected backoff in [%v, %v] after %d attempts, got %v", expected/2, expected, attempts, delay)
+		}
+	}
+}
+
+func TestDeliveryConfig_Options(t *testing.T) {
+	config := DeliveryConfig{
+		DeliveryOptions: DeliveryOptions{MaxAttempts: 3},
+		Notificators:    map[string]DeliveryOptions{"bot": {RateLimitPerMinute: 20}},
+	}
+
+	options := config.Options("bot")
+	if options.MaxAttempts != 3 || options.RateLimitPerMinute != 20 || options.QueueSize != defaultQueueSize {
+		t.Errorf("Unexpected bot options %+v", options)
+	}
+	if options := config.Options("other"); options.RateLimitPerMinute != defaultRateLimitPerMinute {
+		t.Errorf("Expected default rate limit, got %+v", options)
+	}
+}
@@ -0,0 +1,234 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+// [[escalations.steps]]
+// delay_minutes = 15
+// notificators = ['oncall-bot']
+type EscalationStepConfig struct {
+	// DelayMinutes is counted from the start of the outage
+	DelayMinutes int      `toml:"delay_minutes"`
+	Notificators []string `toml:"notificators"`
+}
+
+// [[escalations]]
+// name = 'oncall'
+//
+// [[escalations.steps]]
+// delay_minutes = 15
+// notificators = ['oncall-bot']
+type EscalationPolicyConfig struct {
+	Name  string                 `toml:"name"`
+	Steps []EscalationStepConfig `toml:"steps"`
+}
+
+func (c EscalationPolicyConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("[[escalations]] - name can't be empty")
+	}
+	if len(c.Steps) == 0 {
+		return fmt.Errorf("[[escalations]] %s - steps can't be empty", c.Name)
+	}
+	for _, step := range c.Steps {
+		if step.DelayMinutes < 0 {
+			return fmt.Errorf("[[escalations]] %s - delay_minutes must be non-negative", c.Name)
+		}
+		if len(step.Notificators) == 0 {
+			return fmt.Errorf("[[escalations]] %s - step notificators can't be empty", c.Name)
+		}
+	}
+	return nil
+}
+
+// ValidateEscalations checks policies and their names uniqueness
+func ValidateEscalations(policies []EscalationPolicyConfig) error {
+	var names []string
+	for _, policy := range policies {
+		if err := policy.Validate(); err != nil {
+			return err
+		}
+		if slices.Contains(names, policy.Name) {
+			return fmt.Errorf("[[escalations]] %s - duplicate name", policy.Name)
+		}
+		names = append(names, policy.Name)
+	}
+	return nil
+}
+
+// Escalator notifies next tiers about outages, which are not acknowledged
+// in time. Steps are cancelled when the resource recovers, is acknowledged
+// or muted.
+type Escalator struct {
+	state       *State
+	maintenance *Maintenance
+	// minute is the unit of step delays, it is shortened by tests
+	minute time.Duration
+
+	mu       sync.Mutex
+	sinks    map[string]notificationSink
+	policies map[string]EscalationPolicyConfig
+	// monitors maps monitor name to its escalation policy name
+	monitors map[string]string
+	active   map[stateKey]*escalation
+}
+
+// escalation is an outage, which is being escalated
+type escalation struct {
+	timers []*time.Timer
+	// last is the latest check result of the outage
+	last status.CheckResult
+	// notified holds notificators, which were notified by escalation steps
+	notified []string
+}
+
+func NewEscalator(
+	state *State,
+	maintenance *Maintenance,
+	sinks map[string]notificationSink,
+	policies []EscalationPolicyConfig,
+	monitors map[string]string,
+) *Escalator {
+	e := &Escalator{
+		state:       state,
+		maintenance: maintenance,
+		minute:      time.Minute,
+		active:      make(map[stateKey]*escalation),
+	}
+	e.configure(sinks, policies, monitors)
+	return e
+}
+
+// Reconfigure replaces sinks and policies. Running escalations keep steps
+// of their policies.
+func (e *Escalator) Reconfigure(
+	sinks map[string]notificationSink,
+	policies []EscalationPolicyConfig,
+	monitors map[string]string,
+) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.configure(sinks, policies, monitors)
+}
+
+func (e *Escalator) configure(
+	sinks map[string]notificationSink,
+	policies []EscalationPolicyConfig,
+	monitors map[string]string,
+) {
+	nameToPolicy := make(map[string]EscalationPolicyConfig)
+	for _, policy := range policies {
+		nameToPolicy[policy.Name] = policy
+	}
+	e.sinks = sinks
+	e.policies = nameToPolicy
+	e.monitors = monitors
+}
+
+// Handle starts escalation on outage and stops it on recovery. Recovery is
+// sent to notificators the outage was escalated to.
+func (e *Escalator) Handle(checkResult status.CheckResult) {
+	key := stateKey{monitorName: checkResult.MonitorName, resourceName: checkResult.ResourceName}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	current := e.active[key]
+	policy, exists := e.policies[e.monitors[checkResult.MonitorName]]
+	if !exists && current == nil {
+		return
+	}
+
+	switch checkResult.State {
+	case status.StateUnreachable:
+		// the outage is covered by the failed dependency
+		if current != nil {
+			current.last = checkResult
+		}
+	case status.StateNotAvailable, status.StateStillNotAvailable:
+		if current != nil {
+			current.last = checkResult
+			return
+		}
+
+		startedAt := checkResult.DownSince
+		if startedAt.IsZero() {
+			startedAt = checkResult.CheckedAt
+		}
+
+		current = &escalation{last: checkResult}
+		for i, step := range policy.Steps {
+			delay := time.Until(startedAt.Add(time.Duration(step.DelayMinutes) * e.minute))
+			current.timers = append(current.timers, time.AfterFunc(delay, func() {
+				e.escalate(key, policy.Name, i, step)
+			}))
+		}
+		e.active[key] = current
+	default:
+		if current == nil {
+			return
+		}
+		for _, timer := range current.timers {
+			timer.Stop()
+		}
+		delete(e.active, key)
+
+		for _, name := range current.notified {
+			// the notificator may be removed on reload
+			if sink, exists := e.sinks[name]; exists {
+				sink.Enqueue(checkResult)
+			}
+		}
+	}
+}
+
+// escalate runs escalation step, if the outage is still open, not
+// acknowledged, not muted and not in maintenance
+func (e *Escalator) escalate(key stateKey, policyName string, stepIndex int, step EscalationStepConfig) {
+	incident, open := e.state.openIncident(key.monitorName, key.resourceName)
+	if !open || incident.IsAcknowledged() || e.state.IsMuted(key.resourceName) {
+		return
+	}
+
+	e.mu.Lock()
+	current := e.active[key]
+	if current == nil ||
+		current.last.State == status.StateUnreachable ||
+		e.maintenance.Covers(current.last, time.Now()) {
+		e.mu.Unlock()
+		return
+	}
+	// the tier receives the outage as a new one
+	checkResult := current.last
+	checkResult.State = status.StateNotAvailable
+	var sinks []notificationSink
+	for _, name := range step.Notificators {
+		sink, exists := e.sinks[name]
+		if !exists {
+			continue
+		}
+		sinks = append(sinks, sink)
+		if !slices.Contains(current.notified, name) {
+			current.notified = append(current.notified, name)
+		}
+	}
+	e.mu.Unlock()
+
+	slog.Info(
+		"Escalating outage",
+		"escalation_name", policyName,
+		"step", stepIndex+1,
+		"monitor_name", key.monitorName,
+		"resource_name", key.resourceName,
+	)
+	for _, sink := range sinks {
+		sink.Enqueue(checkResult)
+	}
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+// recordingSink records enqueued check results
+type recordingSink struct {
+	mu      sync.Mutex
+	results []status.CheckResult
+}
+
+func (s *recordingSink) Enqueue(checkResult status.CheckResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = append(s.results, checkResult)
+}
+
+func (s *recordingSink) states() []status.ResourceState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var states []status.ResourceState
+	for _, checkResult := range s.results {
+		states = append(states, checkResult.State)
+	}
+	return states
+}
+
+func newTestEscalator(state *State) (*Escalator, *recordingSink, *recordingSink) {
+	oncall, managers := &recordingSink{}, &recordingSink{}
+	policies := []EscalationPolicyConfig{{
+		Name: "oncall",
+		Steps: []EscalationStepConfig{
+			{DelayMinutes: 1, Notificators: []string{"oncall"}},
+			{DelayMinutes: 3, Notificators: []string{"managers"}},
+		},
+	}}
+	maintenance, _ := NewMaintenance(nil)
+	escalator := NewEscalator(
+		state,
+		maintenance,
+		map[string]notificationSink{"oncall": oncall, "managers": managers},
+		policies,
+		map[string]string{"every-minute": "oncall"},
+	)
+	escalator.minute = 20 * time.Millisecond
+	return escalator, oncall, managers
+}
+
+// update records the result in the state and passes it to escalator
+func update(state *State, escalator *Escalator, checkResult status.CheckResult) {
+	state.Update(checkResult)
+	escalator.Handle(checkResult)
+}
+
+func TestEscalator_Steps(t *testing.T) {
+	state := NewState(nil)
+	escalator, oncall, managers := newTestEscalator(state)
+
+	update(state, escalator, newResult(status.StateNotAvailable))
+	update(state, escalator, newResult(status.StateStillNotAvailable))
+
+	time.Sleep(30 * time.Millisecond)
+	if states := oncall.states(); len(states) != 1 || states[0] != status.StateNotAvailable {
+		t.Fatalf("Expected first tier to receive the outage, got %v", states)
+	}
+	if states := managers.states(); len(states) != 0 {
+		t.Fatalf("Expected second tier to wait, got %v", states)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if states := managers.states(); len(states) != 1 {
+		t.Fatalf("Expected second tier to receive the outage, got %v", states)
+	}
+
+	update(state, escalator, newResult(status.StateRecovered))
+	if states := oncall.states(); len(states) != 2 || states[1] != status.StateRecovered {
+		t.Errorf("Expected first tier to receive the recovery, got %v", states)
+	}
+	if states := managers.states(); len(states) != 2 || states[1] != status.StateRecovered {
+		t.Errorf("Expected second tier to receive the recovery, got %v", states)
+	}
+}
+
+func TestEscalator_Acknowledged(t *testing.T) {
+	state := NewState(nil)
+	escalator, oncall, managers := newTestEscalator(state)
+
+	update(state, escalator, newResult(status.StateNotAvailable))
+	time.Sleep(30 * time.Millisecond)
+	if err := state.Acknowledge("api", "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if states := managers.states(); len(states) != 0 {
+		t.Errorf("Expected acknowledged outage not to be escalated, got %v", states)
+	}
+
+	update(state, escalator, newResult(status.StateRecovered))
+	if states := oncall.states(); len(states) != 2 {
+		t.Errorf("Expected only notified tier to receive the recovery, got %v", states)
+	}
+	if states := managers.states(); len(states) != 0 {
+		t.Errorf("Expected second tier not to receive the recovery, got %v", states)
+	}
+}
+
+func TestEscalator_RecoveredBeforeFirstStep(t *testing.T) {
+	state := NewState(nil)
+	escalator, oncall, _ := newTestEscalator(state)
+
+	update(state, escalator, newResult(status.StateNotAvailable))
+	update(state, escalator, newResult(status.StateRecovered))
+
+	time.Sleep(30 * time.Millisecond)
+	if states := oncall.states(); len(states) != 0 {
+		t.Errorf("Expected short outage not to be escalated, got %v", states)
+	}
+}
+
+func TestValidateEscalations(t *testing.T) {
+	cases := map[string][]EscalationPolicyConfig{
+		"empty name": {{Steps: []EscalationStepConfig{{Notificators: []string{"bot"}}}}},
+		"no steps":   {{Name: "oncall"}},
+		"no targets": {{Name: "oncall", Steps: []EscalationStepConfig{{DelayMinutes: 5}}}},
+		"negative":   {{Name: "oncall", Steps: []EscalationStepConfig{{DelayMinutes: -1, Notificators: []string{"bot"}}}}},
+		"duplicate":  {{Name: "a", Steps: []EscalationStepConfig{{Notificators: []string{"bot"}}}}, {Name: "a", Steps: []EscalationStepConfig{{Notificators: []string{"bot"}}}}},
+	}
+	for name, policies := range cases {
+		if err := ValidateEscalations(policies); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
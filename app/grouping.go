@@ -0,0 +1,229 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrewsapw/avalio/notificators"
+	"github.com/andrewsapw/avalio/status"
+)
+
+const defaultGroupIntervalSeconds = 300
+
+// Group keys, which are not labels
+const (
+	groupByMonitor      = "monitor"
+	groupByResourceType = "resource_type"
+)
+
+// GroupingOptions configure grouping of notifications sent to a notificator.
+// Grouping is disabled if GroupWaitSeconds is zero.
+type GroupingOptions struct {
+	// GroupBy lists "monitor", "resource_type" and label names. Results with
+	// the same values are grouped together.
+	GroupBy              []string `toml:"group_by"`
+	GroupWaitSeconds     int      `toml:"group_wait_seconds"`
+	GroupIntervalSeconds int      `toml:"group_interval_seconds"`
+}
+
+func (o GroupingOptions) Validate() error {
+	for _, key := range o.GroupBy {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("group_by can't contain empty keys")
+		}
+	}
+	if o.GroupWaitSeconds < 0 {
+		return fmt.Errorf("group_wait_seconds must be non-negative")
+	}
+	if o.GroupIntervalSeconds < 0 {
+		return fmt.Errorf("group_interval_seconds must be non-negative")
+	}
+	return nil
+}
+
+// [grouping]
+// group_by = ['monitor']
+// group_wait_seconds = 30
+// group_interval_seconds = 300
+//
+// [grouping.notificators.bot]
+// group_by = ['env']
+type GroupingConfig struct {
+	GroupingOptions
+	// Notificators override options per notificator name
+	Notificators map[string]GroupingOptions `toml:"notificators"`
+}
+
+func (c GroupingConfig) Validate() error {
+	if err := c.GroupingOptions.Validate(); err != nil {
+		return fmt.Errorf("[grouping] - %v", err)
+	}
+	for name, options := range c.Notificators {
+		if err := options.Validate(); err != nil {
+			return fmt.Errorf("[grouping.notificators.%s] - %v", name, err)
+		}
+	}
+	return nil
+}
+
+// Options returns grouping options of the notificator, fields of the
+// notificator section override global ones
+func (c GroupingConfig) Options(notificatorName string) GroupingOptions {
+	options := c.GroupingOptions
+	if override, exists := c.Notificators[notificatorName]; exists {
+		if override.GroupBy != nil {
+			options.GroupBy = override.GroupBy
+		}
+		if override.GroupWaitSeconds != 0 {
+			options.GroupWaitSeconds = override.GroupWaitSeconds
+		}
+		if override.GroupIntervalSeconds != 0 {
+			options.GroupIntervalSeconds = override.GroupIntervalSeconds
+		}
+	}
+	if options.GroupIntervalSeconds == 0 {
+		options.GroupIntervalSeconds = defaultGroupIntervalSeconds
+	}
+	return options
+}
+
+// Grouper buffers outages and recoveries for group_wait and sends them to
+// the delivery queue as a single digest, later transitions of the same group
+// are sent every group_interval. Other results are passed through.
+type Grouper struct {
+	queue    *DeliveryQueue
+	digests  bool
+	groupBy  []string
+	wait     time.Duration
+	interval time.Duration
+
+	mu     sync.Mutex
+	groups map[string]*resultGroup
+}
+
+type resultGroup struct {
+	labels  map[string]string
+	pending []status.CheckResult
+}
+
+func NewGrouper(queue *DeliveryQueue, options GroupingOptions) *Grouper {
+	queue.mu.Lock()
+	_, digests := queue.notificator.(notificators.DigestSender)
+	queue.mu.Unlock()
+	return &Grouper{
+		queue:    queue,
+		digests:  digests,
+		groupBy:  options.GroupBy,
+		wait:     time.Duration(options.GroupWaitSeconds) * time.Second,
+		interval: time.Duration(options.GroupIntervalSeconds) * time.Second,
+		groups:   make(map[string]*resultGroup),
+	}
+}
+
+// Enqueue adds check result to its group
+func (g *Grouper) Enqueue(checkResult status.CheckResult) {
+	key, labels := g.groupKey(checkResult)
+
+	g.mu.Lock()
+	group := g.groups[key]
+
+	switch checkResult.State {
+	case status.StateNotAvailable, status.StateRecovered:
+	case status.StateStillNotAvailable:
+		// reminder must not overtake the outage, which is not sent yet
+		if group != nil && group.pendingOutage(checkResult) {
+			g.mu.Unlock()
+			return
+		}
+		fallthrough
+	default:
+		g.mu.Unlock()
+		g.queue.Enqueue(checkResult)
+		return
+	}
+
+	if group == nil {
+		group = &resultGroup{labels: labels}
+		g.groups[key] = group
+		time.AfterFunc(g.wait, func() { g.flush(key) })
+	}
+	group.add(checkResult)
+	g.mu.Unlock()
+}
+
+// flush sends pending results of the group. Group without new results is
+// removed, so the next outage waits for group_wait again.
+func (g *Grouper) flush(key string) {
+	g.mu.Lock()
+	group := g.groups[key]
+	if group == nil {
+		g.mu.Unlock()
+		return
+	}
+	pending := group.pending
+	group.pending = nil
+	if len(pending) == 0 {
+		delete(g.groups, key)
+		g.mu.Unlock()
+		return
+	}
+	time.AfterFunc(g.interval, func() { g.flush(key) })
+	g.mu.Unlock()
+
+	if len(pending) == 1 || !g.digests {
+		for _, checkResult := range pending {
+			g.queue.Enqueue(checkResult)
+		}
+		return
+	}
+	g.queue.EnqueueDigest(notificators.Digest{Group: group.labels, Results: pending})
+}
+
+func (g *Grouper) groupKey(checkResult status.CheckResult) (string, map[string]string) {
+	labels := make(map[string]string, len(g.groupBy))
+	parts := make([]string, 0, len(g.groupBy))
+	for _, name := range g.groupBy {
+		var value string
+		switch name {
+		case groupByMonitor:
+			value = checkResult.MonitorName
+		case groupByResourceType:
+			value = checkResult.ResourceType
+		default:
+			value = checkResult.Labels[name]
+		}
+		labels[name] = value
+		parts = append(parts, name+"="+value)
+	}
+	return strings.Join(parts, ","), labels
+}
+
+// add records transition of a resource. Outage, which recovers before it is
+// sent, and recovery, followed by a new outage, cancel each other.
+func (g *resultGroup) add(checkResult status.CheckResult) {
+	i := slices.IndexFunc(g.pending, func(pending status.CheckResult) bool {
+		return sameResource(pending, checkResult)
+	})
+	if i < 0 {
+		g.pending = append(g.pending, checkResult)
+		return
+	}
+	if g.pending[i].State != checkResult.State {
+		g.pending = slices.Delete(g.pending, i, i+1)
+		return
+	}
+	g.pending[i] = checkResult
+}
+
+func (g *resultGroup) pendingOutage(checkResult status.CheckResult) bool {
+	return slices.ContainsFunc(g.pending, func(pending status.CheckResult) bool {
+		return sameResource(pending, checkResult) && pending.State == status.StateNotAvailable
+	})
+}
+
+func sameResource(a, b status.CheckResult) bool {
+	return a.MonitorName == b.MonitorName && a.ResourceName == b.ResourceName
+}
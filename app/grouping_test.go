@@ -0,0 +1,117 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewsapw/avalio/notificators"
+	"github.com/andrewsapw/avalio/status"
+)
+
+type mockedDigestNotificator struct {
+	mockedNotificator
+}
+
+func (n *mockedDigestNotificator) SendDigest(digest notificators.Digest) error { return nil }
+
+func newTestGrouper(t *testing.T, notificator notificators.Notificator, groupBy []string) (*Grouper, *DeliveryQueue) {
+	queue, err := NewDeliveryQueue(notificator, DeliveryConfig{}.Options("mock"), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grouper := NewGrouper(queue, GroupingOptions{GroupBy: groupBy, GroupWaitSeconds: 1, GroupIntervalSeconds: 1})
+	grouper.wait = 20 * time.Millisecond
+	grouper.interval = 200 * time.Millisecond
+	return grouper, queue
+}
+
+func resourceResult(name string, env string, state status.ResourceState) status.CheckResult {
+	checkResult := status.NewCheckResult(name, "http", nil, state)
+	checkResult.MonitorName = "every-minute"
+	checkResult.Labels = map[string]string{"env": env}
+	return checkResult
+}
+
+// queued returns queued items after the group window is closed
+func queued(queue *DeliveryQueue, wait time.Duration) []deliveryItem {
+	time.Sleep(wait)
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	return append([]deliveryItem(nil), queue.items...)
+}
+
+func TestGrouper_Digest(t *testing.T) {
+	grouper, queue := newTestGrouper(t, &mockedDigestNotificator{}, []string{"env"})
+
+	grouper.Enqueue(resourceResult("api", "prod", status.StateNotAvailable))
+	grouper.Enqueue(resourceResult("db", "prod", status.StateNotAvailable))
+	grouper.Enqueue(resourceResult("stage", "stage", status.StateNotAvailable))
+	// reminders and available results are not grouped
+	grouper.Enqueue(resourceResult("web", "prod", status.StateAvailable))
+	// reminder about outage, which is not sent yet, is dropped
+	grouper.Enqueue(resourceResult("api", "prod", status.StateStillNotAvailable))
+
+	if items := queued(queue, 0); len(items) != 1 || items[0].CheckResult.ResourceName != "web" {
+		t.Fatalf("Expected only available result to pass through, got %+v", items)
+	}
+
+	items := queued(queue, 50*time.Millisecond)
+	if len(items) != 3 {
+		t.Fatalf("Expected digest and single result, got %d items", len(items))
+	}
+
+	var digest *notificators.Digest
+	for _, item := range items[1:] {
+		if item.Digest != nil {
+			digest = item.Digest
+		} else if item.CheckResult.ResourceName != "stage" {
+			t.Errorf("Expected single stage result, got %+v", item.CheckResult)
+		}
+	}
+	if digest == nil || len(digest.Results) != 2 || digest.Group["env"] != "prod" {
+		t.Fatalf("Expected prod digest with 2 results, got %+v", digest)
+	}
+
+	// later transitions are sent after group_interval
+	grouper.Enqueue(resourceResult("api", "prod", status.StateRecovered))
+	if items := queued(queue, 20*time.Millisecond); len(items) != 3 {
+		t.Errorf("Expected recovery to wait for group_interval, got %d items", len(items))
+	}
+	if items := queued(queue, 250*time.Millisecond); len(items) != 4 || items[3].CheckResult.State != status.StateRecovered {
+		t.Errorf("Expected recovery after group_interval, got %+v", items)
+	}
+}
+
+func TestGrouper_FlappingAndFallback(t *testing.T) {
+	grouper, queue := newTestGrouper(t, &mockedNotificator{}, nil)
+
+	grouper.Enqueue(resourceResult("api", "prod", status.StateNotAvailable))
+	grouper.Enqueue(resourceResult("api", "prod", status.StateRecovered))
+	grouper.Enqueue(resourceResult("db", "prod", status.StateNotAvailable))
+	grouper.Enqueue(resourceResult("web", "prod", status.StateNotAvailable))
+
+	items := queued(queue, 50*time.Millisecond)
+	if len(items) != 2 {
+		t.Fatalf("Expected flapping resource to be dropped, got %d items", len(items))
+	}
+	for _, item := range items {
+		if item.Digest != nil {
+			t.Error("Expected results to be sent one by one to notificator without digests")
+		}
+	}
+}
+
+func TestGroupingConfig_Options(t *testing.T) {
+	config := GroupingConfig{
+		GroupingOptions: GroupingOptions{GroupBy: []string{"monitor"}, GroupWaitSeconds: 30},
+		Notificators:    map[string]GroupingOptions{"bot": {GroupBy: []string{"env"}}},
+	}
+
+	options := config.Options("bot")
+	if len(options.GroupBy) != 1 || options.GroupBy[0] != "env" || options.GroupWaitSeconds != 30 {
+		t.Errorf("Unexpected bot options %+v", options)
+	}
+	if options.GroupIntervalSeconds != defaultGroupIntervalSeconds {
+		t.Errorf("Expected default group interval, got %d", options.GroupIntervalSeconds)
+	}
+}
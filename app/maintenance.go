@@ -0,0 +1,375 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/andrewsapw/avalio/notificators"
+	"github.com/andrewsapw/avalio/status"
+	"github.com/robfig/cron/v3"
+)
+
+// summaryInterval is how often ended windows and silences are checked
+const summaryInterval = 10 * time.Second
+
+// maintenanceScope selects check results by resource, monitor and labels.
+// All non-empty conditions must match.
+type maintenanceScope struct {
+	resources []string
+	monitors  []string
+	labels    map[string]string
+}
+
+func (s maintenanceScope) isEmpty() bool {
+	return len(s.resources) == 0 && len(s.monitors) == 0 && len(s.labels) == 0
+}
+
+func (s maintenanceScope) matches(checkResult status.CheckResult) bool {
+	if len(s.resources) > 0 && !slices.Contains(s.resources, checkResult.ResourceName) {
+		return false
+	}
+	if len(s.monitors) > 0 && !slices.Contains(s.monitors, checkResult.MonitorName) {
+		return false
+	}
+	for name, value := range s.labels {
+		if checkResult.Labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// [[maintenance]]
+// name = 'weekly deploy'
+// resources = ['api']
+// cron = '0 3 * * sun'
+// duration_minutes = 60
+//
+// [[maintenance]]
+// name = 'database migration'
+// labels = { team = 'payments' }
+// start = 2025-06-01T22:00:00+03:00
+// end = 2025-06-02T02:00:00+03:00
+//
+// Window is either one-off with start and end, or recurring with cron and
+// duration_minutes.
+type MaintenanceWindowConfig struct {
+	Name      string            `toml:"name"`
+	Resources []string          `toml:"resources"`
+	Monitors  []string          `toml:"monitors"`
+	Labels    map[string]string `toml:"labels"`
+
+	Start time.Time `toml:"start"`
+	End   time.Time `toml:"end"`
+
+	Cron            string `toml:"cron"`
+	DurationMinutes int    `toml:"duration_minutes"`
+	Timezone        string `toml:"timezone"`
+}
+
+func (c MaintenanceWindowConfig) Validate() error {
+	if _, err := c.compile(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// compile parses window schedule, errors mention the window name
+func (c MaintenanceWindowConfig) compile() (*maintenanceWindow, error) {
+	fail := func(format string, args ...any) (*maintenanceWindow, error) {
+		return nil, fmt.Errorf("[[maintenance]] %s - %s", c.Name, fmt.Sprintf(format, args...))
+	}
+
+	if c.Name == "" {
+		return fail("name can't be empty")
+	}
+
+	window := &maintenanceWindow{
+		config:   c,
+		scope:    maintenanceScope{resources: c.Resources, monitors: c.Monitors, labels: c.Labels},
+		location: time.Local,
+	}
+	if window.scope.isEmpty() {
+		return fail("at least one of resources, monitors or labels must be set")
+	}
+
+	oneOff := !c.Start.IsZero() || !c.End.IsZero()
+	switch {
+	case oneOff && c.Cron != "":
+		return fail("start and end can't be used with cron")
+	case oneOff:
+		if c.Start.IsZero() || c.End.IsZero() {
+			return fail("both start and end must be set")
+		}
+		if !c.End.After(c.Start) {
+			return fail("end must be after start")
+		}
+	case c.Cron != "":
+		if c.DurationMinutes <= 0 {
+			return fail("duration_minutes must be positive")
+		}
+		parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+		schedule, err := parser.Parse(c.Cron)
+		if err != nil {
+			return fail("invalid cron '%s': %v", c.Cron, err)
+		}
+		window.schedule = schedule
+		window.duration = time.Duration(c.DurationMinutes) * time.Minute
+	default:
+		return fail("either start and end or cron must be set")
+	}
+
+	if c.Timezone != "" {
+		location, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return fail("invalid timezone '%s': %v", c.Timezone, err)
+		}
+		window.location = location
+	}
+	return window, nil
+}
+
+// ValidateMaintenance checks windows and their names uniqueness
+func ValidateMaintenance(windows []MaintenanceWindowConfig) error {
+	var names []string
+	for _, window := range windows {
+		if err := window.Validate(); err != nil {
+			return err
+		}
+		if slices.Contains(names, window.Name) {
+			return fmt.Errorf("[[maintenance]] %s - duplicate name", window.Name)
+		}
+		names = append(names, window.Name)
+	}
+	return nil
+}
+
+// maintenanceWindow is a compiled maintenance window config
+type maintenanceWindow struct {
+	config   MaintenanceWindowConfig
+	scope    maintenanceScope
+	schedule cron.Schedule
+	duration time.Duration
+	location *time.Location
+}
+
+func (w *maintenanceWindow) active(now time.Time) bool {
+	if w.schedule == nil {
+		return !now.Before(w.config.Start) && now.Before(w.config.End)
+	}
+	// the window is active if it has started within the last duration
+	start := w.schedule.Next(now.Add(-w.duration).In(w.location))
+	return !start.After(now)
+}
+
+// suppressedResult is the last suppressed result of a resource
+type suppressedResult struct {
+	checkResult status.CheckResult
+	// by names window or silence, which has suppressed the result
+	by string
+}
+
+// Maintenance suppresses notifications during maintenance windows and
+// silences. When a window ends and the resource is still not available,
+// the outage is sent as a new one with the window name in details.
+type Maintenance struct {
+	mu            sync.Mutex
+	windows       []*maintenanceWindow
+	silences      []notificators.Silence
+	nextSilenceID int
+	suppressed    map[stateKey]suppressedResult
+}
+
+// NewMaintenance compiles windows, which are expected to be validated
+func NewMaintenance(windows []MaintenanceWindowConfig) (*Maintenance, error) {
+	m := &Maintenance{
+		nextSilenceID: 1,
+		suppressed:    make(map[stateKey]suppressedResult),
+	}
+	if err := m.SetWindows(windows); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SetWindows replaces maintenance windows, silences are kept
+func (m *Maintenance) SetWindows(windows []MaintenanceWindowConfig) error {
+	var compiled []*maintenanceWindow
+	for _, config := range windows {
+		window, err := config.compile()
+		if err != nil {
+			return err
+		}
+		compiled = append(compiled, window)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.windows = compiled
+	return nil
+}
+
+// Filter returns the result, which should be sent to notificators, and
+// reports whether it is suppressed by a window or silence. The first
+// reminder after the end of a window is sent as the maintenance summary.
+func (m *Maintenance) Filter(checkResult status.CheckResult, now time.Time) (status.CheckResult, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := stateKey{monitorName: checkResult.MonitorName, resourceName: checkResult.ResourceName}
+	if by, suppressed := m.suppressedBy(checkResult, now); suppressed {
+		m.suppressed[key] = suppressedResult{checkResult: checkResult, by: by}
+		return checkResult, true
+	}
+
+	previous, exists := m.suppressed[key]
+	if !exists {
+		return checkResult, false
+	}
+	delete(m.suppressed, key)
+	if checkResult.State == status.StateStillNotAvailable {
+		return summary(checkResult, previous.by), false
+	}
+	return checkResult, false
+}
+
+// Covers reports whether notifications about the result are suppressed,
+// unlike Filter it doesn't record the result
+func (m *Maintenance) Covers(checkResult status.CheckResult, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, suppressed := m.suppressedBy(checkResult, now)
+	return suppressed
+}
+
+func (m *Maintenance) suppressedBy(checkResult status.CheckResult, now time.Time) (string, bool) {
+	for _, window := range m.windows {
+		if window.active(now) && window.scope.matches(checkResult) {
+			return fmt.Sprintf("'%s'", window.config.Name), true
+		}
+	}
+	for _, silence := range m.silences {
+		if silence.StartsAt.After(now) || !now.Before(silence.EndsAt) {
+			continue
+		}
+		if silenceScope(silence).matches(checkResult) {
+			return fmt.Sprintf("#%d", silence.ID), true
+		}
+	}
+	return "", false
+}
+
+// Summaries returns outages of resources, which are still not available
+// after their windows and silences have ended
+func (m *Maintenance) Summaries(now time.Time) []status.CheckResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var summaries []status.CheckResult
+	for key, previous := range m.suppressed {
+		if _, suppressed := m.suppressedBy(previous.checkResult, now); suppressed {
+			continue
+		}
+		delete(m.suppressed, key)
+
+		switch previous.checkResult.State {
+		case status.StateNotAvailable, status.StateStillNotAvailable:
+			summaries = append(summaries, summary(previous.checkResult, previous.by))
+		}
+	}
+	return summaries
+}
+
+// Run sends summaries of ended windows until ctx is done
+func (m *Maintenance) Run(ctx context.Context, summaries chan<- status.CheckResult) {
+	ticker := time.NewTicker(summaryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, checkResult := range m.Summaries(now) {
+				select {
+				case summaries <- checkResult:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// summary turns suppressed outage into a new one, which mentions the window
+func summary(checkResult status.CheckResult, by string) status.CheckResult {
+	checkResult.State = status.StateNotAvailable
+	checkResult.Details = append(
+		slices.Clip(checkResult.Details),
+		status.NewCheckDetails(status.Msg(status.MsgMaintenance), status.Msg(status.MsgMaintenanceEnded, by)),
+	)
+	return checkResult
+}
+
+func silenceScope(silence notificators.Silence) maintenanceScope {
+	return maintenanceScope{resources: silence.Resources, monitors: silence.Monitors, labels: silence.Labels}
+}
+
+// Silences returns active and pending silences, expired ones are removed
+func (m *Maintenance) Silences() []notificators.Silence {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.silences = slices.DeleteFunc(m.silences, func(silence notificators.Silence) bool {
+		return !now.Before(silence.EndsAt)
+	})
+
+	silences := make([]notificators.Silence, 0, len(m.silences))
+	for _, silence := range m.silences {
+		silence.Labels = maps.Clone(silence.Labels)
+		silences = append(silences, silence)
+	}
+	return silences
+}
+
+// AddSilence creates silence, it starts now if start time is not set
+func (m *Maintenance) AddSilence(silence notificators.Silence) (notificators.Silence, error) {
+	if silenceScope(silence).isEmpty() {
+		return notificators.Silence{}, fmt.Errorf("at least one of resources, monitors or labels must be set")
+	}
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = time.Now()
+	}
+	if !silence.EndsAt.After(silence.StartsAt) {
+		return notificators.Silence{}, fmt.Errorf("silence must end after it starts")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	silence.ID = m.nextSilenceID
+	m.nextSilenceID++
+	m.silences = append(m.silences, silence)
+	return silence, nil
+}
+
+// ExpireSilence removes silence before its end
+func (m *Maintenance) ExpireSilence(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := slices.IndexFunc(m.silences, func(silence notificators.Silence) bool {
+		return silence.ID == id
+	})
+	if i < 0 {
+		return fmt.Errorf("Silence %d not found", id)
+	}
+	m.silences = slices.Delete(m.silences, i, i+1)
+	return nil
+}
@@ -0,0 +1,164 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewsapw/avalio/notificators"
+	"github.com/andrewsapw/avalio/status"
+)
+
+func TestMaintenanceWindow_Active(t *testing.T) {
+	location := time.UTC
+	recurring, err := MaintenanceWindowConfig{
+		Name:            "weekly deploy",
+		Resources:       []string{"api"},
+		Cron:            "0 3 * * sun",
+		DurationMinutes: 60,
+		Timezone:        "UTC",
+	}.compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oneOff, err := MaintenanceWindowConfig{
+		Name:      "migration",
+		Resources: []string{"api"},
+		Start:     time.Date(2025, 6, 1, 22, 0, 0, 0, location),
+		End:       time.Date(2025, 6, 2, 2, 0, 0, 0, location),
+	}.compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		window *maintenanceWindow
+		now    time.Time
+		active bool
+	}{
+		// 2025-06-01 is sunday
+		{recurring, time.Date(2025, 6, 1, 3, 0, 0, 0, location), true},
+		{recurring, time.Date(2025, 6, 1, 3, 59, 0, 0, location), true},
+		{recurring, time.Date(2025, 6, 1, 4, 0, 0, 0, location), false},
+		{recurring, time.Date(2025, 6, 1, 2, 59, 0, 0, location), false},
+		{recurring, time.Date(2025, 6, 2, 3, 30, 0, 0, location), false},
+		{oneOff, time.Date(2025, 6, 1, 23, 0, 0, 0, location), true},
+		{oneOff, time.Date(2025, 6, 2, 2, 0, 0, 0, location), false},
+	}
+	for _, c := range cases {
+		if active := c.window.active(c.now); active != c.active {
+			t.Errorf("Expected %s active=%v at %v", c.window.config.Name, c.active, c.now)
+		}
+	}
+}
+
+func TestMaintenance_Summary(t *testing.T) {
+	start := time.Now()
+	maintenance, err := NewMaintenance([]MaintenanceWindowConfig{{
+		Name:   "deploy",
+		Labels: map[string]string{"env": "prod"},
+		Start:  start,
+		End:    start.Add(time.Hour),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outage := resourceResult("api", "prod", status.StateNotAvailable)
+	if _, suppressed := maintenance.Filter(outage, start.Add(time.Minute)); !suppressed {
+		t.Fatal("Expected outage to be suppressed during the window")
+	}
+	other := resourceResult("stage", "stage", status.StateNotAvailable)
+	if _, suppressed := maintenance.Filter(other, start.Add(time.Minute)); suppressed {
+		t.Fatal("Expected resource out of scope not to be suppressed")
+	}
+
+	if summaries := maintenance.Summaries(start.Add(30 * time.Minute)); len(summaries) != 0 {
+		t.Errorf("Expected no summaries during the window, got %d", len(summaries))
+	}
+
+	summaries := maintenance.Summaries(start.Add(time.Hour))
+	if len(summaries) != 1 {
+		t.Fatalf("Expected summary after the window, got %d", len(summaries))
+	}
+	summary := summaries[0]
+	if summary.State != status.StateNotAvailable || len(summary.Details) != 1 {
+		t.Fatalf("Unexpected summary %+v", summary)
+	}
+	if description := summary.Details[0].DescriptionIn(status.LanguageEnglish); description != "Maintenance 'deploy' has ended, the resource is still not available" {
+		t.Errorf("Unexpected summary description %q", description)
+	}
+
+	if summaries := maintenance.Summaries(start.Add(2 * time.Hour)); len(summaries) != 0 {
+		t.Errorf("Expected summary to be sent once, got %d", len(summaries))
+	}
+}
+
+func TestMaintenance_RecoveredDuringSilence(t *testing.T) {
+	maintenance, _ := NewMaintenance(nil)
+	silence, err := maintenance.AddSilence(notificators.Silence{
+		Resources: []string{"api"},
+		EndsAt:    time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	maintenance.Filter(newResult(status.StateNotAvailable), now)
+	if _, suppressed := maintenance.Filter(newResult(status.StateRecovered), now); !suppressed {
+		t.Fatal("Expected recovery to be suppressed by the silence")
+	}
+
+	if err := maintenance.ExpireSilence(silence.ID); err != nil {
+		t.Fatal(err)
+	}
+	if summaries := maintenance.Summaries(now); len(summaries) != 0 {
+		t.Errorf("Expected no summary for recovered resource, got %d", len(summaries))
+	}
+	if len(maintenance.Silences()) != 0 {
+		t.Error("Expected expired silence to be removed")
+	}
+}
+
+func TestMaintenance_ReminderAfterSilence(t *testing.T) {
+	maintenance, _ := NewMaintenance(nil)
+	silence, _ := maintenance.AddSilence(notificators.Silence{
+		Monitors: []string{"every-minute"},
+		EndsAt:   time.Now().Add(time.Hour),
+	})
+
+	maintenance.Filter(newResult(status.StateNotAvailable), time.Now())
+	maintenance.ExpireSilence(silence.ID)
+
+	// reminder, which comes before the summary, is sent as the summary
+	checkResult, suppressed := maintenance.Filter(newResult(status.StateStillNotAvailable), time.Now())
+	if suppressed || checkResult.State != status.StateNotAvailable || len(checkResult.Details) != 1 {
+		t.Errorf("Expected reminder to be sent as the summary, got %+v", checkResult)
+	}
+	if summaries := maintenance.Summaries(time.Now()); len(summaries) != 0 {
+		t.Errorf("Expected summary to be sent once, got %d", len(summaries))
+	}
+}
+
+func TestValidateMaintenance(t *testing.T) {
+	now := time.Now()
+	cases := map[string]MaintenanceWindowConfig{
+		"no scope":     {Name: "w", Cron: "0 3 * * *", DurationMinutes: 60},
+		"no schedule":  {Name: "w", Resources: []string{"api"}},
+		"no duration":  {Name: "w", Resources: []string{"api"}, Cron: "0 3 * * *"},
+		"invalid cron": {Name: "w", Resources: []string{"api"}, Cron: "daily", DurationMinutes: 60},
+		"no end":       {Name: "w", Resources: []string{"api"}, Start: now},
+		"end < start":  {Name: "w", Resources: []string{"api"}, Start: now, End: now.Add(-time.Hour)},
+		"both":         {Name: "w", Resources: []string{"api"}, Start: now, End: now.Add(time.Hour), Cron: "0 3 * * *"},
+	}
+	for name, window := range cases {
+		if err := ValidateMaintenance([]MaintenanceWindowConfig{window}); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+
+	valid := MaintenanceWindowConfig{Name: "w", Resources: []string{"api"}, Cron: "0 3 * * *", DurationMinutes: 60}
+	if err := ValidateMaintenance([]MaintenanceWindowConfig{valid, valid}); err == nil {
+		t.Error("Expected duplicate names to be rejected")
+	}
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/andrewsapw/avalio/labels"
+	"github.com/andrewsapw/avalio/resources"
+	"github.com/andrewsapw/avalio/status"
+)
+
+// OnceResult is a result of a single check made by RunOnce
+type OnceResult struct {
+	status.CheckResult
+	Duration time.Duration `json:"-"`
+}
+
+// Failed reports whether the resource isn't available, including resources
+// unreachable because of their dependencies
+func (r OnceResult) Failed() bool {
+	switch r.State {
+	case status.StateNotAvailable, status.StateStillNotAvailable, status.StateUnreachable:
+		return true
+	}
+	return false
+}
+
+// RunOnce checks resources concurrently, each of them exactly once, and
+// returns results in the order of resources. Empty selector selects all
+// resources. If notify is set, failures are sent to notificators of
+// monitors, which check the resource, unless they are in maintenance.
+func (app *Application) RunOnce(selector labels.Selector, notify bool) ([]OnceResult, error) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	layout, err := app.layout()
+	if err != nil {
+		return nil, err
+	}
+
+	// results are reported on behalf of the first monitor of the resource,
+	// so its templates and routes are used
+	resourcesMonitors := make(map[string]string)
+	for _, m := range app.Monitors {
+		for _, r := range layout.monitorsResources[m.GetName()] {
+			if _, exists := resourcesMonitors[r.GetName()]; !exists {
+				resourcesMonitors[r.GetName()] = m.GetName()
+			}
+		}
+	}
+
+	var selected []resources.Resource
+	for _, r := range app.Resources {
+		if selector.Empty() || selector.Matches(r.GetLabels()) {
+			selected = append(selected, r)
+		}
+	}
+
+	results := make([]OnceResult, len(selected))
+	var wg sync.WaitGroup
+	for i, r := range selected {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = checkOnce(r, resourcesMonitors[r.GetName()])
+		}()
+	}
+	wg.Wait()
+
+	// failures of dependencies are known only after all checks
+	for _, result := range results {
+		app.State.Update(result.CheckResult)
+	}
+	for i := range results {
+		results[i].CheckResult = app.State.ResolveDependencies(results[i].CheckResult)
+	}
+
+	if notify {
+		if err := app.notifyOnce(results, layout); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func checkOnce(r resources.Resource, monitorName string) OnceResult {
+	started := time.Now()
+	ok, details := r.RunCheck()
+
+	state := status.StateAvailable
+	if !ok {
+		state = status.StateNotAvailable
+	}
+	checkResult := status.NewCheckResult(r.GetName(), r.GetType(), details, state)
+	checkResult.MonitorName = monitorName
+	checkResult.Labels = r.GetLabels()
+	if !ok {
+		checkResult.DownSince = checkResult.CheckedAt
+	}
+	return OnceResult{CheckResult: checkResult, Duration: time.Since(started)}
+}
+
+// notifyOnce sends outages directly, without delivery queues, as the
+// process exits right after the run
+func (app *Application) notifyOnce(results []OnceResult, layout *layout) error {
+	maintenance, err := NewMaintenance(app.Options.Maintenance)
+	if err != nil {
+		return err
+	}
+	nameToNotificator := make(map[string]func(status.CheckResult) error)
+	for _, n := range app.Notificators {
+		nameToNotificator[n.GetName()] = n.Send
+	}
+
+	now := time.Now()
+	for _, result := range results {
+		if result.State != status.StateNotAvailable || maintenance.Covers(result.CheckResult, now) {
+			continue
+		}
+		names := layout.router.Route(result.CheckResult, layout.monitorsNotificators[result.MonitorName], now)
+		for _, name := range names {
+			if err := nameToNotificator[name](result.CheckResult); err != nil {
+				slog.Error(
+					"Failed to send notification",
+					"notificator_name", name,
+					"resource_name", result.ResourceName,
+					"error", err,
+				)
+			}
+		}
+	}
+	return nil
+}
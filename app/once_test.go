@@ -0,0 +1,77 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/andrewsapw/avalio/labels"
+	"github.com/andrewsapw/avalio/monitors"
+	"github.com/andrewsapw/avalio/notificators"
+	"github.com/andrewsapw/avalio/resources"
+	"github.com/andrewsapw/avalio/status"
+)
+
+type failingResource struct {
+	mockedResource
+}
+
+func (f failingResource) RunCheck() (bool, []status.CheckDetails) {
+	return false, []status.CheckDetails{status.NewCheckError("Reason", "connection refused")}
+}
+
+func TestApplication_RunOnce(t *testing.T) {
+	monitor, err := monitors.NewCronMonitor(monitors.CronMonitorConfig{
+		MonitorConfig: monitors.MonitorConfig{
+			Name:         "prod",
+			Resources:    []string{"gateway", "api"},
+			Notificators: []string{"mock"},
+		},
+		Cron: "* * * * *",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notificator := &mockedNotificator{}
+	application := NewApplication(
+		[]resources.Resource{
+			failingResource{mockedResource{name: "gateway", labels: map[string]string{"env": "prod"}}},
+			failingResource{mockedResource{name: "api", labels: map[string]string{"env": "prod"}, dependsOn: []string{"gateway"}}},
+			mockedResource{name: "stage", labels: map[string]string{"env": "stage"}},
+		},
+		[]notificators.Notificator{notificator},
+		[]monitors.Monitor{monitor},
+		Options{},
+	)
+
+	selector, err := labels.Parse("env=prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := application.RunOnce(selector, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected results of selected resources, got %+v", results)
+	}
+	if results[0].ResourceName != "gateway" || results[0].State != status.StateNotAvailable || results[0].MonitorName != "prod" {
+		t.Errorf("Expected gateway to be not available, got %+v", results[0])
+	}
+	if results[1].ResourceName != "api" || results[1].State != status.StateUnreachable || !results[1].Failed() {
+		t.Errorf("Expected api to be unreachable, got %+v", results[1])
+	}
+
+	// unreachable resources aren't notified about, as by the monitor
+	if len(notificator.sent) != 1 || notificator.sent[0].ResourceName != "gateway" {
+		t.Errorf("Expected single notification about gateway, got %+v", notificator.sent)
+	}
+
+	results, err = application.RunOnce(labels.Selector{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || results[2].Failed() {
+		t.Errorf("Expected all resources to be checked, got %+v", results)
+	}
+}
@@ -0,0 +1,313 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+// severityLabel is the resource label matched by route severities
+const severityLabel = "severity"
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// [[routing.routes]]
+// name = 'payments at work'
+// labels = { team = 'payments' }
+// states = ['not available', 'recovered']
+// days = ['mon', 'tue', 'wed', 'thu', 'fri']
+// time = '09:00-18:00'
+// notificators = ['bot']
+//
+// All conditions of a route must match. Empty condition matches anything.
+type RouteConfig struct {
+	Name          string            `toml:"name"`
+	Monitors      []string          `toml:"monitors"`
+	Labels        map[string]string `toml:"labels"`
+	ResourceTypes []string          `toml:"resource_types"`
+	States        []string          `toml:"states"`
+	Severities    []string          `toml:"severities"`
+	Days          []string          `toml:"days"`
+	Time          string            `toml:"time"`
+	Timezone      string            `toml:"timezone"`
+	// Notificators receive matched results, they are inherited from the
+	// parent route, top level routes inherit monitor notificators
+	Notificators []string `toml:"notificators"`
+	// RepeatIntervalSeconds limits reminders about resources which are still
+	// not available, it is inherited from the parent route
+	RepeatIntervalSeconds int `toml:"repeat_interval_seconds"`
+	// Continue makes the next sibling routes to be checked after this one
+	// has matched
+	Continue bool          `toml:"continue"`
+	Routes   []RouteConfig `toml:"routes"`
+}
+
+func (c RouteConfig) Validate() error {
+	if _, err := c.compile(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// compile parses route conditions, errors mention the route name
+func (c RouteConfig) compile() (*Route, error) {
+	route := &Route{
+		config:   c,
+		location: time.Local,
+		lastSent: make(map[string]time.Time),
+	}
+
+	fail := func(format string, args ...any) (*Route, error) {
+		return nil, fmt.Errorf("[[routing.routes]] %s - %s", c.Name, fmt.Sprintf(format, args...))
+	}
+
+	for _, name := range c.States {
+		state, err := status.ParseResourceState(name)
+		if err != nil {
+			return fail("%v", err)
+		}
+		route.states = append(route.states, state)
+	}
+
+	for _, name := range c.Days {
+		day, exists := weekdays[strings.ToLower(strings.TrimSpace(name))]
+		if !exists {
+			return fail("unknown day '%s', expected one of mon, tue, wed, thu, fri, sat, sun", name)
+		}
+		route.days = append(route.days, day)
+	}
+
+	if c.Time != "" {
+		from, to, found := strings.Cut(c.Time, "-")
+		if !found {
+			return fail("time must be a range like '09:00-18:00'")
+		}
+		var err error
+		if route.from, err = parseMinuteOfDay(from); err != nil {
+			return fail("invalid time '%s': %v", c.Time, err)
+		}
+		if route.to, err = parseMinuteOfDay(to); err != nil {
+			return fail("invalid time '%s': %v", c.Time, err)
+		}
+		route.hasTime = true
+	}
+
+	if c.Timezone != "" {
+		location, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return fail("invalid timezone '%s': %v", c.Timezone, err)
+		}
+		route.location = location
+	}
+
+	if c.RepeatIntervalSeconds < 0 {
+		return fail("repeat_interval_seconds must be non-negative")
+	}
+
+	for _, childConfig := range c.Routes {
+		child, err := childConfig.compile()
+		if err != nil {
+			return nil, err
+		}
+		route.children = append(route.children, child)
+	}
+	return route, nil
+}
+
+// parseMinuteOfDay parses "15:04" to minutes since midnight
+func parseMinuteOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// [[routing.routes]]
+// ...
+type RoutingConfig struct {
+	Routes []RouteConfig `toml:"routes"`
+}
+
+func (c RoutingConfig) Validate() error {
+	for _, route := range c.Routes {
+		if err := route.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notificatorsNames returns names of notificators used in all routes
+func (c RoutingConfig) notificatorsNames() []string {
+	var names []string
+	var walk func(routes []RouteConfig)
+	walk = func(routes []RouteConfig) {
+		for _, route := range routes {
+			names = append(names, route.Notificators...)
+			walk(route.Routes)
+		}
+	}
+	walk(c.Routes)
+	return names
+}
+
+// Route is a compiled routing tree node
+type Route struct {
+	config   RouteConfig
+	states   []status.ResourceState
+	days     []time.Weekday
+	hasTime  bool
+	from, to int
+	location *time.Location
+	children []*Route
+
+	mu sync.Mutex
+	// lastSent holds time of the last notification per monitor and
+	// resource, it is used for repeat interval
+	lastSent map[string]time.Time
+}
+
+// Router chooses notificators for check results using routing tree
+type Router struct {
+	root *Route
+}
+
+// NewRouter compiles routes, which are expected to be validated
+func NewRouter(config RoutingConfig) (*Router, error) {
+	root, err := RouteConfig{Routes: config.Routes}.compile()
+	if err != nil {
+		return nil, err
+	}
+	return &Router{root: root}, nil
+}
+
+// Route returns names of notificators, which should receive check result.
+// Results not matched by any route are sent to monitor notificators.
+func (r *Router) Route(checkResult status.CheckResult, monitorNotificators []string, now time.Time) []string {
+	names := r.root.receivers(checkResult, monitorNotificators, 0, now)
+
+	var unique []string
+	for _, name := range names {
+		if !slices.Contains(unique, name) {
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
+
+// receivers walks the tree: the first matching child handles the result,
+// unless it has continue set. If no child matches, the route itself does.
+func (r *Route) receivers(
+	checkResult status.CheckResult,
+	notificators []string,
+	repeatInterval time.Duration,
+	now time.Time,
+) []string {
+	if len(r.config.Notificators) > 0 {
+		notificators = r.config.Notificators
+	}
+	if r.config.RepeatIntervalSeconds > 0 {
+		repeatInterval = time.Duration(r.config.RepeatIntervalSeconds) * time.Second
+	}
+
+	var names []string
+	matched := false
+	for _, child := range r.children {
+		if !child.matches(checkResult, now) {
+			continue
+		}
+		matched = true
+		names = append(names, child.receivers(checkResult, notificators, repeatInterval, now)...)
+		if !child.config.Continue {
+			break
+		}
+	}
+	if matched {
+		return names
+	}
+
+	if !r.shouldRepeat(checkResult, repeatInterval, now) {
+		return nil
+	}
+	return notificators
+}
+
+// shouldRepeat throttles reminders about resources which are still not
+// available to one per repeat interval
+func (r *Route) shouldRepeat(checkResult status.CheckResult, repeatInterval time.Duration, now time.Time) bool {
+	if repeatInterval == 0 {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := checkResult.MonitorName + "/" + checkResult.ResourceName
+	switch checkResult.State {
+	case status.StateNotAvailable:
+		r.lastSent[key] = now
+	case status.StateStillNotAvailable:
+		if lastSent, exists := r.lastSent[key]; exists && now.Sub(lastSent) < repeatInterval {
+			return false
+		}
+		r.lastSent[key] = now
+	default:
+		delete(r.lastSent, key)
+	}
+	return true
+}
+
+func (r *Route) matches(checkResult status.CheckResult, now time.Time) bool {
+	c := r.config
+	if len(c.Monitors) > 0 && !slices.Contains(c.Monitors, checkResult.MonitorName) {
+		return false
+	}
+	for name, value := range c.Labels {
+		if checkResult.Labels[name] != value {
+			return false
+		}
+	}
+	if len(c.ResourceTypes) > 0 && !slices.Contains(c.ResourceTypes, checkResult.ResourceType) {
+		return false
+	}
+	if len(r.states) > 0 && !slices.Contains(r.states, checkResult.State) {
+		return false
+	}
+	if len(c.Severities) > 0 && !slices.Contains(c.Severities, checkResult.Labels[severityLabel]) {
+		return false
+	}
+
+	now = now.In(r.location)
+	// days are matched against the day the time range has started on
+	day := now.Weekday()
+	if r.hasTime {
+		minute := now.Hour()*60 + now.Minute()
+		switch {
+		case r.from <= r.to:
+			if minute < r.from || minute >= r.to {
+				return false
+			}
+		case minute >= r.from:
+			// evening part of range over midnight, e.g. 22:00-08:00
+		case minute < r.to:
+			// morning part belongs to the range started the day before
+			day = (day + 6) % 7
+		default:
+			return false
+		}
+	}
+	return len(r.days) == 0 || slices.Contains(r.days, day)
+}
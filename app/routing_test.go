@@ -0,0 +1,127 @@
+package app
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+func newTestRouter(t *testing.T, routes ...RouteConfig) *Router {
+	config := RoutingConfig{Routes: routes}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected routes to be valid, got %v", err)
+	}
+	router, err := NewRouter(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return router
+}
+
+func labeledResult(state status.ResourceState, labels map[string]string) status.CheckResult {
+	checkResult := newResult(state)
+	checkResult.Labels = labels
+	return checkResult
+}
+
+func TestRouter_Tree(t *testing.T) {
+	router := newTestRouter(t,
+		RouteConfig{
+			Name:         "payments",
+			Labels:       map[string]string{"team": "payments"},
+			Notificators: []string{"payments-bot"},
+			Continue:     true,
+			Routes: []RouteConfig{
+				{Name: "critical", Severities: []string{"critical"}, Notificators: []string{"pager"}},
+			},
+		},
+		RouteConfig{Name: "http", ResourceTypes: []string{"mock"}, Notificators: []string{"mail"}},
+		RouteConfig{Name: "never", ResourceTypes: []string{"mock"}, Notificators: []string{"unused"}},
+	)
+
+	now := time.Now()
+	cases := []struct {
+		labels   map[string]string
+		expected []string
+	}{
+		{map[string]string{"team": "payments", "severity": "critical"}, []string{"pager", "mail"}},
+		{map[string]string{"team": "payments"}, []string{"payments-bot", "mail"}},
+		{nil, []string{"mail"}},
+	}
+	for _, c := range cases {
+		names := router.Route(labeledResult(status.StateNotAvailable, c.labels), []string{"monitor-bot"}, now)
+		if !slices.Equal(names, c.expected) {
+			t.Errorf("Expected %v for labels %v, got %v", c.expected, c.labels, names)
+		}
+	}
+
+	unmatched := newTestRouter(t, RouteConfig{States: []string{"recovered"}, Notificators: []string{"mail"}})
+	if names := unmatched.Route(newResult(status.StateNotAvailable), []string{"monitor-bot"}, now); !slices.Equal(names, []string{"monitor-bot"}) {
+		t.Errorf("Expected unmatched result to go to monitor notificators, got %v", names)
+	}
+}
+
+func TestRouter_TimeConditions(t *testing.T) {
+	router := newTestRouter(t,
+		RouteConfig{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Time: "09:00-18:00", Timezone: "UTC", Notificators: []string{"bot"}},
+		RouteConfig{Days: []string{"sun"}, Time: "22:00-06:00", Timezone: "UTC", Notificators: []string{"pager"}},
+		RouteConfig{Time: "22:00-08:00", Timezone: "UTC", Notificators: []string{"mail"}},
+	)
+
+	cases := map[string][]string{
+		"2025-01-06T10:00:00Z": {"bot"},     // monday, business hours
+		"2025-01-06T18:00:00Z": {"default"}, // monday evening
+		"2025-01-04T10:00:00Z": {"default"}, // saturday
+		"2025-01-04T23:30:00Z": {"mail"},    // saturday night
+		"2025-01-07T07:59:00Z": {"mail"},    // tuesday morning
+		"2025-01-05T23:00:00Z": {"pager"},   // sunday night
+		"2025-01-06T02:00:00Z": {"pager"},   // monday morning, range started on sunday
+		"2025-01-05T02:00:00Z": {"mail"},    // sunday morning, range started on saturday
+	}
+	for at, expected := range cases {
+		now, _ := time.Parse(time.RFC3339, at)
+		if names := router.Route(newResult(status.StateNotAvailable), []string{"default"}, now); !slices.Equal(names, expected) {
+			t.Errorf("Expected %v at %s, got %v", expected, at, names)
+		}
+	}
+}
+
+func TestRouter_RepeatInterval(t *testing.T) {
+	router := newTestRouter(t, RouteConfig{RepeatIntervalSeconds: 3600, Routes: []RouteConfig{{Labels: map[string]string{"env": "prod"}}}})
+
+	now := time.Now()
+	send := func(state status.ResourceState, at time.Time) bool {
+		return len(router.Route(labeledResult(state, map[string]string{"env": "prod"}), []string{"bot"}, at)) > 0
+	}
+
+	if !send(status.StateNotAvailable, now) {
+		t.Error("Expected outage to be sent")
+	}
+	if send(status.StateStillNotAvailable, now.Add(time.Minute)) {
+		t.Error("Expected reminder to be throttled by inherited repeat interval")
+	}
+	if !send(status.StateStillNotAvailable, now.Add(time.Hour)) {
+		t.Error("Expected reminder after repeat interval")
+	}
+	if !send(status.StateRecovered, now.Add(time.Hour+time.Minute)) {
+		t.Error("Expected recovery to be sent")
+	}
+}
+
+func TestRoutingConfig_Validate(t *testing.T) {
+	invalid := []RouteConfig{
+		{States: []string{"broken"}},
+		{Days: []string{"monday"}},
+		{Time: "09:00"},
+		{Time: "9am-6pm"},
+		{Timezone: "Mars/Olympus"},
+		{Routes: []RouteConfig{{RepeatIntervalSeconds: -1}}},
+	}
+	for _, route := range invalid {
+		if err := (RoutingConfig{Routes: []RouteConfig{route}}).Validate(); err == nil {
+			t.Errorf("Expected route %+v to be invalid", route)
+		}
+	}
+}
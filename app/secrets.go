@@ -0,0 +1,202 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// interpolate replaces ${VAR} and ${VAR:-default} with environment
+// variables in all string values of the config. Errors mention the value
+// path, e.g. notificators.telegram[0].token.
+func interpolate(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return interpolate(v.Elem(), path)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldPath := path
+			if !field.Anonymous {
+				name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+				if name == "" {
+					name = field.Name
+				}
+				fieldPath = joinPath(path, name)
+			}
+			if err := interpolate(v.Field(i), fieldPath); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := interpolate(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// map values are not addressable, so they are copied and stored back
+		iter := v.MapRange()
+		for iter.Next() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(iter.Value())
+			if err := interpolate(value, joinPath(path, fmt.Sprint(iter.Key()))); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), value)
+		}
+	case reflect.String:
+		expanded, err := expandEnv(v.String())
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if v.CanSet() {
+			v.SetString(expanded)
+		}
+	}
+	return nil
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// expandEnv replaces ${VAR} with the variable, which must be set, and
+// ${VAR:-default} with the variable or default, if the variable is unset or
+// empty. $${ is kept as ${.
+func expandEnv(s string) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		if start > 0 && s[start-1] == '$' {
+			b.WriteString(s[:start-1])
+			b.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+		b.WriteString(s[:start])
+
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("'${' is not closed")
+		}
+		name, defaultValue, hasDefault := strings.Cut(s[start+2:start+end], ":-")
+		if !isEnvName(name) {
+			return "", fmt.Errorf("invalid environment variable name '%s'", name)
+		}
+
+		value, exists := os.LookupEnv(name)
+		switch {
+		case hasDefault && value == "":
+			value = defaultValue
+		case !exists:
+			return "", fmt.Errorf("environment variable '%s' is not set", name)
+		}
+		b.WriteString(value)
+		s = s[start+end+1:]
+	}
+}
+
+func isEnvName(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, c := range name {
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// secretField is a secret config value, which may be read from a file
+type secretField struct {
+	// location is the value section and key, e.g. "[[notificator.telegram]] bot - token"
+	location string
+	value    *string
+	file     string
+}
+
+func (c *Config) secretFields() []secretField {
+	var fields []secretField
+	for i := range c.Notificators.Telegram {
+		n := &c.Notificators.Telegram[i]
+		fields = append(fields, secretField{
+			location: fmt.Sprintf("[[notificator.telegram]] %s - token", n.Name),
+			value:    &n.Token,
+			file:     n.TokenFile,
+		})
+	}
+	for i := range c.Notificators.Matrix {
+		n := &c.Notificators.Matrix[i]
+		fields = append(fields,
+			secretField{
+				location: fmt.Sprintf("[[notificator.matrix]] %s - access_token", n.Name),
+				value:    &n.AccessToken,
+				file:     n.AccessTokenFile,
+			},
+			secretField{
+				location: fmt.Sprintf("[[notificator.matrix]] %s - password", n.Name),
+				value:    &n.Password,
+				file:     n.PasswordFile,
+			},
+		)
+	}
+	for i := range c.Resources.Heartbeat {
+		r := &c.Resources.Heartbeat[i]
+		fields = append(fields, secretField{
+			location: fmt.Sprintf("[[resources.heartbeat]] %s - token", r.Name),
+			value:    &r.Token,
+			file:     r.TokenFile,
+		})
+	}
+	fields = append(fields, secretField{location: "[api] - token", value: &c.API.Token, file: c.API.TokenFile})
+	return fields
+}
+
+// loadSecrets reads secret values from their files
+func (c *Config) loadSecrets() error {
+	for _, field := range c.secretFields() {
+		if field.file == "" {
+			continue
+		}
+		if *field.value != "" {
+			return fmt.Errorf("%s and its _file variant can't be both set", field.location)
+		}
+		data, err := os.ReadFile(field.file)
+		if err != nil {
+			return fmt.Errorf("%s - can't read secret file: %v", field.location, err)
+		}
+		value := strings.TrimRight(string(data), "\r\n")
+		if value == "" {
+			return fmt.Errorf("%s - secret file %s is empty", field.location, field.file)
+		}
+		*field.value = value
+	}
+	return nil
+}
+
+// Secrets returns secret values of the config, they must not be logged
+func (c *Config) Secrets() []string {
+	var secrets []string
+	for _, field := range c.secretFields() {
+		if *field.value != "" {
+			secrets = append(secrets, *field.value)
+		}
+	}
+	return secrets
+}
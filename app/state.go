@@ -0,0 +1,348 @@
+package app
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/andrewsapw/avalio/notificators"
+	"github.com/andrewsapw/avalio/resources"
+	"github.com/andrewsapw/avalio/status"
+)
+
+// maxResolvedIncidents limits number of resolved incidents kept in memory
+const maxResolvedIncidents = 50
+
+type stateKey struct {
+	monitorName  string
+	resourceName string
+}
+
+// State tracks last check results, incidents and muted resources.
+// It implements notificators.StateProvider.
+type State struct {
+	mu             sync.Mutex
+	resources      map[string]resources.Resource
+	statuses       map[stateKey]notificators.ResourceStatus
+	openIncidents  map[stateKey]*notificators.Incident
+	resolved       []notificators.Incident
+	mutedUntil     map[string]time.Time
+	gaps           []notificators.Gap
+	nextIncidentID int
+}
+
+func NewState(resourcesList []resources.Resource) *State {
+	s := &State{
+		statuses:       make(map[stateKey]notificators.ResourceStatus),
+		openIncidents:  make(map[stateKey]*notificators.Incident),
+		mutedUntil:     make(map[string]time.Time),
+		nextIncidentID: 1,
+	}
+	s.SetResources(resourcesList)
+	return s
+}
+
+// SetResources replaces resources, which are checked by monitors
+func (s *State) SetResources(resourcesList []resources.Resource) {
+	nameToResource := make(map[string]resources.Resource)
+	for _, r := range resourcesList {
+		nameToResource[r.GetName()] = r
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resources = nameToResource
+}
+
+// Ping passes signal of a job to the heartbeat resource with the token
+func (s *State) Ping(token string, signal resources.HeartbeatSignal, body string) error {
+	s.mu.Lock()
+	var heartbeat *resources.HeartbeatResource
+	for _, r := range s.resources {
+		if h, ok := r.(resources.HeartbeatResource); ok && h.Token() == token {
+			heartbeat = &h
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if heartbeat == nil {
+		return fmt.Errorf("Heartbeat not found")
+	}
+	heartbeat.Ping(signal, body, time.Now())
+	slog.Debug("Heartbeat ping received", "resource_name", heartbeat.GetName(), "signal", signal)
+	return nil
+}
+
+// Forget removes status and open incident of the resource, which is no
+// longer checked by the monitor
+func (s *State) Forget(monitorName, resourceName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := stateKey{monitorName: monitorName, resourceName: resourceName}
+	delete(s.statuses, key)
+	delete(s.openIncidents, key)
+}
+
+// ResolveDependencies marks failures of resources, whose dependencies are
+// not available, as unreachable. After the dependency recovers, the
+// resource failure is reported as a new outage, and recovery of unreachable
+// resource, which was never reported, becomes a plain available result.
+func (s *State) ResolveDependencies(checkResult status.CheckResult) status.CheckResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resource, exists := s.resources[checkResult.ResourceName]
+	if !exists {
+		return checkResult
+	}
+
+	key := stateKey{monitorName: checkResult.MonitorName, resourceName: checkResult.ResourceName}
+	wasUnreachable := s.statuses[key].State == status.StateUnreachable
+	_, reported := s.openIncidents[key]
+
+	switch checkResult.State {
+	case status.StateNotAvailable, status.StateStillNotAvailable:
+		for _, parent := range resource.GetDependencies() {
+			if s.isDown(parent) {
+				checkResult.State = status.StateUnreachable
+				return checkResult
+			}
+		}
+		if wasUnreachable && !reported {
+			checkResult.State = status.StateNotAvailable
+		}
+	case status.StateRecovered:
+		if wasUnreachable && !reported {
+			checkResult.State = status.StateAvailable
+		}
+	}
+	return checkResult
+}
+
+// isDown reports whether the last check of the resource by any monitor
+// has failed
+func (s *State) isDown(resourceName string) bool {
+	for key, resourceStatus := range s.statuses {
+		if key.resourceName != resourceName {
+			continue
+		}
+		switch resourceStatus.State {
+		case status.StateNotAvailable, status.StateStillNotAvailable, status.StateUnreachable:
+			return true
+		}
+	}
+	return false
+}
+
+// Update records check result and reports whether notificators should
+// receive it. Results of muted and unreachable resources, reminders about
+// acknowledged incidents and recoveries of unrecorded outages are not sent.
+func (s *State) Update(checkResult status.CheckResult) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := stateKey{monitorName: checkResult.MonitorName, resourceName: checkResult.ResourceName}
+	s.statuses[key] = notificators.ResourceStatus{
+		ResourceName: checkResult.ResourceName,
+		ResourceType: checkResult.ResourceType,
+		MonitorName:  checkResult.MonitorName,
+		State:        checkResult.State,
+		CheckedAt:    checkResult.CheckedAt,
+		DownSince:    checkResult.DownSince,
+	}
+
+	incident := s.openIncidents[key]
+	switch checkResult.State {
+	case status.StateNotAvailable, status.StateStillNotAvailable:
+		if incident == nil {
+			startedAt := checkResult.DownSince
+			if startedAt.IsZero() {
+				startedAt = checkResult.CheckedAt
+			}
+			incident = &notificators.Incident{
+				ID:           s.nextIncidentID,
+				ResourceName: checkResult.ResourceName,
+				MonitorName:  checkResult.MonitorName,
+				StartedAt:    startedAt,
+			}
+			s.nextIncidentID++
+			s.openIncidents[key] = incident
+		}
+	case status.StateRecovered, status.StateAvailable:
+		if incident != nil {
+			incident.ResolvedAt = checkResult.CheckedAt
+			s.resolved = append(s.resolved, *incident)
+			if len(s.resolved) > maxResolvedIncidents {
+				s.resolved = s.resolved[len(s.resolved)-maxResolvedIncidents:]
+			}
+			delete(s.openIncidents, key)
+		}
+	}
+
+	// outage may be unrecorded, if it has happened while the host was offline
+	if checkResult.State == status.StateRecovered && incident == nil {
+		return false
+	}
+
+	if s.isMuted(checkResult.ResourceName) || checkResult.State == status.StateUnreachable {
+		return false
+	}
+
+	if checkResult.State == status.StateStillNotAvailable && incident != nil && incident.IsAcknowledged() {
+		return false
+	}
+
+	return true
+}
+
+// openIncident returns open incident of the resource checked by the monitor
+func (s *State) openIncident(monitorName, resourceName string) (notificators.Incident, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	incident, exists := s.openIncidents[stateKey{monitorName: monitorName, resourceName: resourceName}]
+	if !exists {
+		return notificators.Incident{}, false
+	}
+	return *incident, true
+}
+
+// IsMuted reports whether notifications about the resource are muted
+func (s *State) IsMuted(resourceName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.isMuted(resourceName)
+}
+
+func (s *State) isMuted(resourceName string) bool {
+	until, exists := s.mutedUntil[resourceName]
+	if !exists {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(s.mutedUntil, resourceName)
+		return false
+	}
+	return true
+}
+
+// AddGap records a period, when the monitoring host was offline
+func (s *State) AddGap(gap notificators.Gap) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gaps = append(s.gaps, gap)
+	if len(s.gaps) > maxResolvedIncidents {
+		s.gaps = s.gaps[len(s.gaps)-maxResolvedIncidents:]
+	}
+}
+
+// Gaps returns recent periods, when the monitoring host was offline
+func (s *State) Gaps() []notificators.Gap {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.gaps)
+}
+
+// Statuses implements notificators.StateProvider.
+func (s *State) Statuses() []notificators.ResourceStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]notificators.ResourceStatus, 0, len(s.statuses))
+	for _, resourceStatus := range s.statuses {
+		if s.isMuted(resourceStatus.ResourceName) {
+			resourceStatus.MutedUntil = s.mutedUntil[resourceStatus.ResourceName]
+		}
+		statuses = append(statuses, resourceStatus)
+	}
+
+	slices.SortFunc(statuses, func(a, b notificators.ResourceStatus) int {
+		return cmp.Or(
+			cmp.Compare(a.ResourceName, b.ResourceName),
+			cmp.Compare(a.MonitorName, b.MonitorName),
+		)
+	})
+	return statuses
+}
+
+// Incidents implements notificators.StateProvider.
+func (s *State) Incidents() []notificators.Incident {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var open []notificators.Incident
+	for _, incident := range s.openIncidents {
+		open = append(open, *incident)
+	}
+	slices.SortFunc(open, func(a, b notificators.Incident) int {
+		return cmp.Compare(b.ID, a.ID)
+	})
+
+	incidents := open
+	for i := len(s.resolved) - 1; i >= 0; i-- {
+		incidents = append(incidents, s.resolved[i])
+	}
+	return incidents
+}
+
+// CheckNow implements notificators.StateProvider.
+func (s *State) CheckNow(resourceName string) (status.CheckResult, error) {
+	s.mu.Lock()
+	resource, exists := s.resources[resourceName]
+	s.mu.Unlock()
+	if !exists {
+		return status.CheckResult{}, fmt.Errorf("Resource '%s' not found", resourceName)
+	}
+
+	ok, details := resource.RunCheck()
+	state := status.StateAvailable
+	if !ok {
+		state = status.StateNotAvailable
+	}
+	return status.NewCheckResult(resource.GetName(), resource.GetType(), details, state), nil
+}
+
+// Mute implements notificators.StateProvider.
+func (s *State) Mute(resourceName string, duration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.resources[resourceName]; !exists {
+		return fmt.Errorf("Resource '%s' not found", resourceName)
+	}
+
+	if duration <= 0 {
+		delete(s.mutedUntil, resourceName)
+	} else {
+		s.mutedUntil[resourceName] = time.Now().Add(duration)
+	}
+	return nil
+}
+
+// Acknowledge implements notificators.StateProvider.
+func (s *State) Acknowledge(resourceName, by string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acknowledged := false
+	for key, incident := range s.openIncidents {
+		if key.resourceName != resourceName || incident.IsAcknowledged() {
+			continue
+		}
+		incident.AcknowledgedAt = time.Now()
+		incident.AcknowledgedBy = by
+		acknowledged = true
+	}
+
+	if !acknowledged {
+		return fmt.Errorf("No open incidents for resource '%s'", resourceName)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewsapw/avalio/resources"
+	"github.com/andrewsapw/avalio/status"
+)
+
+type mockedResource struct {
+	name      string
+	labels    map[string]string
+	dependsOn []string
+}
+
+func (m mockedResource) GetName() string { return m.name }
+
+func (m mockedResource) GetType() string { return "mock" }
+
+func (m mockedResource) GetLabels() map[string]string { return m.labels }
+
+func (m mockedResource) GetDependencies() []string { return m.dependsOn }
+
+func (m mockedResource) RunCheck() (bool, []status.CheckDetails) { return true, nil }
+
+func newResult(state status.ResourceState) status.CheckResult {
+	checkResult := status.NewCheckResult("api", "mock", nil, state)
+	checkResult.MonitorName = "every-minute"
+	return checkResult
+}
+
+func TestState_IncidentLifecycle(t *testing.T) {
+	state := NewState(nil)
+
+	if !state.Update(newResult(status.StateNotAvailable)) {
+		t.Error("Expected outage to be sent")
+	}
+	if !state.Update(newResult(status.StateStillNotAvailable)) {
+		t.Error("Expected reminder to be sent before acknowledgement")
+	}
+
+	if err := state.Acknowledge("api", "oncall"); err != nil {
+		t.Fatalf("Expected Acknowledge() to succeed, got %v", err)
+	}
+	if state.Update(newResult(status.StateStillNotAvailable)) {
+		t.Error("Expected reminder to be suppressed after acknowledgement")
+	}
+
+	incidents := state.Incidents()
+	if len(incidents) != 1 || !incidents[0].IsOpen() || incidents[0].AcknowledgedBy != "oncall" {
+		t.Fatalf("Expected single open acknowledged incident, got %+v", incidents)
+	}
+
+	if !state.Update(newResult(status.StateRecovered)) {
+		t.Error("Expected recovery to be sent")
+	}
+	incidents = state.Incidents()
+	if len(incidents) != 1 || incidents[0].IsOpen() {
+		t.Fatalf("Expected incident to be resolved, got %+v", incidents)
+	}
+
+	if err := state.Acknowledge("api", "oncall"); err == nil {
+		t.Error("Expected error acknowledging resource without open incidents")
+	}
+}
+
+func TestState_Mute(t *testing.T) {
+	state := NewState([]resources.Resource{mockedResource{name: "api"}})
+
+	if err := state.Mute("unknown", time.Hour); err == nil {
+		t.Error("Expected error muting unknown resource")
+	}
+	if err := state.Mute("api", time.Hour); err != nil {
+		t.Fatalf("Expected Mute() to succeed, got %v", err)
+	}
+	if state.Update(newResult(status.StateNotAvailable)) {
+		t.Error("Expected notification about muted resource to be suppressed")
+	}
+
+	statuses := state.Statuses()
+	if len(statuses) != 1 || statuses[0].MutedUntil.IsZero() {
+		t.Errorf("Expected muted status, got %+v", statuses)
+	}
+
+	state.Mute("api", 0)
+	if !state.Update(newResult(status.StateStillNotAvailable)) {
+		t.Error("Expected notification after unmute")
+	}
+}
+
+func TestState_Dependencies(t *testing.T) {
+	state := NewState([]resources.Resource{
+		mockedResource{name: "gateway"},
+		mockedResource{name: "api", dependsOn: []string{"gateway"}},
+	})
+
+	resourceResult := func(name string, state status.ResourceState) status.CheckResult {
+		checkResult := status.NewCheckResult(name, "mock", nil, state)
+		checkResult.MonitorName = "every-minute"
+		return checkResult
+	}
+	handle := func(checkResult status.CheckResult) (status.ResourceState, bool) {
+		checkResult = state.ResolveDependencies(checkResult)
+		return checkResult.State, state.Update(checkResult)
+	}
+
+	handle(resourceResult("gateway", status.StateNotAvailable))
+	if resolved, sent := handle(resourceResult("api", status.StateNotAvailable)); resolved != status.StateUnreachable || sent {
+		t.Errorf("Expected api to be unreachable and suppressed, got %v, %v", resolved, sent)
+	}
+	if incidents := state.Incidents(); len(incidents) != 1 || incidents[0].ResourceName != "gateway" {
+		t.Errorf("Expected only gateway incident, got %+v", incidents)
+	}
+
+	// the gateway recovers, but api is still down
+	handle(resourceResult("gateway", status.StateRecovered))
+	if resolved, sent := handle(resourceResult("api", status.StateStillNotAvailable)); resolved != status.StateNotAvailable || !sent {
+		t.Errorf("Expected api outage to be reported as new, got %v, %v", resolved, sent)
+	}
+
+	// recovery of unreachable resource, which was never reported, is not sent
+	handle(resourceResult("gateway", status.StateNotAvailable))
+	handle(resourceResult("api", status.StateRecovered))
+	if resolved, _ := handle(resourceResult("api", status.StateNotAvailable)); resolved != status.StateUnreachable {
+		t.Fatalf("Expected api to be unreachable, got %v", resolved)
+	}
+	handle(resourceResult("gateway", status.StateRecovered))
+	if resolved, _ := handle(resourceResult("api", status.StateRecovered)); resolved != status.StateAvailable {
+		t.Errorf("Expected unreported recovery to become available, got %v", resolved)
+	}
+}
@@ -0,0 +1,373 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/andrewsapw/avalio/monitors"
+	"github.com/andrewsapw/avalio/resources"
+	"github.com/andrewsapw/avalio/status"
+)
+
+var (
+	configTablePattern = regexp.MustCompile(`^\[\[?\s*([^\[\]]+?)\s*\]\]?\s*(#.*)?$`)
+	configKeyPattern   = regexp.MustCompile(`^([A-Za-z0-9_\-."' ]+?)\s*=`)
+	configNamePattern  = regexp.MustCompile(`^name\s*=\s*(?:'([^']*)'|"([^"]*)")`)
+)
+
+// configSource holds positions of keys and named components in config
+// files, as "file:line", for error messages
+type configSource struct {
+	// file is set for source of a single file, its name is returned for
+	// keys without known position
+	file string
+	// keys maps dotted keys and table names, e.g. "resources.http.url", to
+	// their first position
+	keys map[string]string
+	// components maps table and name, e.g. "resources.http api", to the
+	// position of the component table
+	components map[string]string
+}
+
+func newConfigSource() *configSource {
+	return &configSource{keys: make(map[string]string), components: make(map[string]string)}
+}
+
+// index scans config file lines. It doesn't parse TOML, so values spanning
+// several lines, except multi-line strings, may be skipped.
+func (s *configSource) index(file string, data []byte) {
+	s.file = file
+	var table, tablePosition string
+	inString := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		position := fmt.Sprintf("%s:%d", file, lineNumber)
+
+		quotes := strings.Count(line, `"""`) + strings.Count(line, `'''`)
+		if inString {
+			inString = quotes%2 == 0
+			continue
+		}
+		if quotes%2 == 1 {
+			inString = true
+		}
+
+		if match := configTablePattern.FindStringSubmatch(line); match != nil {
+			table, tablePosition = unquoteKey(match[1]), position
+			parts := strings.Split(table, ".")
+			for i := range parts {
+				s.add(s.keys, strings.Join(parts[:i+1], "."), position)
+			}
+			continue
+		}
+		if match := configNamePattern.FindStringSubmatch(line); match != nil && table != "" {
+			s.add(s.components, table+" "+match[1]+match[2], tablePosition)
+		}
+		if match := configKeyPattern.FindStringSubmatch(line); match != nil {
+			key := unquoteKey(match[1])
+			if table != "" {
+				key = table + "." + key
+			}
+			s.add(s.keys, key, position)
+		}
+	}
+}
+
+// merge adds positions of another source, which are not known yet
+func (s *configSource) merge(other *configSource) {
+	for key, position := range other.keys {
+		s.add(s.keys, key, position)
+	}
+	for component, position := range other.components {
+		s.add(s.components, component, position)
+	}
+}
+
+func (s *configSource) add(positions map[string]string, key, position string) {
+	if _, exists := positions[key]; !exists {
+		positions[key] = position
+	}
+}
+
+// key returns position of the key, or empty string, if it is unknown
+func (s *configSource) key(key string) string {
+	if s == nil {
+		return ""
+	}
+	if position, exists := s.keys[key]; exists {
+		return position
+	}
+	return s.file
+}
+
+// component returns position of the named component of the table
+func (s *configSource) component(table, name string) string {
+	if s == nil {
+		return ""
+	}
+	if position, exists := s.components[table+" "+name]; exists {
+		return position
+	}
+	return s.keys[table]
+}
+
+// unquoteKey removes quotes and spaces around parts of a dotted key
+func unquoteKey(key string) string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
+
+// located prefixes the error with its position, if it is known
+func located(position string, err error) error {
+	if position == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", position, err)
+}
+
+// configValidator collects errors of all config sections
+type configValidator struct {
+	source *configSource
+	errs   []error
+	// names map component kind and name to the component position
+	names map[string]map[string]string
+}
+
+func (v *configValidator) report(position string, err error) {
+	if err != nil {
+		v.errs = append(v.errs, located(position, err))
+	}
+}
+
+// unique reports the component, if another one of the kind has the same
+// name, and remembers its position otherwise
+func (v *configValidator) unique(kind, name, position string) {
+	if v.names[kind] == nil {
+		v.names[kind] = make(map[string]string)
+	}
+	previous, exists := v.names[kind][name]
+	if !exists {
+		v.names[kind][name] = position
+		return
+	}
+	if previous == "" {
+		v.report(position, fmt.Errorf("duplicate %s name '%s'", kind, name))
+	} else {
+		v.report(position, fmt.Errorf("duplicate %s name '%s', it is already defined at %s", kind, name, previous))
+	}
+}
+
+func (v *configValidator) exists(kind, name string) bool {
+	_, exists := v.names[kind][name]
+	return exists
+}
+
+// Validate checks all sections of the config and references between them.
+// All errors are returned together, each one is prefixed with position of
+// the invalid value or component, if the config was read from files.
+func (c *Config) Validate() error {
+	v := &configValidator{source: c.source, names: make(map[string]map[string]string)}
+
+	switch strings.ToLower(c.LogLevel) {
+	case "", "debug", "info", "error":
+	default:
+		v.report(v.source.key("log_level"), fmt.Errorf("log_level must be one of debug, info or error"))
+	}
+	if _, err := status.ParseLanguage(c.Language); err != nil {
+		v.report(v.source.key("language"), fmt.Errorf("language: %v", err))
+	}
+
+	c.validateResources(v)
+	c.validateNotificators(v)
+
+	// escalation names are checked before monitors, which refer to them
+	for _, policy := range c.Escalations {
+		position := v.source.component("escalations", policy.Name)
+		v.report(position, policy.Validate())
+		v.unique("escalation policy", policy.Name, position)
+		for _, step := range policy.Steps {
+			for _, name := range step.Notificators {
+				if !v.exists("notificator", name) {
+					v.report(position, fmt.Errorf("[[escalations]] %s - notificator '%s' not found", policy.Name, name))
+				}
+			}
+		}
+	}
+
+	c.validateMonitors(v)
+
+	v.report(v.source.key("delivery"), c.Delivery.Validate())
+	v.report(v.source.key("grouping"), c.Grouping.Validate())
+	for _, route := range c.Routing.Routes {
+		v.report(v.source.component("routing.routes", route.Name), route.Validate())
+	}
+	for _, name := range c.Routing.notificatorsNames() {
+		if !v.exists("notificator", name) {
+			v.report(v.source.key("routing"), fmt.Errorf("[[routing.routes]] - notificator '%s' not found", name))
+		}
+	}
+
+	for _, window := range c.Maintenance {
+		position := v.source.component("maintenance", window.Name)
+		v.report(position, window.Validate())
+		v.unique("maintenance window", window.Name, position)
+	}
+
+	v.report(v.source.key("canary"), c.Canary.Validate())
+	for _, name := range c.Canary.Notificators {
+		if !v.exists("notificator", name) {
+			v.report(v.source.key("canary"), fmt.Errorf("[canary] - notificator '%s' not found", name))
+		}
+	}
+
+	v.report(v.source.key("api"), c.API.Validate())
+
+	return errors.Join(v.errs...)
+}
+
+func (c *Config) validateResources(v *configValidator) {
+	for _, r := range c.Resources.Http {
+		position := v.source.component("resources.http", r.Name)
+		if err := r.Validate(); err != nil {
+			v.report(position, fmt.Errorf("[[resources.http]] %s - %w", r.Name, err))
+		}
+		v.unique("resource", r.Name, position)
+	}
+	for _, r := range c.Resources.Ping {
+		position := v.source.component("resources.ping", r.Name)
+		if err := r.Validate(); err != nil {
+			v.report(position, fmt.Errorf("[[resources.ping]] %s - %w", r.Name, err))
+		}
+		v.unique("resource", r.Name, position)
+	}
+	for _, r := range c.Resources.Nagios {
+		position := v.source.component("resources.nagios", r.Name)
+		if err := r.Validate(); err != nil {
+			v.report(position, fmt.Errorf("[[resources.nagios]] %s - %w", r.Name, err))
+		}
+		v.unique("resource", r.Name, position)
+	}
+	for _, r := range c.Resources.Exec {
+		position := v.source.component("resources.exec", r.Name)
+		if err := r.Validate(); err != nil {
+			v.report(position, fmt.Errorf("[[resources.exec]] %s - %w", r.Name, err))
+		}
+		v.unique("resource", r.Name, position)
+	}
+	tokens := make(map[string]string)
+	for _, r := range c.Resources.Heartbeat {
+		position := v.source.component("resources.heartbeat", r.Name)
+		if err := r.Validate(); err != nil {
+			v.report(position, fmt.Errorf("[[resources.heartbeat]] %s - %w", r.Name, err))
+		}
+		v.unique("resource", r.Name, position)
+		if other, exists := tokens[r.Token]; exists && r.Token != "" {
+			v.report(position, fmt.Errorf("[[resources.heartbeat]] %s - token is already used by '%s'", r.Name, other))
+		}
+		tokens[r.Token] = r.Name
+	}
+	if len(c.Resources.Heartbeat) > 0 && c.API.Listen == "" {
+		v.report(v.source.key("resources.heartbeat"), errors.New("[[resources.heartbeat]] requires [api] listen, pings are received by the API server"))
+	}
+	for _, r := range c.Resources.Group {
+		position := v.source.component("resources.group", r.Name)
+		if err := r.Validate(); err != nil {
+			v.report(position, fmt.Errorf("[[resources.group]] %s - %w", r.Name, err))
+		}
+		v.unique("resource", r.Name, position)
+	}
+	// members are checked after all groups are known, as groups may
+	// contain groups defined later
+	for _, r := range c.Resources.Group {
+		for _, member := range r.Members {
+			if !v.exists("resource", member) {
+				v.report(v.source.component("resources.group", r.Name), fmt.Errorf("[[resources.group]] %s - member '%s' not found", r.Name, member))
+			}
+		}
+	}
+	if err := c.Resources.ValidateGroups(); errors.Is(err, resources.ResourceMembersCycleError) {
+		v.report(v.source.key("resources.group"), fmt.Errorf("invalid resource groups: %w", err))
+	}
+	if err := c.Resources.ValidateDependencies(); err != nil {
+		v.report(v.source.key("resources"), fmt.Errorf("invalid resource dependencies: %w", err))
+	}
+}
+
+func (c *Config) validateNotificators(v *configValidator) {
+	v.report(v.source.key("notificators.templates"), c.Notificators.Templates.Validate())
+
+	check := func(table, name string, err error) {
+		position := v.source.component(table, name)
+		v.report(position, err)
+		v.unique("notificator", name, position)
+	}
+	for _, n := range c.Notificators.Console {
+		check("notificators.console", n.Name, n.Validate())
+	}
+	for _, n := range c.Notificators.Telegram {
+		check("notificators.telegram", n.Name, n.Validate())
+	}
+	for _, n := range c.Notificators.Matrix {
+		check("notificators.matrix", n.Name, n.Validate())
+	}
+	for _, n := range c.Notificators.Exec {
+		check("notificators.exec", n.Name, n.Validate())
+	}
+	for _, n := range c.Notificators.File {
+		check("notificators.file", n.Name, n.Validate())
+	}
+	for _, n := range c.Notificators.Syslog {
+		check("notificators.syslog", n.Name, n.Validate())
+	}
+}
+
+func (c *Config) validateMonitors(v *configValidator) {
+	for _, m := range c.Monitors.Cron {
+		position := v.source.component("monitors.cron", m.Name)
+		if _, err := monitors.NewCronMonitor(m); err != nil {
+			v.report(position, err)
+		}
+		v.unique("monitor", m.Name, position)
+
+		for _, name := range m.Resources {
+			if !v.exists("resource", name) {
+				v.report(position, fmt.Errorf("[[monitors.cron]] %s - resource '%s' not found", m.Name, name))
+			}
+		}
+		for _, name := range m.Notificators {
+			if !v.exists("notificator", name) {
+				v.report(position, fmt.Errorf("[[monitors.cron]] %s - notificator '%s' not found", m.Name, name))
+			}
+		}
+		if m.Escalation != "" && !v.exists("escalation policy", m.Escalation) {
+			v.report(position, fmt.Errorf("[[monitors.cron]] %s - escalation policy '%s' not found", m.Name, m.Escalation))
+		}
+	}
+}
+
+// unknownKeys returns keys of the file, which don't match any config
+// value. Keys of unknown tables are not reported separately.
+func unknownKeys(undecoded []string, source *configSource) []error {
+	var errs []error
+	var reported []string
+	for _, key := range undecoded {
+		if slices.ContainsFunc(reported, func(parent string) bool {
+			return strings.HasPrefix(key, parent+".")
+		}) {
+			continue
+		}
+		reported = append(reported, key)
+
+		errs = append(errs, fmt.Errorf("%s: unknown key '%s'", source.key(key), key))
+	}
+	return errs
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig_UnknownKeys(t *testing.T) {
+	path := writeConfig(t, `log_level = 'info'
+
+[[resources.http]]
+name = 'api'
+url = 'https://api.example.com'
+expected_statsu = 200
+
+[resourses.ping]
+name = 'db'
+`)
+	_, err := ParseConfig(path)
+	if err == nil {
+		t.Fatal("Expected unknown keys to be rejected")
+	}
+	expected := path + ":6: unknown key 'resources.http.expected_statsu'\n" +
+		path + ":8: unknown key 'resourses.ping'"
+	if err.Error() != expected {
+		t.Errorf("Expected errors:\n%s\ngot:\n%s", expected, err)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	path := writeConfig(t, `log_level = 'verbose'
+
+[[resources.http]]
+name = 'api'
+url = 'ftp://api.example.com'
+
+[[resources.ping]]
+name = 'api'
+address = 'api.example.com'
+timeout_seconds = 5
+
+[[notificators.telegram]]
+name = 'bot'
+chat_id = '1'
+
+[[monitors.cron]]
+name = 'every-minute'
+resources = ['api', 'db']
+notificators = ['bot', 'mail']
+escalation = 'oncall'
+cron = '* * * *'
+`)
+	config, err := ParseConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = config.Validate()
+	if err == nil {
+		t.Fatal("Expected invalid config to be rejected")
+	}
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) {
+		t.Fatalf("Expected joined errors, got %v", err)
+	}
+	expected := []string{
+		path + ":1: log_level must be one of debug, info or error",
+		path + ":3: [[resources.http]] api - url must use http or https scheme",
+		path + ":7: duplicate resource name 'api', it is already defined at " + path + ":3",
+		path + ":12: [[notificator.telegram]] - token can't be empty",
+		path + ":16: [[monitors.cron]] every-minute - cron:",
+		path + ":16: [[monitors.cron]] every-minute - resource 'db' not found",
+		path + ":16: [[monitors.cron]] every-minute - notificator 'mail' not found",
+		path + ":16: [[monitors.cron]] every-minute - escalation policy 'oncall' not found",
+	}
+	errs := joined.Unwrap()
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d:\n%v", len(expected), len(errs), err)
+	}
+	for i, prefix := range expected {
+		if !strings.HasPrefix(errs[i].Error(), prefix) {
+			t.Errorf("Expected error %q, got %q", prefix, errs[i])
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/andrewsapw/avalio/api"
+	"github.com/andrewsapw/avalio/app"
+)
+
+// apiFlags are flags of commands, which call API of a running instance
+type apiFlags struct {
+	configPath *string
+	url        *string
+	token      *string
+}
+
+func addAPIFlags(flags *flag.FlagSet) apiFlags {
+	return apiFlags{
+		configPath: flags.String("config", "", "config path, API address and token are read from [api] section"),
+		url:        flags.String("api", "", "API base URL, e.g. http://127.0.0.1:8080"),
+		token:      flags.String("token", "", "API token"),
+	}
+}
+
+// client creates API client, flags override values from the config
+func (f apiFlags) client() (*api.Client, error) {
+	url, token := *f.url, *f.token
+	if *f.configPath != "" {
+		config, err := app.ParseConfig(*f.configPath)
+		if err != nil {
+			return nil, err
+		}
+		if url == "" {
+			url = config.API.URL()
+		}
+		if token == "" {
+			token = config.API.Token
+		}
+	}
+	if url == "" {
+		return nil, fmt.Errorf("API address is not set, use -api or -config")
+	}
+	return api.NewClient(url, token), nil
+}
+
+// runAck acknowledges open incidents of the resource:
+//
+//	avalio ack -config config.toml -by alice api
+func runAck(args []string) error {
+	flags := flag.NewFlagSet("ack", flag.ExitOnError)
+	apiFlags := addAPIFlags(flags)
+	by := flags.String("by", os.Getenv("USER"), "who acknowledges the incident")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: avalio ack [flags] <resource>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly one resource name")
+	}
+
+	client, err := apiFlags.client()
+	if err != nil {
+		return err
+	}
+	if err := client.Acknowledge(flags.Arg(0), *by); err != nil {
+		return err
+	}
+	fmt.Printf("Resource %s acknowledged\n", flags.Arg(0))
+	return nil
+}
@@ -1,70 +1,273 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"flag"
-	"log"
+	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/andrewsapw/avalio/app"
+	"github.com/andrewsapw/avalio/logging"
 	"github.com/andrewsapw/avalio/monitors"
 	"github.com/andrewsapw/avalio/notificators"
 	"github.com/andrewsapw/avalio/resources"
+	"github.com/andrewsapw/avalio/status"
 )
 
+// components are application parts built from configuration
+type components struct {
+	language     status.Language
+	resources    []resources.Resource
+	notificators []notificators.Notificator
+	monitors     []monitors.Monitor
+	options      app.Options
+}
+
+// commands are subcommands of avalio, the first argument selects one of them
+var commands = map[string]func(args []string) error{
+	"run":         runDaemon,
+	"validate":    runValidate,
+	"check":       runCheck,
+	"list":        runList,
+	"notify-test": runNotifyTest,
+	"version":     runVersion,
+	"ack":         runAck,
+	"silence":     runSilence,
+}
+
+const usage = `Usage: avalio <command> [flags]
+
+Commands:
+  run          start monitoring, default if command is omitted
+  validate     check configuration
+  check        check resource now
+  list         list resources and monitors
+  notify-test  send test notification
+  version      print version
+  ack          acknowledge incident of running instance
+  silence      manage silences of running instance
+
+Run 'avalio <command> -h' for command flags.`
+
 func StartAvalio() {
-	configPath := flag.String("config", "", "config path")
-	flag.Parse()
+	args := os.Args[1:]
+	command := runDaemon
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		if args[0] == "help" {
+			fmt.Println(usage)
+			return
+		}
+		var exists bool
+		command, exists = commands[args[0]]
+		if !exists {
+			fmt.Fprintf(os.Stderr, "unknown command '%s'\n\n%s\n", args[0], usage)
+			os.Exit(2)
+		}
+		args = args[1:]
+	}
+
+	if err := command(args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		var exit *exitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
+		os.Exit(1)
+	}
+}
+
+// exitError is an error, which sets exit code of the process
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// runDaemon starts monitoring and blocks until SIGINT or SIGTERM:
+//
+//	avalio run -config config.toml
+//
+// With -once resources are checked once, see runOnce.
+func runDaemon(args []string) error {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	configPath := flags.String("config", "", "config path")
+	watchInterval := flags.Duration("watch", 0, "interval of config file changes checks, e.g. 5s, changes are not watched if it is zero")
+	once := addOnceFlags(flags)
+	flags.Parse(args)
+
+	if *once.enabled {
+		return runOnce(*configPath, once)
+	}
 
 	config, err := app.ParseConfig(*configPath)
 	if err != nil {
-		log.Default().Fatal(err.Error())
-		os.Exit(1)
+		return err
+	}
+
+	// level and secrets may be changed on reload
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(parseLogLevel(config.LogLevel))
+	redactor := logging.NewRedactor(config.Secrets())
+	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel})
+	logger := slog.New(logging.NewRedactingHandler(handler, redactor))
+
+	slog.SetDefault(logger)
+
+	slog.Info("Loading configuration file", "config_path", *configPath)
+
+	built, err := buildComponents(config)
+	if err != nil {
+		logErrors(err)
+		return fmt.Errorf("invalid configuration")
 	}
 
-	var logLevel slog.Level
-	switch strings.ToLower(config.LogLevel) {
+	application := app.NewApplication(built.resources, built.notificators, built.monitors, built.options)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	reloader := &reloader{
+		application: application,
+		configPath:  *configPath,
+		logLevel:    logLevel,
+		redactor:    redactor,
+		secrets:     config.Secrets(),
+	}
+	reload := make(chan struct{}, 1)
+	go watchReloadSignal(ctx, reload)
+	if *watchInterval > 0 {
+		go watchConfigFile(ctx, *configPath, *watchInterval, reload)
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-reload:
+				reloader.reload()
+			}
+		}
+	}()
+
+	return application.Run(ctx)
+}
+
+// logErrors logs each of joined errors, e.g. of config validation,
+// separately
+func logErrors(err error) {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		slog.Error(err.Error())
+		return
+	}
+	for _, err := range joined.Unwrap() {
+		logErrors(err)
+	}
+}
+
+func parseLogLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
 	case "info":
-		logLevel = slog.LevelInfo
+		return slog.LevelInfo
 	case "debug":
-		logLevel = slog.LevelDebug
+		return slog.LevelDebug
 	case "error":
-		logLevel = slog.LevelError
+		return slog.LevelError
 	default:
-		logLevel = slog.LevelInfo
+		return slog.LevelInfo
 	}
+}
 
-	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel})
-	logger := slog.New(handler)
+// buildComponents validates configuration and builds resources,
+// notificators and monitors. The default language is set, as it is used
+// by notificators without their own language setting.
+func buildComponents(config *app.Config) (*components, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 
-	slog.SetDefault(logger)
+	language, err := status.ParseLanguage(config.Language)
+	if err != nil {
+		return nil, err
+	}
+	status.SetDefaultLanguage(language)
 
-	slog.Info("Loading configuration file", "config_path", *configPath)
+	resourcesList, err := resources.BuildResources(&config.Resources)
+	if err != nil {
+		return nil, err
+	}
 
-	resources, err := resources.BuildResources(&config.Resources)
+	notificatorsList, err := notificators.BuildNotificators(&config.Notificators, config.Monitors.Templates(), language)
 	if err != nil {
-		slog.Error(err.Error())
-		os.Exit(1)
+		return nil, err
 	}
 
-	notificators, err := notificators.BuildNotificators(&config.Notificators)
+	monitorsList, err := monitors.BuildMonitors(&config.Monitors)
 	if err != nil {
-		slog.Error(err.Error())
-		os.Exit(1)
+		return nil, err
+	}
+
+	// notificators render messages with shared and monitor templates, so
+	// they are rebuilt if any of them changes
+	templates := notificators.Templates{
+		Global:   config.Notificators.Templates,
+		Monitors: config.Monitors.Templates(),
+		Language: language,
+	}
+	notificatorConfigs := make(map[string]any)
+	for name, notificatorConfig := range config.Notificators.Configs() {
+		notificatorConfigs[name] = []any{notificatorConfig, templates}
 	}
 
-	monitors, err := monitors.BuildMonitors(&config.Monitors)
+	return &components{
+		language:     language,
+		resources:    resourcesList,
+		notificators: notificatorsList,
+		monitors:     monitorsList,
+		options: app.Options{
+			Delivery:           config.Delivery,
+			Grouping:           config.Grouping,
+			Routing:            config.Routing,
+			Escalations:        config.Escalations,
+			MonitorEscalations: config.Monitors.Escalations(),
+			Maintenance:        config.Maintenance,
+			Canary:             config.Canary,
+			NotificatorLabels:  config.Notificators.Labels(),
+			API:                config.API,
+			ResourceConfigs:    config.Resources.Configs(),
+			MonitorConfigs:     config.Monitors.Configs(),
+			NotificatorConfigs: notificatorConfigs,
+		},
+	}, nil
+}
+
+// loadComponents builds components for one-off commands. Only warnings and
+// errors are logged, so they don't mix with the command output.
+func loadComponents(configPath string) (*app.Config, *components, error) {
+	config, err := app.ParseConfig(configPath)
 	if err != nil {
-		slog.Error(err.Error())
-		os.Exit(1)
+		return nil, nil, err
 	}
 
-	application := app.NewApplication(resources, notificators, monitors)
+	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})
+	redactor := logging.NewRedactor(config.Secrets())
+	slog.SetDefault(slog.New(logging.NewRedactingHandler(handler, redactor)))
 
-	err = application.Run()
+	built, err := buildComponents(config)
 	if err != nil {
-		slog.Error(err.Error())
-		os.Exit(1)
+		return nil, nil, err
 	}
+	return config, built, nil
 }
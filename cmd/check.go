@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+// runCheck runs check of the resource once and prints its details, it
+// fails if the resource is not available:
+//
+//	avalio check -config config.toml api
+//
+// With -nagios the result is printed as Nagios plugin output, see
+// runNagiosCheck.
+func runCheck(args []string) error {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	configPath := flags.String("config", "", "config path")
+	nagios := flags.Bool("nagios", false, "print result in Nagios plugin format and exit with Nagios exit code")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: avalio check [flags] <resource>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly one resource name")
+	}
+	if *nagios {
+		return runNagiosCheck(*configPath, flags.Arg(0))
+	}
+
+	_, built, err := loadComponents(*configPath)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range built.resources {
+		if r.GetName() != flags.Arg(0) {
+			continue
+		}
+
+		available, details := r.RunCheck()
+		state := status.StateAvailable
+		if !available {
+			state = status.StateNotAvailable
+		}
+		fmt.Printf("%s (%s): %s\n", r.GetName(), r.GetType(), state)
+		printDetails(details, built.language)
+
+		if !available {
+			return fmt.Errorf("resource %s is not available", r.GetName())
+		}
+		return nil
+	}
+	return fmt.Errorf("Resource '%s' not found", flags.Arg(0))
+}
+
+func printDetails(details []status.CheckDetails, language status.Language) {
+	for _, detail := range details {
+		fmt.Printf("  %s: %s\n", detail.TitleIn(language), detail.DescriptionIn(language))
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/andrewsapw/avalio/app"
+)
+
+// runList prints resources and monitors with their next run times:
+//
+//	avalio list -config config.toml
+func runList(args []string) error {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	configPath := flags.String("config", "", "config path")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: avalio list [flags]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	config, built, err := loadComponents(*configPath)
+	if err != nil {
+		return err
+	}
+	application := app.NewApplication(built.resources, built.notificators, built.monitors, built.options)
+	monitorsResources, monitorsNotificators, err := application.MonitorsTargets()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tTYPE\tLABELS\tDEPENDS ON")
+	for _, r := range built.resources {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.GetName(), r.GetType(), formatLabels(r.GetLabels()), formatList(r.GetDependencies()))
+	}
+	fmt.Fprintln(w)
+
+	schedules := make(map[string]string)
+	for _, monitorConfig := range config.Monitors.Cron {
+		schedules[monitorConfig.Name] = monitorConfig.Cron
+	}
+	fmt.Fprintln(w, "MONITOR\tSCHEDULE\tNEXT RUN\tRESOURCES\tNOTIFICATORS")
+	for _, m := range built.monitors {
+		var resourcesNames []string
+		for _, r := range monitorsResources[m.GetName()] {
+			resourcesNames = append(resourcesNames, r.GetName())
+		}
+		fmt.Fprintf(
+			w, "%s\t%s\t%s\t%s\t%s\n",
+			m.GetName(),
+			schedules[m.GetName()],
+			m.Next().Format(time.DateTime),
+			formatList(resourcesNames),
+			formatList(monitorsNotificators[m.GetName()]),
+		)
+	}
+	return w.Flush()
+}
+
+func formatList(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ",")
+}
+
+func formatLabels(labels map[string]string) string {
+	var pairs []string
+	for _, name := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, name+"="+labels[name])
+	}
+	return formatList(pairs)
+}
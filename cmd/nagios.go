@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andrewsapw/avalio/resources"
+	"github.com/andrewsapw/avalio/status"
+)
+
+// runNagiosCheck checks the resource as Nagios plugin, so Nagios and Icinga
+// can run avalio checks:
+//
+//	OK - api (http) is available | time=0.125s;;;0
+//
+// Exit code is 0 if the resource is available, 2 if it isn't and 3 if it
+// can't be checked.
+func runNagiosCheck(configPath string, resourceName string) error {
+	_, built, err := loadComponents(configPath)
+	if err != nil {
+		return nagiosUnknown(err)
+	}
+
+	for _, r := range built.resources {
+		if r.GetName() != resourceName {
+			continue
+		}
+
+		started := time.Now()
+		available, details := r.RunCheck()
+		elapsed := time.Since(started)
+
+		state, resourceState := resources.NagiosOK, status.StateAvailable
+		if !available {
+			state, resourceState = resources.NagiosCritical, status.StateNotAvailable
+		}
+		perfData := []string{
+			status.Measurement{Label: "time", Value: elapsed.Round(time.Millisecond).Seconds(), Unit: "s", Min: new(float64)}.PerfData(),
+		}
+		var longOutput []string
+		for _, detail := range details {
+			if measurement, ok := detail.Measurement(); ok {
+				perfData = append(perfData, measurement.PerfData())
+				continue
+			}
+			longOutput = append(longOutput, nagiosText(detail.TitleIn(built.language)+": "+detail.DescriptionIn(built.language)))
+		}
+
+		fmt.Printf(
+			"%s - %s (%s) is %s | %s\n",
+			state, nagiosText(r.GetName()), r.GetType(), resourceState, strings.Join(perfData, " "),
+		)
+		for _, line := range longOutput {
+			fmt.Println(line)
+		}
+		if !available {
+			return &exitError{code: int(state), err: fmt.Errorf("resource %s is not available", r.GetName())}
+		}
+		return nil
+	}
+	return nagiosUnknown(fmt.Errorf("Resource '%s' not found", resourceName))
+}
+
+// nagiosUnknown prints error as UNKNOWN state and sets its exit code
+func nagiosUnknown(err error) error {
+	fmt.Printf("%s - %s\n", resources.NagiosUnknown, nagiosText(strings.ReplaceAll(err.Error(), "\n", "; ")))
+	return &exitError{code: int(resources.NagiosUnknown), err: err}
+}
+
+// nagiosText replaces "|", which separates performance data in plugin
+// output
+func nagiosText(text string) string {
+	return strings.ReplaceAll(text, "|", "/")
+}
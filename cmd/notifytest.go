@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+// runNotifyTest sends synthetic check result to the notificator, so its
+// credentials and templates can be checked:
+//
+//	avalio notify-test -config config.toml -state recovered telegram
+func runNotifyTest(args []string) error {
+	flags := flag.NewFlagSet("notify-test", flag.ExitOnError)
+	configPath := flags.String("config", "", "config path")
+	stateName := flags.String("state", status.StateNotAvailable.String(), "state of the check result, e.g. 'not available' or recovered")
+	resourceName := flags.String("resource", "avalio-test", "resource name of the check result")
+	monitorName := flags.String("monitor", "", "monitor name of the check result, selects monitor templates")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: avalio notify-test [flags] <notificator>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly one notificator name")
+	}
+	state, err := status.ParseResourceState(*stateName)
+	if err != nil {
+		return err
+	}
+
+	_, built, err := loadComponents(*configPath)
+	if err != nil {
+		return err
+	}
+
+	for _, n := range built.notificators {
+		if n.GetName() != flags.Arg(0) {
+			continue
+		}
+
+		checkResult := status.NewCheckResult(
+			*resourceName,
+			"test",
+			[]status.CheckDetails{
+				status.NewCheckDetails(status.Msg(status.MsgReason), status.Msg(status.MsgTestNotification)),
+			},
+			state,
+		)
+		checkResult.MonitorName = *monitorName
+		if err := n.Send(checkResult); err != nil {
+			return fmt.Errorf("Notificator '%s' failed to send: %v", n.GetName(), err)
+		}
+		fmt.Printf("Test notification sent to %s\n", n.GetName())
+		return nil
+	}
+	return fmt.Errorf("Notificator '%s' not found", flags.Arg(0))
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andrewsapw/avalio/app"
+	"github.com/andrewsapw/avalio/labels"
+	"github.com/andrewsapw/avalio/status"
+)
+
+// maxExitCode limits exit code of -once run, greater codes have special
+// meaning in shells
+const maxExitCode = 125
+
+// onceFlags are flags of one-shot run
+type onceFlags struct {
+	enabled  *bool
+	selector *string
+	format   *string
+	output   *string
+	notify   *bool
+}
+
+func addOnceFlags(flags *flag.FlagSet) onceFlags {
+	return onceFlags{
+		enabled:  flags.Bool("once", false, "check every resource once and exit, exit code is the number of failed resources"),
+		selector: flags.String("selector", "", "label selector of resources checked with -once, e.g. env=prod,team!=infra"),
+		format:   flags.String("format", "human", "output format of -once: human, json or junit"),
+		output:   flags.String("output", "", "file to write -once results to, stdout if it is not set"),
+		notify:   flags.Bool("notify", false, "send notifications about resources failed with -once"),
+	}
+}
+
+// runOnce checks resources once, e.g. as smoke tests after deploy:
+//
+//	avalio run -once -config config.toml -selector env=prod -format junit -output report.xml
+func runOnce(configPath string, flags onceFlags) error {
+	var write func(w io.Writer, results []app.OnceResult, language status.Language) error
+	switch *flags.format {
+	case "human":
+		write = writeHuman
+	case "json":
+		write = writeJSON
+	case "junit":
+		write = writeJUnit
+	default:
+		return fmt.Errorf("unknown format '%s', expected human, json or junit", *flags.format)
+	}
+	selector, err := labels.Parse(*flags.selector)
+	if err != nil {
+		return fmt.Errorf("selector: %v", err)
+	}
+
+	_, built, err := loadComponents(configPath)
+	if err != nil {
+		return err
+	}
+	application := app.NewApplication(built.resources, built.notificators, built.monitors, built.options)
+	results, err := application.RunOnce(selector, *flags.notify)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no resources selected")
+	}
+
+	output := os.Stdout
+	if *flags.output != "" {
+		output, err = os.Create(*flags.output)
+		if err != nil {
+			return err
+		}
+		defer output.Close()
+	}
+	if err := write(output, results, built.language); err != nil {
+		return err
+	}
+
+	failures := 0
+	for _, result := range results {
+		if result.Failed() {
+			failures++
+		}
+	}
+	if failures > 0 {
+		return &exitError{
+			code: min(failures, maxExitCode),
+			err:  fmt.Errorf("%d of %d resources are not available", failures, len(results)),
+		}
+	}
+	return nil
+}
+
+func writeHuman(w io.Writer, results []app.OnceResult, language status.Language) error {
+	for _, result := range results {
+		fmt.Fprintf(
+			w, "%s (%s): %s in %s\n",
+			result.ResourceName, result.ResourceType, result.State, result.Duration.Round(time.Millisecond),
+		)
+		for _, detail := range result.Details {
+			fmt.Fprintf(w, "  %s: %s\n", detail.TitleIn(language), detail.DescriptionIn(language))
+		}
+	}
+	return nil
+}
+
+type jsonResult struct {
+	app.OnceResult
+	DurationSeconds float64 `json:"duration_seconds"`
+	Failed          bool    `json:"failed"`
+}
+
+func writeJSON(w io.Writer, results []app.OnceResult, language status.Language) error {
+	report := struct {
+		Total    int          `json:"total"`
+		Failures int          `json:"failures"`
+		Results  []jsonResult `json:"results"`
+	}{Total: len(results), Results: []jsonResult{}}
+	for _, result := range results {
+		if result.Failed() {
+			report.Failures++
+		}
+		report.Results = append(report.Results, jsonResult{
+			OnceResult:      result,
+			DurationSeconds: result.Duration.Seconds(),
+			Failed:          result.Failed(),
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnit(w io.Writer, results []app.OnceResult, language status.Language) error {
+	suite := junitTestSuite{
+		Name:      "avalio",
+		Tests:     len(results),
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	// checks run concurrently, so the suite takes as long as the slowest one
+	var elapsed time.Duration
+	for _, result := range results {
+		elapsed = max(elapsed, result.Duration)
+
+		testCase := junitTestCase{
+			Name:      result.ResourceName,
+			ClassName: "avalio." + result.ResourceType,
+			Time:      junitTime(result.Duration),
+		}
+		if result.Failed() {
+			suite.Failures++
+			var details strings.Builder
+			for _, detail := range result.Details {
+				fmt.Fprintf(&details, "%s: %s\n", detail.TitleIn(language), detail.DescriptionIn(language))
+			}
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("%s is %s", result.ResourceName, result.State),
+				Type:    result.State.String(),
+				Text:    details.String(),
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Time = junitTime(elapsed)
+
+	report := junitTestSuites{
+		Name:     suite.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/andrewsapw/avalio/app"
+	"github.com/andrewsapw/avalio/logging"
+	"github.com/andrewsapw/avalio/status"
+)
+
+// reloader applies configuration file changes to the running application
+type reloader struct {
+	application *app.Application
+	configPath  string
+	logLevel    *slog.LevelVar
+	redactor    *logging.Redactor
+	// secrets are secret values of the current configuration
+	secrets []string
+}
+
+// reload parses configuration file and applies it. Invalid configuration
+// is logged and the current one is kept.
+func (r *reloader) reload() {
+	slog.Info("Reloading configuration file", "config_path", r.configPath)
+
+	previousLanguage := status.DefaultLanguage()
+	err := func() error {
+		config, err := app.ParseConfig(r.configPath)
+		if err != nil {
+			return err
+		}
+		// secrets of both configurations may be logged until reload ends
+		secrets := config.Secrets()
+		r.redactor.SetSecrets(append(slices.Clone(r.secrets), secrets...))
+
+		built, err := buildComponents(config)
+		if err != nil {
+			return err
+		}
+		if err := r.application.Reload(built.resources, built.notificators, built.monitors, built.options); err != nil {
+			return err
+		}
+		r.logLevel.Set(parseLogLevel(config.LogLevel))
+		r.secrets = secrets
+		return nil
+	}()
+	if err != nil {
+		status.SetDefaultLanguage(previousLanguage)
+		logErrors(err)
+		slog.Error("Configuration reload failed, the current configuration is kept")
+	}
+	r.redactor.SetSecrets(r.secrets)
+}
+
+// watchReloadSignal requests reload on SIGHUP
+func watchReloadSignal(ctx context.Context, reload chan<- struct{}) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			requestReload(reload)
+		}
+	}
+}
+
+// watchConfigFile requests reload when config files are added or removed,
+// or modification time or size of any of them changes
+func watchConfigFile(ctx context.Context, configPath string, interval time.Duration, reload chan<- struct{}) {
+	last, err := configSnapshot(configPath)
+	if err != nil {
+		slog.Error("Failed to watch configuration file", "config_path", configPath, "error", err)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		snapshot, err := configSnapshot(configPath)
+		if err != nil {
+			// the file may be replaced by an editor right now
+			slog.Debug("Failed to check configuration file", "config_path", configPath, "error", err)
+			continue
+		}
+		if slices.Equal(snapshot, last) {
+			continue
+		}
+		last = snapshot
+		slog.Info("Configuration file has changed", "config_path", configPath)
+		requestReload(reload)
+	}
+}
+
+// configFileState is a config file with its modification time and size
+type configFileState struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+func configSnapshot(configPath string) ([]configFileState, error) {
+	files, err := app.ConfigFiles(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot []configFileState
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		snapshot = append(snapshot, configFileState{path: file, modTime: info.ModTime(), size: info.Size()})
+	}
+	return snapshot, nil
+}
+
+// requestReload doesn't block, as pending reload reads the latest file
+func requestReload(reload chan<- struct{}) {
+	select {
+	case reload <- struct{}{}:
+	default:
+	}
+}
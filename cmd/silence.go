@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewsapw/avalio/api"
+)
+
+// runSilence manages silences of a running instance:
+//
+//	avalio silence add -config config.toml -resources api -duration 2h
+//	avalio silence list -config config.toml
+//	avalio silence expire -config config.toml 1
+func runSilence(args []string) error {
+	usage := "Usage: avalio silence add|list|expire [flags]"
+	if len(args) == 0 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	switch args[0] {
+	case "add":
+		return runSilenceAdd(args[1:])
+	case "list":
+		return runSilenceList(args[1:])
+	case "expire":
+		return runSilenceExpire(args[1:])
+	default:
+		return fmt.Errorf("unknown command '%s'\n%s", args[0], usage)
+	}
+}
+
+func runSilenceAdd(args []string) error {
+	flags := flag.NewFlagSet("silence add", flag.ExitOnError)
+	apiFlags := addAPIFlags(flags)
+	resources := flags.String("resources", "", "comma separated resource names")
+	monitors := flags.String("monitors", "", "comma separated monitor names")
+	labels := flags.String("labels", "", "comma separated labels, e.g. env=prod,team=payments")
+	duration := flags.Duration("duration", time.Hour, "silence duration, e.g. 30m or 2h")
+	comment := flags.String("comment", "", "reason of the silence")
+	by := flags.String("by", os.Getenv("USER"), "who creates the silence")
+	flags.Parse(args)
+
+	parsedLabels, err := parseLabels(*labels)
+	if err != nil {
+		return err
+	}
+
+	client, err := apiFlags.client()
+	if err != nil {
+		return err
+	}
+	silence, err := client.AddSilence(api.SilenceRequest{
+		Resources:       splitList(*resources),
+		Monitors:        splitList(*monitors),
+		Labels:          parsedLabels,
+		DurationMinutes: int(math.Ceil(duration.Minutes())),
+		Comment:         *comment,
+		By:              *by,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Silence #%d created, ends at %s\n", silence.ID, silence.EndsAt.Format(time.DateTime))
+	return nil
+}
+
+func runSilenceList(args []string) error {
+	flags := flag.NewFlagSet("silence list", flag.ExitOnError)
+	apiFlags := addAPIFlags(flags)
+	flags.Parse(args)
+
+	client, err := apiFlags.client()
+	if err != nil {
+		return err
+	}
+	silences, err := client.Silences()
+	if err != nil {
+		return err
+	}
+	if len(silences) == 0 {
+		fmt.Println("No silences")
+		return nil
+	}
+
+	for _, silence := range silences {
+		var scope []string
+		if len(silence.Resources) > 0 {
+			scope = append(scope, "resources="+strings.Join(silence.Resources, ","))
+		}
+		if len(silence.Monitors) > 0 {
+			scope = append(scope, "monitors="+strings.Join(silence.Monitors, ","))
+		}
+		for name, value := range silence.Labels {
+			scope = append(scope, name+"="+value)
+		}
+		fmt.Printf(
+			"#%d until %s %s %s %s\n",
+			silence.ID,
+			silence.EndsAt.Format(time.DateTime),
+			strings.Join(scope, " "),
+			silence.CreatedBy,
+			silence.Comment,
+		)
+	}
+	return nil
+}
+
+func runSilenceExpire(args []string) error {
+	flags := flag.NewFlagSet("silence expire", flag.ExitOnError)
+	apiFlags := addAPIFlags(flags)
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("Usage: avalio silence expire [flags] <id>")
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(flags.Arg(0), "#"))
+	if err != nil {
+		return fmt.Errorf("invalid silence id '%s'", flags.Arg(0))
+	}
+
+	client, err := apiFlags.client()
+	if err != nil {
+		return err
+	}
+	if err := client.ExpireSilence(id); err != nil {
+		return err
+	}
+	fmt.Printf("Silence #%d expired\n", id)
+	return nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseLabels parses "name=value,name=value"
+func parseLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, item := range splitList(s) {
+		name, value, found := strings.Cut(item, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid label '%s', expected name=value", item)
+		}
+		labels[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
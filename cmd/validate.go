@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/andrewsapw/avalio/app"
+)
+
+// runValidate checks configuration without starting monitoring:
+//
+//	avalio validate -config config.toml
+func runValidate(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	configPath := flags.String("config", "", "config path")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: avalio validate [flags]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	_, built, err := loadComponents(*configPath)
+	if err != nil {
+		return err
+	}
+
+	// monitors must refer to existing resources and notificators
+	application := app.NewApplication(built.resources, built.notificators, built.monitors, built.options)
+	if _, _, err := application.MonitorsTargets(); err != nil {
+		return err
+	}
+
+	files, err := app.ConfigFiles(*configPath)
+	if err != nil {
+		return err
+	}
+	fmt.Printf(
+		"Configuration is valid: %d files, %d resources, %d monitors, %d notificators\n",
+		len(files), len(built.resources), len(built.monitors), len(built.notificators),
+	)
+	return nil
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Version is set on build:
+//
+//	go build -ldflags "-X github.com/andrewsapw/avalio/cmd.Version=v1.2.0"
+var Version = ""
+
+// version returns the build version, module version is used if it is not set
+func version() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+// runVersion prints version:
+//
+//	avalio version
+func runVersion(args []string) error {
+	fmt.Printf("avalio %s %s %s/%s\n", version(), runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	return nil
+}
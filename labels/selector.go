@@ -0,0 +1,168 @@
+// Package labels implements label selectors, which choose resources and
+// notificators by their labels.
+package labels
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+type Operator string
+
+const (
+	OperatorEquals       Operator = "="
+	OperatorNotEquals    Operator = "!="
+	OperatorIn           Operator = "in"
+	OperatorNotIn        Operator = "notin"
+	OperatorExists       Operator = "exists"
+	OperatorDoesNotExist Operator = "!"
+)
+
+var (
+	keyPattern = regexp.MustCompile(`^[A-Za-z0-9_./-]+$`)
+	setPattern = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+)
+
+// Requirement is a single condition of a selector
+type Requirement struct {
+	Key      string
+	Operator Operator
+	Values   []string
+}
+
+func (r Requirement) Matches(labels map[string]string) bool {
+	value, exists := labels[r.Key]
+	switch r.Operator {
+	case OperatorEquals:
+		return exists && value == r.Values[0]
+	case OperatorNotEquals:
+		return !exists || value != r.Values[0]
+	case OperatorIn:
+		return exists && slices.Contains(r.Values, value)
+	case OperatorNotIn:
+		return !exists || !slices.Contains(r.Values, value)
+	case OperatorExists:
+		return exists
+	case OperatorDoesNotExist:
+		return !exists
+	default:
+		return false
+	}
+}
+
+func (r Requirement) String() string {
+	switch r.Operator {
+	case OperatorEquals, OperatorNotEquals:
+		return r.Key + string(r.Operator) + r.Values[0]
+	case OperatorIn, OperatorNotIn:
+		return fmt.Sprintf("%s %s (%s)", r.Key, r.Operator, strings.Join(r.Values, ","))
+	case OperatorDoesNotExist:
+		return "!" + r.Key
+	default:
+		return r.Key
+	}
+}
+
+// Selector matches labels, which satisfy all its requirements
+type Selector []Requirement
+
+// Parse parses comma separated requirements:
+//
+//	env=prod,team!=infra,tier in (web,api),region notin (eu),canary,!legacy
+func Parse(s string) (Selector, error) {
+	var selector Selector
+	for _, term := range splitTerms(s) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			return nil, fmt.Errorf("empty requirement in selector '%s'", s)
+		}
+		requirement, err := parseRequirement(term)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector '%s': %v", s, err)
+		}
+		selector = append(selector, requirement)
+	}
+	return selector, nil
+}
+
+// splitTerms splits selector by commas, which are not inside parentheses
+func splitTerms(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+
+	var terms []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, s[start:])
+}
+
+func parseRequirement(term string) (Requirement, error) {
+	var requirement Requirement
+	switch {
+	case strings.HasPrefix(term, "!") && !strings.Contains(term, "="):
+		requirement = Requirement{Key: strings.TrimSpace(term[1:]), Operator: OperatorDoesNotExist}
+	case strings.Contains(term, "!="):
+		key, value, _ := strings.Cut(term, "!=")
+		requirement = Requirement{Key: strings.TrimSpace(key), Operator: OperatorNotEquals, Values: []string{strings.TrimSpace(value)}}
+	case strings.Contains(term, "="):
+		key, value, _ := strings.Cut(term, "=")
+		value = strings.TrimPrefix(value, "=")
+		requirement = Requirement{Key: strings.TrimSpace(key), Operator: OperatorEquals, Values: []string{strings.TrimSpace(value)}}
+	case setPattern.MatchString(term):
+		match := setPattern.FindStringSubmatch(term)
+		requirement = Requirement{Key: match[1], Operator: Operator(match[2])}
+		for _, value := range strings.Split(match[3], ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				requirement.Values = append(requirement.Values, value)
+			}
+		}
+		if len(requirement.Values) == 0 {
+			return Requirement{}, fmt.Errorf("'%s' requires at least one value", term)
+		}
+	default:
+		requirement = Requirement{Key: term, Operator: OperatorExists}
+	}
+
+	if !keyPattern.MatchString(requirement.Key) {
+		return Requirement{}, fmt.Errorf("invalid label name '%s'", requirement.Key)
+	}
+	return requirement, nil
+}
+
+// Empty reports whether the selector has no requirements. Empty selector
+// matches any labels.
+func (s Selector) Empty() bool {
+	return len(s) == 0
+}
+
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, requirement := range s {
+		if !requirement.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Selector) String() string {
+	terms := make([]string, 0, len(s))
+	for _, requirement := range s {
+		terms = append(terms, requirement.String())
+	}
+	return strings.Join(terms, ",")
+}
@@ -0,0 +1,56 @@
+package labels
+
+import "testing"
+
+func TestSelector_Matches(t *testing.T) {
+	labels := map[string]string{"env": "prod", "team": "payments", "tier": "api"}
+
+	cases := map[string]bool{
+		"env=prod":                   true,
+		"env==prod":                  true,
+		"env=stage":                  false,
+		"env=prod,team!=infra":       true,
+		"env=prod,team!=payments":    false,
+		"tier in (web, api)":         true,
+		"tier notin (web,api)":       false,
+		"region notin (eu)":          true,
+		"region in (eu)":             false,
+		"team":                       true,
+		"!legacy":                    true,
+		"!team":                      false,
+		"env=prod, tier in (api),!x": true,
+	}
+	for s, expected := range cases {
+		selector, err := Parse(s)
+		if err != nil {
+			t.Errorf("Expected %q to be parsed, got %v", s, err)
+			continue
+		}
+		if matches := selector.Matches(labels); matches != expected {
+			t.Errorf("Expected %q to match=%v", s, expected)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, s := range []string{"env=prod,", "tier in ()", "=prod", "env prod", "!"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Expected %q to be rejected", s)
+		}
+	}
+
+	selector, err := Parse("")
+	if err != nil || !selector.Empty() {
+		t.Errorf("Expected empty selector, got %v, %v", selector, err)
+	}
+}
+
+func TestSelector_String(t *testing.T) {
+	selector, err := Parse("env = prod,tier in (web, api),!legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := selector.String(); s != "env=prod,tier in (web,api),!legacy" {
+		t.Errorf("Unexpected selector string %q", s)
+	}
+}
@@ -0,0 +1,112 @@
+// Package logging provides slog handlers used by avalio
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// redacted replaces secrets in log output
+const redacted = "[REDACTED]"
+
+// Redactor replaces secret values in strings. Secrets may be replaced on
+// configuration reload.
+type Redactor struct {
+	mu       sync.RWMutex
+	replacer *strings.Replacer
+}
+
+func NewRedactor(secrets []string) *Redactor {
+	r := &Redactor{}
+	r.SetSecrets(secrets)
+	return r
+}
+
+// SetSecrets replaces secrets, empty ones are ignored
+func (r *Redactor) SetSecrets(secrets []string) {
+	var pairs []string
+	for _, secret := range secrets {
+		if secret != "" {
+			pairs = append(pairs, secret, redacted)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.replacer = nil
+	if len(pairs) > 0 {
+		r.replacer = strings.NewReplacer(pairs...)
+	}
+}
+
+// Redact returns s with secrets replaced
+func (r *Redactor) Redact(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.replacer == nil {
+		return s
+	}
+	return r.replacer.Replace(s)
+}
+
+// RedactingHandler removes secrets from messages and attributes of log
+// records before passing them to the next handler
+type RedactingHandler struct {
+	next     slog.Handler
+	redactor *Redactor
+}
+
+func NewRedactingHandler(next slog.Handler, redactor *Redactor) *RedactingHandler {
+	return &RedactingHandler{next: next, redactor: redactor}
+}
+
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *RedactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redactedRecord := slog.NewRecord(record.Time, record.Level, h.redactor.Redact(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redactedRecord.AddAttrs(h.redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redactedRecord)
+}
+
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redactedAttrs := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		redactedAttrs = append(redactedAttrs, h.redactAttr(attr))
+	}
+	return &RedactingHandler{next: h.next.WithAttrs(redactedAttrs), redactor: h.redactor}
+}
+
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{next: h.next.WithGroup(name), redactor: h.redactor}
+}
+
+// redactAttr redacts strings, groups and values formatted as strings,
+// e.g. errors. Numbers, times and other values are kept.
+func (h *RedactingHandler) redactAttr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, h.redactor.Redact(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redactedGroup := make([]slog.Attr, 0, len(group))
+		for _, groupAttr := range group {
+			redactedGroup = append(redactedGroup, h.redactAttr(groupAttr))
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redactedGroup...)}
+	case slog.KindAny:
+		formatted := fmt.Sprint(value.Any())
+		if redactedValue := h.redactor.Redact(formatted); redactedValue != formatted {
+			return slog.String(attr.Key, redactedValue)
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
@@ -0,0 +1,36 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedactingHandler(t *testing.T) {
+	var output bytes.Buffer
+	redactor := NewRedactor([]string{"123:secret", ""})
+	logger := slog.New(NewRedactingHandler(slog.NewTextHandler(&output, nil), redactor))
+
+	logger.With("token", "123:secret").Error(
+		"Request to /bot123:secret/sendMessage failed",
+		"error", errors.New("Post https://api.telegram.org/bot123:secret/sendMessage: timeout"),
+		slog.Group("request", "url", "https://api.telegram.org/bot123:secret"),
+		"attempt", 2,
+	)
+	if strings.Contains(output.String(), "secret") {
+		t.Errorf("Expected secret to be redacted, got %s", output.String())
+	}
+	if !strings.Contains(output.String(), "attempt=2") || strings.Count(output.String(), redacted) != 4 {
+		t.Errorf("Expected other values to be kept, got %s", output.String())
+	}
+
+	// secrets are replaced on reload
+	output.Reset()
+	redactor.SetSecrets([]string{"new-secret"})
+	logger.Info("123:secret new-secret")
+	if !strings.Contains(output.String(), "123:secret "+redacted) {
+		t.Errorf("Expected only new secret to be redacted, got %s", output.String())
+	}
+}
@@ -1,10 +1,18 @@
 package monitors
 
-import "log/slog"
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/andrewsapw/avalio/notificators"
+)
 
 // [[monitors.cron]]
 // name = 'every minute'
 // resources = ['moninotr1']
+// resource_selector = 'env=prod,team!=infra'
 // cron = '* * * * *'
 // retries = 3
 
@@ -12,6 +20,14 @@ type MonitorConfig struct {
 	Name         string   `toml:"name"`
 	Resources    []string `toml:"resources"`
 	Notificators []string `toml:"notificators"`
+	// ResourceSelector and NotificatorSelector add resources and
+	// notificators with matching labels to the named ones
+	ResourceSelector    string `toml:"resource_selector"`
+	NotificatorSelector string `toml:"notificator_selector"`
+	// Templates override notificators templates for this monitor results
+	Templates notificators.TemplatesConfig `toml:"templates"`
+	// Escalation is the name of escalation policy for this monitor outages
+	Escalation string `toml:"escalation"`
 }
 
 type CronMonitorConfig struct {
@@ -23,18 +39,71 @@ type MonitorsConfig struct {
 	Cron []CronMonitorConfig `toml:"cron"`
 }
 
+// Templates returns templates overrides of all monitors, keyed by monitor name
+func (c *MonitorsConfig) Templates() map[string]notificators.TemplatesConfig {
+	templates := make(map[string]notificators.TemplatesConfig)
+	for _, cronMonitorConfig := range c.Cron {
+		templates[cronMonitorConfig.Name] = cronMonitorConfig.Templates
+	}
+	return templates
+}
+
+// Escalations returns escalation policy names of monitors, which have one,
+// keyed by monitor name
+func (c *MonitorsConfig) Escalations() map[string]string {
+	escalations := make(map[string]string)
+	for _, cronMonitorConfig := range c.Cron {
+		if cronMonitorConfig.Escalation != "" {
+			escalations[cronMonitorConfig.Name] = cronMonitorConfig.Escalation
+		}
+	}
+	return escalations
+}
+
+// Names returns names of all monitors in config order, including
+// duplicates
+func (c *MonitorsConfig) Names() []string {
+	var names []string
+	for _, cronMonitorConfig := range c.Cron {
+		names = append(names, cronMonitorConfig.Name)
+	}
+	return names
+}
+
+// Configs returns configs of all monitors, keyed by monitor name
+func (c *MonitorsConfig) Configs() map[string]any {
+	configs := make(map[string]any)
+	for _, cronMonitorConfig := range c.Cron {
+		configs[cronMonitorConfig.Name] = cronMonitorConfig
+	}
+	return configs
+}
+
+// BuildMonitors creates monitors from config. Errors of all monitors are
+// returned together.
 func BuildMonitors(config *MonitorsConfig) ([]Monitor, error) {
 	var buildedMonitors []Monitor
+	var errs []error
+	var names []string
 	for _, cronMonitorConfig := range config.Cron {
+		if slices.Contains(names, cronMonitorConfig.Name) {
+			errs = append(errs, fmt.Errorf("[[monitors.cron]] %s - duplicate name", cronMonitorConfig.Name))
+		}
+		names = append(names, cronMonitorConfig.Name)
+
 		cronMonitor, err := NewCronMonitor(cronMonitorConfig)
 		if err != nil {
-			slog.Error("Error creating monitor", "error", err.Error())
-			return nil, err
+			errs = append(errs, err)
+			continue
 		}
-
-		slog.Info("Builded monitor", "monitor_name", cronMonitorConfig.Name)
 		buildedMonitors = append(buildedMonitors, cronMonitor)
 	}
 
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	for _, m := range buildedMonitors {
+		slog.Info("Builded monitor", "monitor_name", m.GetName())
+	}
 	return buildedMonitors, nil
 }
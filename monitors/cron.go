@@ -1,14 +1,18 @@
 package monitors
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/andrewsapw/avalio/labels"
 	"github.com/robfig/cron/v3"
 )
 
 type CronMonitor struct {
-	config   CronMonitorConfig
-	schedule cron.Schedule
+	config              CronMonitorConfig
+	schedule            cron.Schedule
+	resourceSelector    labels.Selector
+	notificatorSelector labels.Selector
 }
 
 // GetName implements Monitor.
@@ -26,17 +30,45 @@ func (c CronMonitor) GetResourcesNames() []string {
 	return c.config.Resources
 }
 
+// GetResourceSelector implements Monitor.
+func (c CronMonitor) GetResourceSelector() labels.Selector {
+	return c.resourceSelector
+}
+
+// GetNotificatorSelector implements Monitor.
+func (c CronMonitor) GetNotificatorSelector() labels.Selector {
+	return c.notificatorSelector
+}
+
 func (c CronMonitor) Next() time.Time {
 	now := time.Now()
 	return c.schedule.Next(now)
 }
 
 func NewCronMonitor(config CronMonitorConfig) (*CronMonitor, error) {
+	if err := config.Templates.Validate(); err != nil {
+		return nil, fmt.Errorf("[[monitors.cron]] %s - %v", config.Name, err)
+	}
+
+	resourceSelector, err := labels.Parse(config.ResourceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("[[monitors.cron]] %s - resource_selector: %v", config.Name, err)
+	}
+	notificatorSelector, err := labels.Parse(config.NotificatorSelector)
+	if err != nil {
+		return nil, fmt.Errorf("[[monitors.cron]] %s - notificator_selector: %v", config.Name, err)
+	}
+
 	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
 	schedule, err := parser.Parse(config.Cron)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("[[monitors.cron]] %s - cron: %v", config.Name, err)
 	}
-	return &CronMonitor{config: config, schedule: schedule}, nil
+	return &CronMonitor{
+		config:              config,
+		schedule:            schedule,
+		resourceSelector:    resourceSelector,
+		notificatorSelector: notificatorSelector,
+	}, nil
 
 }
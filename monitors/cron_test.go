@@ -0,0 +1,37 @@
+package monitors
+
+import "testing"
+
+func TestNewCronMonitor_Selectors(t *testing.T) {
+	monitor, err := NewCronMonitor(CronMonitorConfig{
+		MonitorConfig: MonitorConfig{Name: "prod", ResourceSelector: "env=prod,team!=infra"},
+		Cron:          "* * * * *",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !monitor.GetResourceSelector().Matches(map[string]string{"env": "prod"}) {
+		t.Error("Expected resource selector to match prod resources")
+	}
+	if !monitor.GetNotificatorSelector().Empty() {
+		t.Error("Expected notificator selector to be empty")
+	}
+
+	_, err = NewCronMonitor(CronMonitorConfig{
+		MonitorConfig: MonitorConfig{Name: "prod", ResourceSelector: "env in ()"},
+		Cron:          "* * * * *",
+	})
+	if err == nil {
+		t.Error("Expected invalid selector to be rejected")
+	}
+}
+
+func TestNewCronMonitor_InvalidCron(t *testing.T) {
+	monitor, err := NewCronMonitor(CronMonitorConfig{
+		MonitorConfig: MonitorConfig{Name: "prod"},
+		Cron:          "* * * *",
+	})
+	if err == nil || monitor != nil {
+		t.Errorf("Expected cron without day of week to be rejected, got %v", err)
+	}
+}
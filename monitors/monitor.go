@@ -2,12 +2,18 @@ package monitors
 
 import (
 	"time"
+
+	"github.com/andrewsapw/avalio/labels"
 )
 
 type Monitor interface {
 	GetName() string
 	GetResourcesNames() []string
 	GetNotificatorsNames() []string
+	// GetResourceSelector and GetNotificatorSelector return selectors, empty
+	// selector doesn't select anything
+	GetResourceSelector() labels.Selector
+	GetNotificatorSelector() labels.Selector
 	Next() time.Time
 }
 
@@ -3,6 +3,7 @@ package monitors
 import (
 	"context"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/andrewsapw/avalio/resources"
@@ -10,11 +11,15 @@ import (
 )
 
 type MonitorRunner struct {
-	monitor            Monitor
-	resource           resources.Resource
+	monitor  Monitor
+	resource resources.Resource
+	channels []chan status.CheckResult
+	ctx      context.Context
+
+	// mu guards outage state, which may be taken over by another runner
+	mu                 sync.Mutex
 	isLastMessageError bool
-	channels           []chan status.CheckResult
-	ctx                context.Context
+	downSince          time.Time
 }
 
 func NewMonitorRunner(
@@ -26,6 +31,19 @@ func NewMonitorRunner(
 	return &MonitorRunner{monitor: monitor, channels: channels, resource: resource, ctx: ctx, isLastMessageError: false}
 }
 
+// Resume continues outage of the previous runner of the same monitor and
+// resource, so the runner doesn't report it again. The previous runner must
+// be stopped.
+func (m *MonitorRunner) Resume(previous *MonitorRunner) {
+	previous.mu.Lock()
+	defer previous.mu.Unlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.isLastMessageError = previous.isLastMessageError
+	m.downSince = previous.downSince
+}
+
 func (m *MonitorRunner) Run() {
 	resourceName := m.resource.GetName()
 	slog.Info("Starting resource monitor", "monitor_name", m.monitor.GetName(),
@@ -42,7 +60,11 @@ func (m *MonitorRunner) Run() {
 		}
 
 		for _, c := range m.channels {
-			c <- checkResult
+			select {
+			case c <- checkResult:
+			case <-m.ctx.Done():
+				return
+			}
 		}
 
 		nextStepAt := m.monitor.Next()
@@ -52,7 +74,11 @@ func (m *MonitorRunner) Run() {
 			"next_run", nextStepAt,
 			"resource_name", resourceName)
 
-		time.Sleep(sleepTime)
+		select {
+		case <-time.After(sleepTime):
+		case <-m.ctx.Done():
+			return
+		}
 	}
 }
 
@@ -62,10 +88,16 @@ func (m *MonitorRunner) Step() status.CheckResult {
 
 	ok, details := m.resource.RunCheck()
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	var state status.ResourceState
+	downSince := m.downSince
 	if !ok {
 		if !m.isLastMessageError {
 			m.isLastMessageError = true
+			m.downSince = time.Now()
+			downSince = m.downSince
 			state = status.StateNotAvailable
 		} else {
 			state = status.StateStillNotAvailable
@@ -75,6 +107,7 @@ func (m *MonitorRunner) Step() status.CheckResult {
 
 		if m.isLastMessageError {
 			m.isLastMessageError = false
+			m.downSince = time.Time{}
 			state = status.StateRecovered
 		} else {
 			state = status.StateAvailable
@@ -87,5 +120,8 @@ func (m *MonitorRunner) Step() status.CheckResult {
 		details,
 		state,
 	)
+	checkResult.MonitorName = m.monitor.GetName()
+	checkResult.Labels = m.resource.GetLabels()
+	checkResult.DownSince = downSince
 	return checkResult
 }
@@ -21,6 +21,16 @@ func (m MockedResource) GetType() string {
 	return "mock"
 }
 
+// GetLabels implements resources.Resource.
+func (m MockedResource) GetLabels() map[string]string {
+	return nil
+}
+
+// GetDependencies implements resources.Resource.
+func (m MockedResource) GetDependencies() []string {
+	return nil
+}
+
 // RunCheck implements resources.Resource.
 func (m MockedResource) RunCheck() (bool, []status.CheckDetails) {
 	if *m.toFail {
@@ -46,12 +56,15 @@ func TestMonitorStates(t *testing.T) {
 	channels := [1]chan status.CheckResult{channel}
 
 	cronConfig := CronMonitorConfig{
-		Cron: "* * * *",
+		Cron: "* * * * *",
 	}
 
 	toFail := false
 	resource := MockedResource{toFail: &toFail}
-	monitor, _ := NewCronMonitor(cronConfig)
+	monitor, err := NewCronMonitor(cronConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	runner := NewMonitorRunner(
 		monitor,
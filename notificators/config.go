@@ -1,26 +1,78 @@
 package notificators
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
-	"os"
+	"net/url"
 	"slices"
+
+	"github.com/andrewsapw/avalio/status"
 )
 
 // [[notificator.console]]
 // name = 'console'
 type ConsoleNotificatorConfig struct {
-	Name string `toml:"name"`
+	Name   string            `toml:"name"`
+	Labels map[string]string `toml:"labels"`
+}
+
+func (c ConsoleNotificatorConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("[[notificator.console]] - name can't be empty")
+	}
+	return nil
 }
 
 // [[notificator.telegram]]
 // name = 'bot'
+// labels = { team = 'payments' }
 // chat_id = '...'
 // token = '...'
 type TelegramNotificatorConfig struct {
-	Name   string `toml:"name"`
-	ChatID string `toml:"chat_id"`
-	Token  string `toml:"token"`
+	Name            string            `toml:"name"`
+	Labels          map[string]string `toml:"labels"`
+	ChatID          string            `toml:"chat_id"`
+	MessageThreadID int               `toml:"message_thread_id"`
+	// Chats are used in addition to ChatID to send messages to several
+	// chats or forum topics
+	Chats []TelegramChatConfig `toml:"chats"`
+	Token string               `toml:"token"`
+	// TokenFile is read into Token on config load
+	TokenFile string          `toml:"token_file"`
+	APIURL    string          `toml:"api_url"`
+	ParseMode string          `toml:"parse_mode"`
+	Language  string          `toml:"language"`
+	Templates TemplatesConfig `toml:"templates"`
+	// SilentRecovery sends recovery messages without sound
+	SilentRecovery bool `toml:"silent_recovery"`
+	// EditOnRecovery replaces outage message with recovery message instead
+	// of sending a new one
+	EditOnRecovery bool `toml:"edit_on_recovery"`
+	// Interactive enables bot commands, they are accepted from configured
+	// chats and AllowedChatIDs
+	Interactive    bool     `toml:"interactive"`
+	AllowedChatIDs []string `toml:"allowed_chat_ids"`
+	// ReminderMinutes enables reminders about resources which are still
+	// not available, until the incident is acknowledged
+	ReminderMinutes int `toml:"reminder_minutes"`
+}
+
+// [[notificator.telegram.chats]]
+// chat_id = '...'
+// message_thread_id = 42
+type TelegramChatConfig struct {
+	ChatID          string `toml:"chat_id"`
+	MessageThreadID int    `toml:"message_thread_id"`
+}
+
+// allChats returns ChatID and Chats as a single list
+func (c TelegramNotificatorConfig) allChats() []TelegramChatConfig {
+	var chats []TelegramChatConfig
+	if c.ChatID != "" {
+		chats = append(chats, TelegramChatConfig{ChatID: c.ChatID, MessageThreadID: c.MessageThreadID})
+	}
+	return append(chats, c.Chats...)
 }
 
 func (c TelegramNotificatorConfig) Validate() error {
@@ -28,50 +80,414 @@ func (c TelegramNotificatorConfig) Validate() error {
 		return fmt.Errorf("[[notificator.telegram]] - name can't be empty")
 	}
 
-	if c.ChatID == "" {
+	if c.ChatID == "" && len(c.Chats) == 0 {
 		return fmt.Errorf("[[notificator.telegram]] - chat_id can't be empty")
 	}
 
+	for _, chat := range c.Chats {
+		if chat.ChatID == "" {
+			return fmt.Errorf("[[notificator.telegram.chats]] - chat_id can't be empty")
+		}
+	}
+
 	if c.Token == "" {
 		return fmt.Errorf("[[notificator.telegram]] - token can't be empty")
 	}
 
+	if c.APIURL != "" {
+		parsedUrl, err := url.Parse(c.APIURL)
+		if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+			return fmt.Errorf("[[notificator.telegram]] - api_url must be a valid http or https url")
+		}
+	}
+
+	switch c.ParseMode {
+	case "", "HTML", "MarkdownV2":
+	default:
+		return fmt.Errorf("[[notificator.telegram]] - parse_mode must be HTML or MarkdownV2")
+	}
+
+	if c.ReminderMinutes < 0 {
+		return fmt.Errorf("[[notificator.telegram]] - reminder_minutes must be non-negative")
+	}
+
+	if _, err := status.ParseLanguage(c.Language); err != nil {
+		return fmt.Errorf("[[notificator.telegram]] - %v", err)
+	}
+
+	if err := c.Templates.Validate(); err != nil {
+		return fmt.Errorf("[[notificator.telegram]] - %v", err)
+	}
+
+	return nil
+}
+
+// [[notificator.matrix]]
+// name = 'ops-room'
+// homeserver = 'https://matrix.example.com'
+// room_id = '!abcdef:example.com'
+// access_token = '...'
+type MatrixNotificatorConfig struct {
+	Name       string            `toml:"name"`
+	Labels     map[string]string `toml:"labels"`
+	Homeserver string            `toml:"homeserver"`
+	RoomID     string            `toml:"room_id"`
+	// AccessToken is used as is. If it is empty, User and Password are
+	// used to log in and the received token is cached in TokenCacheFile.
+	AccessToken string `toml:"access_token"`
+	User        string `toml:"user"`
+	Password    string `toml:"password"`
+	// AccessTokenFile and PasswordFile are read into AccessToken and
+	// Password on config load
+	AccessTokenFile string          `toml:"access_token_file"`
+	PasswordFile    string          `toml:"password_file"`
+	TokenCacheFile  string          `toml:"token_cache_file"`
+	WarnUnencrypted bool            `toml:"warn_unencrypted"`
+	Language        string          `toml:"language"`
+	Templates       TemplatesConfig `toml:"templates"`
+}
+
+func (c MatrixNotificatorConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("[[notificator.matrix]] - name can't be empty")
+	}
+
+	if c.Homeserver == "" {
+		return fmt.Errorf("[[notificator.matrix]] - homeserver can't be empty")
+	}
+
+	parsedUrl, err := url.Parse(c.Homeserver)
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+		return fmt.Errorf("[[notificator.matrix]] - homeserver must be a valid http or https url")
+	}
+
+	if c.RoomID == "" {
+		return fmt.Errorf("[[notificator.matrix]] - room_id can't be empty")
+	}
+
+	if c.AccessToken == "" && (c.User == "" || c.Password == "") {
+		return fmt.Errorf("[[notificator.matrix]] - either access_token or user and password must be set")
+	}
+
+	if _, err := status.ParseLanguage(c.Language); err != nil {
+		return fmt.Errorf("[[notificator.matrix]] - %v", err)
+	}
+
+	if err := c.Templates.Validate(); err != nil {
+		return fmt.Errorf("[[notificator.matrix]] - %v", err)
+	}
+
+	return nil
+}
+
+// [[notificator.exec]]
+// name = 'restart-service'
+// command = '/usr/local/bin/restart.sh'
+// args = ['--force']
+type ExecNotificatorConfig struct {
+	Name           string            `toml:"name"`
+	Labels         map[string]string `toml:"labels"`
+	Command        string            `toml:"command"`
+	Args           []string          `toml:"args"`
+	TimeoutSeconds int               `toml:"timeout_seconds"`
+	MaxConcurrency int               `toml:"max_concurrency"`
+	// States limits results passed to the command, e.g. ['not available'].
+	// All results are passed if it is empty.
+	States []string `toml:"states"`
+}
+
+func (c ExecNotificatorConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("[[notificator.exec]] - name can't be empty")
+	}
+
+	if c.Command == "" {
+		return fmt.Errorf("[[notificator.exec]] - command can't be empty")
+	}
+
+	if c.TimeoutSeconds < 0 || c.TimeoutSeconds > 3600 {
+		return fmt.Errorf("[[notificator.exec]] - timeout_seconds must be between 0 and 3600")
+	}
+
+	if c.MaxConcurrency < 0 {
+		return fmt.Errorf("[[notificator.exec]] - max_concurrency must be non-negative")
+	}
+
+	if _, err := parseStates(c.States); err != nil {
+		return fmt.Errorf("[[notificator.exec]] - %v", err)
+	}
+
+	return nil
+}
+
+// [[notificator.file]]
+// name = 'audit'
+// path = '/var/log/avalio/audit.jsonl'
+type FileNotificatorConfig struct {
+	Name       string            `toml:"name"`
+	Labels     map[string]string `toml:"labels"`
+	Path       string            `toml:"path"`
+	MaxSizeMB  int               `toml:"max_size_mb"`
+	MaxBackups int               `toml:"max_backups"`
+	States     []string          `toml:"states"`
+}
+
+func (c FileNotificatorConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("[[notificator.file]] - name can't be empty")
+	}
+
+	if c.Path == "" {
+		return fmt.Errorf("[[notificator.file]] - path can't be empty")
+	}
+
+	if c.MaxSizeMB < 0 {
+		return fmt.Errorf("[[notificator.file]] - max_size_mb must be non-negative")
+	}
+
+	if c.MaxBackups < 0 {
+		return fmt.Errorf("[[notificator.file]] - max_backups must be non-negative")
+	}
+
+	if _, err := parseStates(c.States); err != nil {
+		return fmt.Errorf("[[notificator.file]] - %v", err)
+	}
+
+	return nil
+}
+
+// [[notificator.syslog]]
+// name = 'syslog'
+// network = 'udp'
+// address = 'localhost:514'
+type SyslogNotificatorConfig struct {
+	Name   string            `toml:"name"`
+	Labels map[string]string `toml:"labels"`
+	// Network is one of udp, tcp or unix. Local syslog socket is used if
+	// both Network and Address are empty.
+	Network   string          `toml:"network"`
+	Address   string          `toml:"address"`
+	Facility  string          `toml:"facility"`
+	AppName   string          `toml:"app_name"`
+	Hostname  string          `toml:"hostname"`
+	States    []string        `toml:"states"`
+	Language  string          `toml:"language"`
+	Templates TemplatesConfig `toml:"templates"`
+}
+
+func (c SyslogNotificatorConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("[[notificator.syslog]] - name can't be empty")
+	}
+
+	switch c.Network {
+	case "", "udp", "tcp", "unix":
+	default:
+		return fmt.Errorf("[[notificator.syslog]] - network must be one of udp, tcp or unix")
+	}
+
+	if c.Network != "" && c.Address == "" {
+		return fmt.Errorf("[[notificator.syslog]] - address can't be empty when network is set")
+	}
+
+	if c.Facility != "" {
+		if _, ok := syslogFacilities[c.Facility]; !ok {
+			return fmt.Errorf("[[notificator.syslog]] - unknown facility '%s'", c.Facility)
+		}
+	}
+
+	if _, err := parseStates(c.States); err != nil {
+		return fmt.Errorf("[[notificator.syslog]] - %v", err)
+	}
+
+	if _, err := status.ParseLanguage(c.Language); err != nil {
+		return fmt.Errorf("[[notificator.syslog]] - %v", err)
+	}
+
+	if err := c.Templates.Validate(); err != nil {
+		return fmt.Errorf("[[notificator.syslog]] - %v", err)
+	}
+
 	return nil
 }
 
+// parseStates converts list of state names from config to ResourceState values
+func parseStates(names []string) ([]status.ResourceState, error) {
+	var states []status.ResourceState
+	for _, name := range names {
+		state, err := status.ParseResourceState(name)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
 type NotificatorsConfig struct {
+	Templates TemplatesConfig `toml:"templates"`
+
 	Console  []ConsoleNotificatorConfig  `toml:"console"`
 	Telegram []TelegramNotificatorConfig `toml:"telegram"`
+	Matrix   []MatrixNotificatorConfig   `toml:"matrix"`
+	Exec     []ExecNotificatorConfig     `toml:"exec"`
+	File     []FileNotificatorConfig     `toml:"file"`
+	Syslog   []SyslogNotificatorConfig   `toml:"syslog"`
+}
+
+// Labels returns labels of all notificators, keyed by notificator name
+func (c *NotificatorsConfig) Labels() map[string]map[string]string {
+	nameToLabels := make(map[string]map[string]string)
+	for _, n := range c.Console {
+		nameToLabels[n.Name] = n.Labels
+	}
+	for _, n := range c.Telegram {
+		nameToLabels[n.Name] = n.Labels
+	}
+	for _, n := range c.Matrix {
+		nameToLabels[n.Name] = n.Labels
+	}
+	for _, n := range c.Exec {
+		nameToLabels[n.Name] = n.Labels
+	}
+	for _, n := range c.File {
+		nameToLabels[n.Name] = n.Labels
+	}
+	for _, n := range c.Syslog {
+		nameToLabels[n.Name] = n.Labels
+	}
+	return nameToLabels
 }
 
-func BuildNotificators(config *NotificatorsConfig) ([]Notificator, error) {
+// Names returns names of all notificators in config order, including
+// duplicates
+func (c *NotificatorsConfig) Names() []string {
+	var names []string
+	for _, n := range c.Console {
+		names = append(names, n.Name)
+	}
+	for _, n := range c.Telegram {
+		names = append(names, n.Name)
+	}
+	for _, n := range c.Matrix {
+		names = append(names, n.Name)
+	}
+	for _, n := range c.Exec {
+		names = append(names, n.Name)
+	}
+	for _, n := range c.File {
+		names = append(names, n.Name)
+	}
+	for _, n := range c.Syslog {
+		names = append(names, n.Name)
+	}
+	return names
+}
+
+// Configs returns configs of all notificators, keyed by notificator name
+func (c *NotificatorsConfig) Configs() map[string]any {
+	configs := make(map[string]any)
+	for _, n := range c.Console {
+		configs[n.Name] = n
+	}
+	for _, n := range c.Telegram {
+		configs[n.Name] = n
+	}
+	for _, n := range c.Matrix {
+		configs[n.Name] = n
+	}
+	for _, n := range c.Exec {
+		configs[n.Name] = n
+	}
+	for _, n := range c.File {
+		configs[n.Name] = n
+	}
+	for _, n := range c.Syslog {
+		configs[n.Name] = n
+	}
+	return configs
+}
+
+// BuildNotificators creates notificators from config. monitorTemplates
+// holds per monitor templates overrides, keyed by monitor name, language
+// is used by notificators without their own language setting. Errors of all
+// notificators are returned together.
+func BuildNotificators(
+	config *NotificatorsConfig,
+	monitorTemplates map[string]TemplatesConfig,
+	language status.Language,
+) ([]Notificator, error) {
 	var buildedNotificators []Notificator
+	var errs []error
 	notificatorsNames := []string{}
 
-	for _, consoleNotificatorConfig := range config.Console {
-		consoleNotificator := NewConsoleNotificator(consoleNotificatorConfig)
-		if slices.Contains(notificatorsNames, consoleNotificator.GetName()) {
-			return nil, fmt.Errorf("Duplicated notificators names: %s", consoleNotificator.GetName())
-		}
+	if err := config.Templates.Validate(); err != nil {
+		return nil, fmt.Errorf("[notificators.templates] - %v", err)
+	}
+	templates := Templates{Global: config.Templates, Monitors: monitorTemplates, Language: language}
 
-		slog.Info("Builded notificator", "notificatorName", consoleNotificator.GetName())
-		buildedNotificators = append(buildedNotificators, consoleNotificator)
+	add := func(notificator Notificator) {
+		if slices.Contains(notificatorsNames, notificator.GetName()) {
+			errs = append(errs, fmt.Errorf("Duplicated notificators names: %s", notificator.GetName()))
+			return
+		}
+		buildedNotificators = append(buildedNotificators, notificator)
+		notificatorsNames = append(notificatorsNames, notificator.GetName())
+	}
 
-		notificatorsNames = append(notificatorsNames, consoleNotificator.GetName())
+	for _, consoleNotificatorConfig := range config.Console {
+		if err := consoleNotificatorConfig.Validate(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		add(NewConsoleNotificator(consoleNotificatorConfig))
 	}
 
 	for _, telegramNotificatorConfig := range config.Telegram {
 		if err := telegramNotificatorConfig.Validate(); err != nil {
-			return nil, err
+			errs = append(errs, err)
+			continue
+		}
+		add(NewTelegramNotificator(telegramNotificatorConfig, templates))
+	}
+
+	for _, matrixNotificatorConfig := range config.Matrix {
+		if err := matrixNotificatorConfig.Validate(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		add(NewMatrixNotificator(matrixNotificatorConfig, templates))
+	}
+
+	for _, execNotificatorConfig := range config.Exec {
+		if err := execNotificatorConfig.Validate(); err != nil {
+			errs = append(errs, err)
+			continue
 		}
-		telegramNotificator := NewTelegramNotificator(telegramNotificatorConfig)
-		if slices.Contains(notificatorsNames, telegramNotificator.GetName()) {
-			slog.Error("Duplicated notificators names", "duplicated_names", telegramNotificator.GetName())
-			os.Exit(1)
+		add(NewExecNotificator(execNotificatorConfig))
+	}
+
+	for _, fileNotificatorConfig := range config.File {
+		if err := fileNotificatorConfig.Validate(); err != nil {
+			errs = append(errs, err)
+			continue
 		}
-		slog.Info("Builded notificator", "notificator_name", telegramNotificator.GetName())
-		buildedNotificators = append(buildedNotificators, telegramNotificator)
+		add(NewFileNotificator(fileNotificatorConfig))
 	}
 
+	for _, syslogNotificatorConfig := range config.Syslog {
+		if err := syslogNotificatorConfig.Validate(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		add(NewSyslogNotificator(syslogNotificatorConfig, templates))
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	for _, notificator := range buildedNotificators {
+		slog.Info("Builded notificator", "notificator_name", notificator.GetName())
+	}
 	return buildedNotificators, nil
 }
@@ -0,0 +1,33 @@
+package notificators
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+func TestBuildNotificators_Errors(t *testing.T) {
+	config := &NotificatorsConfig{
+		Console: []ConsoleNotificatorConfig{{Name: "bot"}},
+		Telegram: []TelegramNotificatorConfig{
+			{Name: "bot", ChatID: "1", Token: "123:abc"},
+			{Name: "oncall", ChatID: "2"},
+		},
+		Exec: []ExecNotificatorConfig{{Name: "restart"}},
+	}
+
+	notificators, err := BuildNotificators(config, nil, status.LanguageEnglish)
+	if err == nil || notificators != nil {
+		t.Fatal("Expected invalid notificators to be rejected")
+	}
+	for _, expected := range []string{
+		"Duplicated notificators names: bot",
+		"[[notificator.telegram]] - token can't be empty",
+		"[[notificator.exec]] - command can't be empty",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error %q in %q", expected, err)
+		}
+	}
+}
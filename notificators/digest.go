@@ -0,0 +1,49 @@
+package notificators
+
+import (
+	"github.com/andrewsapw/avalio/status"
+)
+
+// Digest is a group of outages and recoveries sent as a single message
+type Digest struct {
+	// Group holds values of group_by keys, which are shared by all results
+	Group   map[string]string    `json:"group,omitempty"`
+	Results []status.CheckResult `json:"results"`
+}
+
+// DigestSender is implemented by notificators, which can send grouped
+// results as a single message. Other notificators receive grouped results
+// one by one.
+type DigestSender interface {
+	SendDigest(digest Digest) error
+}
+
+// defaultDigestTemplate lists resources by state, showing the first check
+// detail of unavailable resources and the downtime of recovered ones
+const defaultDigestTemplate = "{{ with .NotAvailable }}❌ {{ t \"digest.not_available\" (len .) }}\n" +
+	"{{ range . }}• {{ code .ResourceName }}{{ with .Details }} - {{ escape (index . 0).Description }}{{ end }}\n{{ end }}\n{{ end }}" +
+	"{{ with .Recovered }}✅ {{ t \"digest.recovered\" (len .) }}\n" +
+	"{{ range . }}• {{ code .ResourceName }} ({{ duration .Downtime }})\n{{ end }}{{ end }}"
+
+// DigestData is passed to the digest template on render
+type DigestData struct {
+	Group        map[string]string
+	Results      []TemplateData
+	NotAvailable []TemplateData
+	Recovered    []TemplateData
+}
+
+func newDigestData(language status.Language, digest Digest) DigestData {
+	data := DigestData{Group: digest.Group}
+	for _, checkResult := range digest.Results {
+		result := newTemplateData(language, checkResult)
+		data.Results = append(data.Results, result)
+		switch checkResult.State {
+		case status.StateNotAvailable:
+			data.NotAvailable = append(data.NotAvailable, result)
+		case status.StateRecovered:
+			data.Recovered = append(data.Recovered, result)
+		}
+	}
+	return data
+}
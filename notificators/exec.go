@@ -0,0 +1,147 @@
+package notificators
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+const (
+	execDefaultTimeout     = 30 * time.Second
+	execDefaultConcurrency = 1
+	// execMaxStderrSize limits amount of stderr output written to the log
+	execMaxStderrSize = 4096
+)
+
+// ExecNotificator runs configured command for each check result. The result
+// is passed as JSON on stdin and as AVALIO_* environment variables.
+type ExecNotificator struct {
+	config ExecNotificatorConfig
+	states []status.ResourceState
+	slots  chan struct{}
+}
+
+// Send implements Notificator. It waits for the command to finish, so its
+// failure is retried by the delivery queue. Send blocks while
+// max_concurrency commands are already running.
+func (e ExecNotificator) Send(checkResult status.CheckResult) error {
+	if len(e.states) > 0 && !slices.Contains(e.states, checkResult.State) {
+		return nil
+	}
+
+	payload, err := json.Marshal(checkResult)
+	if err != nil {
+		return fmt.Errorf("failed to marshal check result: %v", err)
+	}
+
+	e.slots <- struct{}{}
+	defer func() { <-e.slots }()
+	return e.run(checkResult, payload)
+}
+
+// run runs the command and logs its failure, which is also returned
+func (e ExecNotificator) run(checkResult status.CheckResult, payload []byte) error {
+	timeout := time.Duration(e.config.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = execDefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.config.Command, e.config.Args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), e.environment(checkResult)...)
+
+	slog.Debug(
+		"exec notificator command",
+		"notificator_name", e.GetName(),
+		"cmd", e.config.Command,
+		"args", e.config.Args,
+	)
+
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	output := strings.TrimSpace(stderr.String())
+	if len(output) > execMaxStderrSize {
+		output = output[:execMaxStderrSize] + "..."
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		slog.Error(
+			"Notification command timed out",
+			"notificator_name", e.GetName(),
+			"resource_name", checkResult.ResourceName,
+			"timeout", timeout,
+			"stderr", output,
+		)
+		return fmt.Errorf("command timed out after %s: %s", timeout, output)
+	case errors.As(err, &exitErr):
+		slog.Error(
+			"Notification command failed",
+			"notificator_name", e.GetName(),
+			"resource_name", checkResult.ResourceName,
+			"exit_code", exitErr.ExitCode(),
+			"stderr", output,
+		)
+		return fmt.Errorf("command exited with code %d: %s", exitErr.ExitCode(), output)
+	default:
+		slog.Error(
+			"Can't run notification command",
+			"notificator_name", e.GetName(),
+			"resource_name", checkResult.ResourceName,
+			"error", err,
+		)
+		return fmt.Errorf("can't run command: %v", err)
+	}
+}
+
+func (e ExecNotificator) environment(checkResult status.CheckResult) []string {
+	return []string{
+		"AVALIO_NOTIFICATOR=" + e.GetName(),
+		"AVALIO_RESOURCE_NAME=" + checkResult.ResourceName,
+		"AVALIO_RESOURCE_TYPE=" + checkResult.ResourceType,
+		"AVALIO_STATE=" + checkResult.State.String(),
+		"AVALIO_STATE_CODE=" + strconv.Itoa(int(checkResult.State)),
+		"AVALIO_DETAILS=" + checkResult.ErrorsAsString(),
+	}
+}
+
+// GetName implements Notificator.
+func (e ExecNotificator) GetName() string {
+	return e.config.Name
+}
+
+func NewExecNotificator(config ExecNotificatorConfig) ExecNotificator {
+	concurrency := config.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = execDefaultConcurrency
+	}
+
+	// states are checked in Validate
+	states, _ := parseStates(config.States)
+
+	return ExecNotificator{
+		config: config,
+		states: states,
+		slots:  make(chan struct{}, concurrency),
+	}
+}
@@ -0,0 +1,80 @@
+package notificators
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+func TestExecNotificator_PassesResult(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	dir := t.TempDir()
+	notificator := NewExecNotificator(ExecNotificatorConfig{
+		Name:    "hook",
+		Command: "sh",
+		Args: []string{
+			"-c",
+			`cat > "$0/stdin.json"; echo "$AVALIO_RESOURCE_NAME $AVALIO_STATE_CODE" > "$0/env"`,
+			dir,
+		},
+		States: []string{"not available"},
+	})
+
+	details := []status.CheckDetails{status.NewCheckError("Reason", "timeout")}
+	if err := notificator.Send(status.NewCheckResult("api", "http", details, status.StateAvailable)); err != nil {
+		t.Fatalf("Expected Send() to succeed, got %v", err)
+	}
+	if err := notificator.Send(status.NewCheckResult("api", "http", details, status.StateNotAvailable)); err != nil {
+		t.Fatalf("Expected Send() to succeed, got %v", err)
+	}
+
+	env, err := os.ReadFile(filepath.Join(dir, "env"))
+	if err != nil {
+		t.Fatalf("Expected command to run, got %v", err)
+	}
+	if strings.TrimSpace(string(env)) != "api 1" {
+		t.Errorf("Expected environment 'api 1', got '%s'", env)
+	}
+
+	stdin, err := os.ReadFile(filepath.Join(dir, "stdin.json"))
+	if err != nil {
+		t.Fatalf("Expected command to read stdin, got %v", err)
+	}
+	var payload struct {
+		ResourceName string `json:"resource_name"`
+		State        string `json:"state"`
+		Details      []struct {
+			Title string `json:"title"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(stdin, &payload); err != nil {
+		t.Fatalf("Expected JSON on stdin, got %v", err)
+	}
+	if payload.State != "not available" || len(payload.Details) != 1 || payload.Details[0].Title != "Reason" {
+		t.Errorf("Unexpected payload: %s", stdin)
+	}
+}
+
+func TestExecNotificator_ReportsFailure(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	notificator := NewExecNotificator(ExecNotificatorConfig{
+		Name:    "hook",
+		Command: "sh",
+		Args:    []string{"-c", "echo 'webhook is down' >&2; exit 2"},
+	})
+	err := notificator.Send(status.NewCheckResult("api", "http", nil, status.StateNotAvailable))
+	if err == nil || !strings.Contains(err.Error(), "exited with code 2: webhook is down") {
+		t.Errorf("Expected command failure, got %v", err)
+	}
+}
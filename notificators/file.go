@@ -0,0 +1,138 @@
+package notificators
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+const (
+	fileDefaultMaxSizeMB  = 10
+	fileDefaultMaxBackups = 5
+)
+
+// FileNotificator writes one JSON object per check result to a file.
+// When the file grows larger than max_size_mb it is rotated: path is
+// renamed to path.1, path.1 to path.2 and so on up to max_backups.
+type FileNotificator struct {
+	config  FileNotificatorConfig
+	states  []status.ResourceState
+	maxSize int64
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Send implements Notificator.
+func (f *FileNotificator) Send(checkResult status.CheckResult) error {
+	if len(f.states) > 0 && !slices.Contains(f.states, checkResult.State) {
+		return nil
+	}
+
+	line, err := json.Marshal(checkResult)
+	if err != nil {
+		return fmt.Errorf("failed to marshal check result: %v", err)
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.rotateIfNeeded(int64(len(line))); err != nil {
+		return err
+	}
+
+	file, err := f.open()
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("failed to write to %s: %v", f.config.Path, err)
+	}
+
+	return file.Sync()
+}
+
+func (f *FileNotificator) open() (*os.File, error) {
+	if f.file != nil {
+		return f.file, nil
+	}
+
+	file, err := os.OpenFile(f.config.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", f.config.Path, err)
+	}
+	f.file = file
+	return file, nil
+}
+
+func (f *FileNotificator) rotateIfNeeded(writeSize int64) error {
+	info, err := os.Stat(f.config.Path)
+	if err != nil || info.Size() == 0 || info.Size()+writeSize <= f.maxSize {
+		return nil
+	}
+
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+
+	for i := f.backups() - 1; i >= 1; i-- {
+		os.Rename(f.backupPath(i), f.backupPath(i+1))
+	}
+
+	if err := os.Rename(f.config.Path, f.backupPath(1)); err != nil {
+		return fmt.Errorf("failed to rotate %s: %v", f.config.Path, err)
+	}
+	return nil
+}
+
+func (f *FileNotificator) backups() int {
+	if f.config.MaxBackups > 0 {
+		return f.config.MaxBackups
+	}
+	return fileDefaultMaxBackups
+}
+
+func (f *FileNotificator) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", f.config.Path, i)
+}
+
+// Close implements io.Closer. The file is opened again by the next Send.
+func (f *FileNotificator) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// GetName implements Notificator.
+func (f *FileNotificator) GetName() string {
+	return f.config.Name
+}
+
+func NewFileNotificator(config FileNotificatorConfig) *FileNotificator {
+	maxSizeMB := config.MaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = fileDefaultMaxSizeMB
+	}
+
+	// states are checked in Validate
+	states, _ := parseStates(config.States)
+
+	return &FileNotificator{
+		config:  config,
+		states:  states,
+		maxSize: int64(maxSizeMB) * 1024 * 1024,
+	}
+}
@@ -0,0 +1,87 @@
+package notificators
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+func TestFileNotificator_WritesJSONLinesAndRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	notificator := NewFileNotificator(FileNotificatorConfig{
+		Name:       "audit",
+		Path:       path,
+		MaxBackups: 2,
+	})
+	notificator.maxSize = 300
+
+	states := []status.ResourceState{
+		status.StateNotAvailable,
+		status.StateStillNotAvailable,
+		status.StateRecovered,
+		status.StateAvailable,
+	}
+	for _, state := range states {
+		if err := notificator.Send(status.NewCheckResult("api", "http", nil, state)); err != nil {
+			t.Fatalf("Expected Send() to succeed, got %v", err)
+		}
+	}
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Fatalf("Expected file to be rotated, got %v", err)
+	}
+	if _, err := os.Stat(path + ".3"); err == nil {
+		t.Errorf("Expected at most 2 backups")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	var lines int
+	for scanner.Scan() {
+		var record map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Expected JSON line, got '%s'", scanner.Text())
+		}
+		if record["resource_name"] != "api" || record["checked_at"] == nil {
+			t.Errorf("Unexpected record: %s", scanner.Text())
+		}
+		lines++
+	}
+	if lines == 0 {
+		t.Error("Expected current file to contain records")
+	}
+}
+
+func TestFileNotificator_Close(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	notificator := NewFileNotificator(FileNotificatorConfig{Name: "audit", Path: path})
+
+	if err := notificator.Send(status.NewCheckResult("api", "http", nil, status.StateNotAvailable)); err != nil {
+		t.Fatalf("Expected Send() to succeed, got %v", err)
+	}
+	if err := notificator.Close(); err != nil || notificator.file != nil {
+		t.Fatalf("Expected file to be closed, got %v", err)
+	}
+
+	// the file is reopened by the next send
+	if err := notificator.Send(status.NewCheckResult("api", "http", nil, status.StateRecovered)); err != nil {
+		t.Fatalf("Expected Send() after Close() to succeed, got %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := len(strings.Split(strings.TrimSpace(string(data)), "\n")); lines != 2 {
+		t.Errorf("Expected 2 lines, got %d", lines)
+	}
+}
@@ -0,0 +1,284 @@
+package notificators
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+// errMatrixUnauthorized is returned when homeserver rejects access token
+var errMatrixUnauthorized = errors.New("matrix access token was rejected")
+
+type MatrixNotificator struct {
+	config        MatrixNotificatorConfig
+	client        http.Client
+	plainRenderer *Renderer
+	htmlRenderer  *Renderer
+
+	mu                sync.Mutex
+	accessToken       string
+	encryptionChecked bool
+}
+
+// MatrixResponse represents the structure of Matrix client-server API response
+type MatrixResponse struct {
+	EventID     string `json:"event_id"`
+	AccessToken string `json:"access_token"`
+	ErrCode     string `json:"errcode"`
+	Error       string `json:"error"`
+}
+
+// matrixError describes failed request to the homeserver
+type matrixError struct {
+	statusCode int
+	errCode    string
+	message    string
+}
+
+func (e matrixError) Error() string {
+	return fmt.Sprintf("matrix API error: %d %s: %s", e.statusCode, e.errCode, e.message)
+}
+
+// Send implements Notificator.
+func (m *MatrixNotificator) Send(checkResult status.CheckResult) error {
+	plain, ok, err := m.plainRenderer.Render(checkResult)
+	if err != nil || !ok {
+		return err
+	}
+
+	formatted, _, err := m.htmlRenderer.Render(checkResult)
+	if err != nil {
+		return err
+	}
+
+	return m.sendMessage(transactionID(plain, checkResult), plain, strings.ReplaceAll(formatted, "\n", "<br>"))
+}
+
+// SendDigest implements DigestSender.
+func (m *MatrixNotificator) SendDigest(digest Digest) error {
+	plain, err := m.plainRenderer.RenderDigest(digest)
+	if err != nil {
+		return err
+	}
+
+	formatted, err := m.htmlRenderer.RenderDigest(digest)
+	if err != nil {
+		return err
+	}
+
+	return m.sendMessage(transactionID(plain, digest.Results...), plain, strings.ReplaceAll(formatted, "\n", "<br>"))
+}
+
+// sendMessage sends m.room.message event. Failed sends are retried by the
+// delivery queue with the same transaction ID, so the homeserver
+// deduplicates them.
+func (m *MatrixNotificator) sendMessage(txnID, plain, formatted string) error {
+	m.warnIfEncrypted()
+
+	content := map[string]any{
+		"msgtype":        "m.text",
+		"body":           plain,
+		"format":         "org.matrix.custom.html",
+		"formatted_body": formatted,
+	}
+
+	err := m.sendEvent(txnID, content)
+	// cached token is expired, login again on the next attempt. Static
+	// access token can't be renewed.
+	if errors.Is(err, errMatrixUnauthorized) && m.config.AccessToken == "" {
+		m.resetToken()
+	}
+	return err
+}
+
+func (m *MatrixNotificator) sendEvent(txnID string, content map[string]any) error {
+	token, err := m.token()
+	if err != nil {
+		return err
+	}
+
+	apiURL := fmt.Sprintf(
+		"%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimRight(m.config.Homeserver, "/"),
+		url.PathEscape(m.config.RoomID),
+		url.PathEscape(txnID),
+	)
+
+	_, err = m.do(http.MethodPut, apiURL, token, content)
+	return err
+}
+
+// token returns access token, logging in with password if needed
+func (m *MatrixNotificator) token() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.accessToken != "" {
+		return m.accessToken, nil
+	}
+
+	if m.config.TokenCacheFile != "" {
+		if cached, err := os.ReadFile(m.config.TokenCacheFile); err == nil {
+			if token := strings.TrimSpace(string(cached)); token != "" {
+				m.accessToken = token
+				return token, nil
+			}
+		}
+	}
+
+	apiURL := fmt.Sprintf("%s/_matrix/client/v3/login", strings.TrimRight(m.config.Homeserver, "/"))
+	resp, err := m.do(http.MethodPost, apiURL, "", map[string]any{
+		"type": "m.login.password",
+		"identifier": map[string]string{
+			"type": "m.id.user",
+			"user": m.config.User,
+		},
+		"password":                    m.config.Password,
+		"initial_device_display_name": "avalio",
+	})
+	if err != nil {
+		return "", fmt.Errorf("matrix login failed: %w", err)
+	}
+	if resp.AccessToken == "" {
+		return "", fmt.Errorf("matrix login failed: empty access token")
+	}
+
+	m.accessToken = resp.AccessToken
+	if m.config.TokenCacheFile != "" {
+		if err := os.WriteFile(m.config.TokenCacheFile, []byte(resp.AccessToken), 0o600); err != nil {
+			slog.Warn("Can't cache matrix access token", "notificator_name", m.GetName(), "error", err)
+		}
+	}
+
+	return m.accessToken, nil
+}
+
+func (m *MatrixNotificator) resetToken() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.accessToken = m.config.AccessToken
+	if m.config.TokenCacheFile != "" {
+		os.Remove(m.config.TokenCacheFile)
+	}
+}
+
+// warnIfEncrypted logs a warning once if the room has end-to-end encryption
+// enabled, because avalio sends messages unencrypted.
+func (m *MatrixNotificator) warnIfEncrypted() {
+	if !m.config.WarnUnencrypted {
+		return
+	}
+
+	m.mu.Lock()
+	checked := m.encryptionChecked
+	m.encryptionChecked = true
+	m.mu.Unlock()
+	if checked {
+		return
+	}
+
+	token, err := m.token()
+	if err != nil {
+		return
+	}
+
+	apiURL := fmt.Sprintf(
+		"%s/_matrix/client/v3/rooms/%s/state/m.room.encryption/",
+		strings.TrimRight(m.config.Homeserver, "/"),
+		url.PathEscape(m.config.RoomID),
+	)
+	if _, err := m.do(http.MethodGet, apiURL, token, nil); err == nil {
+		slog.Warn(
+			"Matrix room is end-to-end encrypted, notifications will be sent unencrypted",
+			"notificator_name", m.GetName(),
+			"room_id", m.config.RoomID,
+		)
+	}
+}
+
+func (m *MatrixNotificator) do(method, apiURL, token string, body any) (MatrixResponse, error) {
+	var matrixResp MatrixResponse
+
+	var reqBody io.Reader
+	if body != nil {
+		requestBody, err := json.Marshal(body)
+		if err != nil {
+			return matrixResp, fmt.Errorf("failed to marshal request body: %v", err)
+		}
+		reqBody = bytes.NewBuffer(requestBody)
+	}
+
+	req, err := http.NewRequest(method, apiURL, reqBody)
+	if err != nil {
+		return matrixResp, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return matrixResp, fmt.Errorf("HTTP request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&matrixResp); err != nil && err != io.EOF {
+		return matrixResp, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return matrixResp, fmt.Errorf("%w: %s", errMatrixUnauthorized, matrixResp.Error)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return matrixResp, matrixError{
+			statusCode: resp.StatusCode,
+			errCode:    matrixResp.ErrCode,
+			message:    matrixResp.Error,
+		}
+	}
+
+	return matrixResp, nil
+}
+
+// GetName implements Notificator.
+func (m *MatrixNotificator) GetName() string {
+	return m.config.Name
+}
+
+// transactionID derives transaction ID from the message and its results,
+// so it is the same for every attempt to send them
+func transactionID(plain string, results ...status.CheckResult) string {
+	h := sha256.New()
+	h.Write([]byte(plain))
+	for _, checkResult := range results {
+		fmt.Fprintf(h, "\x00%s\x00%s\x00%d", checkResult.MonitorName, checkResult.ResourceName, checkResult.CheckedAt.UnixNano())
+	}
+	return "avalio-" + hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+func NewMatrixNotificator(config MatrixNotificatorConfig, templates Templates) *MatrixNotificator {
+	language := templates.language(config.Language)
+	return &MatrixNotificator{
+		config:        config,
+		client:        http.Client{Timeout: 10 * time.Second},
+		plainRenderer: newRenderer(formatPlain, language, defaultTemplates, templates, config.Templates),
+		htmlRenderer:  newRenderer(formatHTML, language, defaultTemplates, templates, config.Templates),
+		accessToken:   config.AccessToken,
+	}
+}
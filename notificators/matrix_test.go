@@ -0,0 +1,161 @@
+package notificators
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+type matrixStandIn struct {
+	mu        sync.Mutex
+	failSends int
+	logins    int
+	// rejected token is answered with 401, like an expired one
+	rejected     string
+	unauthorized int
+	txnIDs       []string
+	bodies       []map[string]string
+}
+
+func (s *matrixStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/_matrix/client/v3/login":
+		s.logins++
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "login-token"})
+	case strings.Contains(r.URL.Path, "/send/m.room.message/"):
+		if auth := r.Header.Get("Authorization"); auth == "" || auth == "Bearer "+s.rejected {
+			s.unauthorized++
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"errcode": "M_MISSING_TOKEN"})
+			return
+		}
+		parts := strings.Split(r.URL.Path, "/")
+		s.txnIDs = append(s.txnIDs, parts[len(parts)-1])
+
+		if s.failSends > 0 {
+			s.failSends--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		s.bodies = append(s.bodies, body)
+		json.NewEncoder(w).Encode(map[string]string{"event_id": "$event"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestMatrixNotificator_RetryKeepsTransactionID(t *testing.T) {
+	standIn := &matrixStandIn{failSends: 1}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	notificator := NewMatrixNotificator(MatrixNotificatorConfig{
+		Name:        "matrix",
+		Homeserver:  server.URL,
+		RoomID:      "!room:example.com",
+		AccessToken: "token",
+	}, Templates{})
+
+	// failed send is returned to the delivery queue, which retries it
+	checkResult := status.NewCheckResult("api_v1", "http", nil, status.StateNotAvailable)
+	if err := notificator.Send(checkResult); err == nil {
+		t.Fatal("Expected Send() to fail")
+	}
+	if err := notificator.Send(checkResult); err != nil {
+		t.Fatalf("Expected retried Send() to succeed, got %v", err)
+	}
+
+	if len(standIn.txnIDs) != 2 {
+		t.Fatalf("Expected 2 send attempts, got %d", len(standIn.txnIDs))
+	}
+	if standIn.txnIDs[0] != standIn.txnIDs[1] {
+		t.Errorf("Expected retry to use transaction ID %s, got %s", standIn.txnIDs[0], standIn.txnIDs[1])
+	}
+
+	other := status.NewCheckResult("api_v1", "http", nil, status.StateNotAvailable)
+	other.CheckedAt = checkResult.CheckedAt.Add(time.Minute)
+	if err := notificator.Send(other); err != nil {
+		t.Fatalf("Expected Send() to succeed, got %v", err)
+	}
+	if standIn.txnIDs[2] == standIn.txnIDs[0] {
+		t.Error("Expected new check result to use new transaction ID")
+	}
+
+	body := standIn.bodies[0]
+	if body["format"] != "org.matrix.custom.html" {
+		t.Errorf("Expected HTML formatted message, got format '%s'", body["format"])
+	}
+	if !strings.Contains(body["formatted_body"], "<code>api_v1</code>") {
+		t.Errorf("Expected resource name in formatted body, got '%s'", body["formatted_body"])
+	}
+}
+
+func TestMatrixNotificator_RejectedStaticTokenIsNotRetried(t *testing.T) {
+	standIn := &matrixStandIn{rejected: "revoked"}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	notificator := NewMatrixNotificator(MatrixNotificatorConfig{
+		Name:        "matrix",
+		Homeserver:  server.URL,
+		RoomID:      "!room:example.com",
+		AccessToken: "revoked",
+	}, Templates{})
+
+	err := notificator.Send(status.NewCheckResult("api", "http", nil, status.StateNotAvailable))
+	if !errors.Is(err, errMatrixUnauthorized) {
+		t.Fatalf("Expected unauthorized error, got %v", err)
+	}
+	if standIn.unauthorized != 1 || standIn.logins != 0 {
+		t.Errorf("Expected single attempt without login, got %d attempts and %d logins", standIn.unauthorized, standIn.logins)
+	}
+}
+
+func TestMatrixNotificator_PasswordLoginCachesToken(t *testing.T) {
+	standIn := &matrixStandIn{}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	cacheFile := filepath.Join(t.TempDir(), "token")
+	config := MatrixNotificatorConfig{
+		Name:           "matrix",
+		Homeserver:     server.URL,
+		RoomID:         "!room:example.com",
+		User:           "avalio",
+		Password:       "secret",
+		TokenCacheFile: cacheFile,
+	}
+
+	checkResult := status.NewCheckResult("api", "http", nil, status.StateRecovered)
+	if err := NewMatrixNotificator(config, Templates{}).Send(checkResult); err != nil {
+		t.Fatalf("Expected Send() to succeed, got %v", err)
+	}
+
+	cached, err := os.ReadFile(cacheFile)
+	if err != nil || string(cached) != "login-token" {
+		t.Fatalf("Expected token to be cached, got '%s' (%v)", cached, err)
+	}
+
+	// new notificator instance must reuse cached token
+	if err := NewMatrixNotificator(config, Templates{}).Send(checkResult); err != nil {
+		t.Fatalf("Expected Send() to succeed, got %v", err)
+	}
+	if standIn.logins != 1 {
+		t.Errorf("Expected exactly 1 login, got %d", standIn.logins)
+	}
+}
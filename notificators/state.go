@@ -0,0 +1,81 @@
+package notificators
+
+import (
+	"context"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+// ResourceStatus is the last known state of a resource checked by a monitor
+type ResourceStatus struct {
+	ResourceName string               `json:"resource_name"`
+	ResourceType string               `json:"resource_type"`
+	MonitorName  string               `json:"monitor_name"`
+	State        status.ResourceState `json:"state"`
+	CheckedAt    time.Time            `json:"checked_at"`
+	DownSince    time.Time            `json:"down_since,omitzero"`
+	MutedUntil   time.Time            `json:"muted_until,omitzero"`
+}
+
+// Incident is a period of resource unavailability
+type Incident struct {
+	ID             int       `json:"id"`
+	ResourceName   string    `json:"resource_name"`
+	MonitorName    string    `json:"monitor_name"`
+	StartedAt      time.Time `json:"started_at"`
+	ResolvedAt     time.Time `json:"resolved_at,omitzero"`
+	AcknowledgedAt time.Time `json:"acknowledged_at,omitzero"`
+	AcknowledgedBy string    `json:"acknowledged_by,omitempty"`
+}
+
+// Gap is a period when the monitoring host was offline, check results of
+// this period are not recorded
+type Gap struct {
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+}
+
+// Silence suppresses notifications about check results matching all its
+// non-empty conditions until it expires
+type Silence struct {
+	ID        int               `json:"id"`
+	Resources []string          `json:"resources,omitempty"`
+	Monitors  []string          `json:"monitors,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Comment   string            `json:"comment,omitempty"`
+	CreatedBy string            `json:"created_by,omitempty"`
+	StartsAt  time.Time         `json:"starts_at"`
+	EndsAt    time.Time         `json:"ends_at"`
+}
+
+func (i Incident) IsOpen() bool {
+	return i.ResolvedAt.IsZero()
+}
+
+func (i Incident) IsAcknowledged() bool {
+	return !i.AcknowledgedAt.IsZero()
+}
+
+// StateProvider gives notificators access to application state without
+// depending on the application itself
+type StateProvider interface {
+	// Statuses returns last check results of all monitored resources
+	Statuses() []ResourceStatus
+	// Incidents returns open incidents followed by recently resolved ones
+	Incidents() []Incident
+	// CheckNow runs resource check immediately, monitors state is not changed
+	CheckNow(resourceName string) (status.CheckResult, error)
+	// Mute suppresses notifications about resource for the given duration,
+	// zero duration unmutes resource
+	Mute(resourceName string, duration time.Duration) error
+	// Acknowledge marks open incidents of the resource as acknowledged,
+	// which stops reminders about them
+	Acknowledge(resourceName, by string) error
+}
+
+// Interactive is implemented by notificators, which receive commands from
+// users. Listen blocks until ctx is done.
+type Interactive interface {
+	Listen(ctx context.Context, provider StateProvider)
+}
@@ -0,0 +1,284 @@
+package notificators
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+// syslogStructuredDataID identifies avalio structured data element,
+// 32473 is the private enterprise number reserved for documentation (RFC 5612)
+const syslogStructuredDataID = "avalio@32473"
+
+var syslogFacilities = map[string]int{
+	"kern":     0,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+const syslogDefaultTemplate = "resource {{ .ResourceName }} is {{ .State }}" +
+	"{{ range $i, $d := .Details }}{{ if eq $i 0 }}:{{ else }};{{ end }} {{ $d.Title }}: {{ $d.Description }}{{ end }}"
+
+// syslogDefaultTemplates are used instead of defaultTemplates, because
+// every state should be written to syslog
+var syslogDefaultTemplates = TemplatesConfig{
+	Available:         syslogDefaultTemplate,
+	NotAvailable:      syslogDefaultTemplate,
+	StillNotAvailable: syslogDefaultTemplate,
+	Recovered:         syslogDefaultTemplate,
+}
+
+// syslog severities, RFC 5424 section 6.2.1
+const (
+	syslogSeverityError   = 3
+	syslogSeverityWarning = 4
+	syslogSeverityNotice  = 5
+	syslogSeverityInfo    = 6
+)
+
+// SyslogNotificator sends check results to syslog in RFC 5424 format
+type SyslogNotificator struct {
+	config   SyslogNotificatorConfig
+	renderer *Renderer
+	states   []status.ResourceState
+	facility int
+	hostname string
+	appName  string
+
+	mu      sync.Mutex
+	conn    net.Conn
+	network string
+}
+
+// Send implements Notificator.
+func (s *SyslogNotificator) Send(checkResult status.CheckResult) error {
+	if len(s.states) > 0 && !slices.Contains(s.states, checkResult.State) {
+		return nil
+	}
+
+	message, err := s.format(checkResult)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the connection may be closed by the server, reconnect once on failure
+	err = s.write(message)
+	if err != nil {
+		s.close()
+		err = s.write(message)
+	}
+	if err != nil {
+		s.close()
+		return fmt.Errorf("failed to write to syslog: %v", err)
+	}
+
+	return nil
+}
+
+func (s *SyslogNotificator) write(message string) error {
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+
+	switch s.network {
+	case "tcp":
+		// octet counting framing, RFC 6587 section 3.4.1
+		message = fmt.Sprintf("%d %s", len(message), message)
+	case "unix":
+		message += "\n"
+	}
+
+	s.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := s.conn.Write([]byte(message))
+	return err
+}
+
+func (s *SyslogNotificator) connect() error {
+	if s.config.Network == "" && s.config.Address == "" {
+		return s.connectLocal()
+	}
+
+	if s.config.Network == "unix" {
+		// unix sockets of syslog daemons are usually datagram-oriented
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.DialTimeout(network, s.config.Address, 10*time.Second)
+			if err == nil {
+				s.conn, s.network = conn, network
+				return nil
+			}
+		}
+		return fmt.Errorf("can't connect to %s", s.config.Address)
+	}
+
+	conn, err := net.DialTimeout(s.config.Network, s.config.Address, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	s.conn, s.network = conn, s.config.Network
+	return nil
+}
+
+func (s *SyslogNotificator) connectLocal() error {
+	for _, address := range []string{"/dev/log", "/var/run/syslog", "/var/run/log"} {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.DialTimeout(network, address, 10*time.Second)
+			if err == nil {
+				s.conn, s.network = conn, network
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("local syslog socket not found")
+}
+
+func (s *SyslogNotificator) close() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// Close implements io.Closer. The connection is opened again by the next
+// Send.
+func (s *SyslogNotificator) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.close()
+	return nil
+}
+
+// format builds RFC 5424 message:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ELEMENT] MSG
+func (s *SyslogNotificator) format(checkResult status.CheckResult) (string, error) {
+	priority := s.facility*8 + syslogSeverity(checkResult.State)
+
+	timestamp := checkResult.CheckedAt
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	structuredData := fmt.Sprintf(
+		`[%s resource="%s" type="%s" state="%s"]`,
+		syslogStructuredDataID,
+		escapeSDParam(checkResult.ResourceName),
+		escapeSDParam(checkResult.ResourceType),
+		escapeSDParam(checkResult.State.String()),
+	)
+
+	message, _, err := s.renderer.Render(checkResult)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(
+		"<%d>1 %s %s %s %d %s %s %s",
+		priority,
+		timestamp.Format("2006-01-02T15:04:05.000000Z07:00"),
+		s.hostname,
+		s.appName,
+		os.Getpid(),
+		strings.ReplaceAll(checkResult.State.String(), " ", "_"),
+		structuredData,
+		singleLine(message),
+	), nil
+}
+
+// singleLine joins non-empty lines of multiline message with "; "
+func singleLine(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "; ")
+}
+
+func syslogSeverity(state status.ResourceState) int {
+	switch state {
+	case status.StateNotAvailable:
+		return syslogSeverityError
+	case status.StateStillNotAvailable:
+		return syslogSeverityWarning
+	case status.StateRecovered:
+		return syslogSeverityNotice
+	default:
+		return syslogSeverityInfo
+	}
+}
+
+// escapeSDParam escapes structured data parameter value, RFC 5424 section 6.3.3
+func escapeSDParam(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
+
+// GetName implements Notificator.
+func (s *SyslogNotificator) GetName() string {
+	return s.config.Name
+}
+
+func NewSyslogNotificator(config SyslogNotificatorConfig, templates Templates) *SyslogNotificator {
+	facility, ok := syslogFacilities[config.Facility]
+	if !ok {
+		facility = syslogFacilities["daemon"]
+	}
+
+	hostname := config.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	appName := config.AppName
+	if appName == "" {
+		appName = "avalio"
+	}
+
+	// states are checked in Validate
+	states, _ := parseStates(config.States)
+
+	return &SyslogNotificator{
+		config: config,
+		renderer: newRenderer(
+			formatPlain,
+			templates.language(config.Language),
+			syslogDefaultTemplates,
+			templates,
+			config.Templates,
+		),
+		states:   states,
+		facility: facility,
+		hostname: hostname,
+		appName:  appName,
+	}
+}
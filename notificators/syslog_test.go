@@ -0,0 +1,51 @@
+package notificators
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+func TestSyslogNotificator_RFC5424(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("can't listen udp: %v", err)
+	}
+	defer listener.Close()
+
+	notificator := NewSyslogNotificator(SyslogNotificatorConfig{
+		Name:     "syslog",
+		Network:  "udp",
+		Address:  listener.LocalAddr().String(),
+		Facility: "local0",
+		Hostname: "monitoring",
+	}, Templates{})
+
+	details := []status.CheckDetails{status.NewCheckError("Reason", `bad "gateway"`)}
+	if err := notificator.Send(status.NewCheckResult("api]1", "http", details, status.StateNotAvailable)); err != nil {
+		t.Fatalf("Expected Send() to succeed, got %v", err)
+	}
+
+	buf := make([]byte, 2048)
+	n, _, err := listener.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := string(buf[:n])
+
+	// local0 (16) * 8 + err (3)
+	if !strings.HasPrefix(message, "<131>1 ") {
+		t.Errorf("Expected priority <131> and version 1, got '%s'", message)
+	}
+	if !strings.Contains(message, " monitoring avalio ") {
+		t.Errorf("Expected hostname and app name, got '%s'", message)
+	}
+	if !strings.Contains(message, ` not_available [avalio@32473 resource="api\]1" type="http" state="not available"] `) {
+		t.Errorf("Expected msgid and structured data, got '%s'", message)
+	}
+	if !strings.HasSuffix(message, `Reason: bad "gateway"`) {
+		t.Errorf("Expected details in message, got '%s'", message)
+	}
+}
@@ -2,78 +2,283 @@ package notificators
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/andrewsapw/avalio/status"
 )
 
+const telegramAPIURL = "https://api.telegram.org"
+
+// telegramMaxCallbackData is the Telegram limit of inline button payload size
+const telegramMaxCallbackData = 64
+
+// telegramSentMessage identifies message sent to a chat
+type telegramSentMessage struct {
+	chatID    string
+	messageID int
+}
+
 type TelegramNotificator struct {
-	config TelegramNotificatorConfig
+	config    TelegramNotificatorConfig
+	chats     []TelegramChatConfig
+	parseMode string
+	renderer  *Renderer
+	apiURL    string
+	client    http.Client
+
+	mu sync.Mutex
+	// lastNotified holds time of the last outage message per monitor and
+	// resource, it is used to throttle reminders
+	lastNotified map[string]time.Time
+	// outageMessages holds sent outage messages per monitor and resource,
+	// they are edited on recovery if edit_on_recovery is enabled
+	outageMessages map[string][]telegramSentMessage
 }
 
 // TelegramResponse represents the structure of Telegram API response
 type TelegramResponse struct {
-	OK          bool   `json:"ok"`
-	Description string `json:"description"`
+	OK          bool            `json:"ok"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
 }
 
 // Send implements Notificator.
-func (t TelegramNotificator) Send(checkResult status.CheckResult) error {
-	var message string
+func (t *TelegramNotificator) Send(checkResult status.CheckResult) error {
+	if !t.shouldNotify(checkResult) {
+		return nil
+	}
 
-	switch checkResult.State {
-	case status.StateNotAvailable:
-		messageDetails := []string{
-			fmt.Sprintf("Тип проверки: `%s`", checkResult.ResourceType),
-			checkResult.ErrorsAsString(),
+	message, ok, err := t.renderer.Render(checkResult)
+	if err != nil || !ok {
+		return err
+	}
+
+	key := checkResult.MonitorName + "/" + checkResult.ResourceName
+	chats := t.chats
+	if checkResult.State == status.StateRecovered && t.config.EditOnRecovery {
+		chats = t.editOutageMessages(key, message)
+	}
+
+	params := map[string]any{}
+	if checkResult.State == status.StateNotAvailable && t.config.Interactive {
+		if keyboard := t.ackKeyboard(checkResult.ResourceName); keyboard != nil {
+			params["reply_markup"] = keyboard
 		}
-		message = fmt.Sprintf(
-			"❌ Ресурс `%s` недоступен.\n\n%s",
-			checkResult.ResourceName,
-			strings.Join(messageDetails, "\n"),
-		)
-	case status.StateRecovered:
-		message = fmt.Sprintf("✅ Ресурс `%s` снова доступен.", checkResult.ResourceName)
-	case status.StateAvailable:
-		return nil
-	case status.StateStillNotAvailable:
-		return nil
-	default:
-		slog.Warn(
-			"Ошибка проверки состояния ресурса. Код состояния не поддерживается",
-			"resource_name", checkResult.ResourceName,
-			"state", checkResult.State,
-		)
 	}
+	if checkResult.State == status.StateRecovered && t.config.SilentRecovery {
+		params["disable_notification"] = true
+	}
+
+	sent, err := t.broadcast(chats, message, params)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.notified(checkResult)
+	if checkResult.State == status.StateNotAvailable && t.config.EditOnRecovery {
+		t.outageMessages[key] = sent
+	}
+	return nil
+}
+
+// SendDigest implements DigestSender.
+func (t *TelegramNotificator) SendDigest(digest Digest) error {
+	message, err := t.renderer.RenderDigest(digest)
+	if err != nil {
+		return err
+	}
+
+	params := map[string]any{}
+	recoveredOnly := !slices.ContainsFunc(digest.Results, func(checkResult status.CheckResult) bool {
+		return checkResult.State != status.StateRecovered
+	})
+	if recoveredOnly && t.config.SilentRecovery {
+		params["disable_notification"] = true
+	}
+
+	// outage messages are edited as on separate recoveries, the digest is
+	// still sent to every chat, as it may hold other results
+	if t.config.EditOnRecovery {
+		for _, checkResult := range digest.Results {
+			if checkResult.State != status.StateRecovered {
+				continue
+			}
+			recovery, ok, err := t.renderer.Render(checkResult)
+			if err != nil || !ok {
+				continue
+			}
+			t.editOutageMessages(checkResult.MonitorName+"/"+checkResult.ResourceName, recovery)
+		}
+	}
+
+	if _, err := t.broadcast(t.chats, message, params); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, checkResult := range digest.Results {
+		t.notified(checkResult)
+		// digest message holds other resources, so it isn't edited on
+		// recovery and older outage message must not be edited either
+		delete(t.outageMessages, checkResult.MonitorName+"/"+checkResult.ResourceName)
+	}
+	return nil
+}
+
+// broadcast sends message to every chat, failure in one chat doesn't stop
+// sending to others. Failures are only logged if any chat got the message,
+// as a retry would send it again to chats, which already got it.
+func (t *TelegramNotificator) broadcast(chats []TelegramChatConfig, message string, params map[string]any) ([]telegramSentMessage, error) {
+	var errs []error
+	var sent []telegramSentMessage
+	for _, chat := range chats {
+		messageID, err := t.sendMessage(chat, message, params)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("chat %s: %w", chat.ChatID, err))
+			continue
+		}
+		sent = append(sent, telegramSentMessage{chatID: chat.ChatID, messageID: messageID})
+	}
+
+	if len(sent) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		slog.Error("Can't send telegram message", "notificator_name", t.GetName(), "error", err)
+	}
+	return sent, nil
+}
 
-	err := t.sendMessage(message)
-	return err
+// editOutageMessages replaces outage messages with the recovery message and
+// returns chats, where the message should be sent as a new one
+func (t *TelegramNotificator) editOutageMessages(key string, message string) []TelegramChatConfig {
+	t.mu.Lock()
+	sent := t.outageMessages[key]
+	delete(t.outageMessages, key)
+	t.mu.Unlock()
+
+	var chats []TelegramChatConfig
+	for _, chat := range t.chats {
+		edited := false
+		for _, s := range sent {
+			if s.chatID != chat.ChatID {
+				continue
+			}
+			err := t.call(context.Background(), "editMessageText", map[string]any{
+				"chat_id":    s.chatID,
+				"message_id": s.messageID,
+				"text":       message,
+				"parse_mode": t.parseMode,
+			}, nil)
+			if err != nil {
+				slog.Warn("Can't edit telegram message", "notificator_name", t.GetName(), "error", err)
+				break
+			}
+			edited = true
+		}
+		if !edited {
+			chats = append(chats, chat)
+		}
+	}
+	return chats
 }
 
-func (t TelegramNotificator) sendMessage(message string) error {
-	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.config.Token)
+// shouldNotify throttles reminders about resources which are still not
+// available to one per reminder_minutes
+func (t *TelegramNotificator) shouldNotify(checkResult status.CheckResult) bool {
+	if checkResult.State != status.StateStillNotAvailable || t.config.ReminderMinutes <= 0 {
+		return true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	interval := time.Duration(t.config.ReminderMinutes) * time.Minute
+	return time.Since(t.lastNotified[checkResult.MonitorName+"/"+checkResult.ResourceName]) >= interval
+}
 
-	client := http.Client{
-		Timeout: 10 * time.Second,
+// notified records time of the sent outage message, so failed sends don't
+// delay reminders. t.mu must be held.
+func (t *TelegramNotificator) notified(checkResult status.CheckResult) {
+	key := checkResult.MonitorName + "/" + checkResult.ResourceName
+	switch checkResult.State {
+	case status.StateNotAvailable, status.StateStillNotAvailable:
+		t.lastNotified[key] = time.Now()
+	default:
+		delete(t.lastNotified, key)
+	}
+}
+
+func (t *TelegramNotificator) ackKeyboard(resourceName string) any {
+	data := telegramAckPrefix + resourceName
+	if len(data) > telegramMaxCallbackData {
+		return nil
 	}
 
-	requestBody, err := json.Marshal(map[string]any{
-		"chat_id":    t.config.ChatID,
+	return map[string]any{
+		"inline_keyboard": [][]map[string]string{{
+			{
+				"text":          status.Msg(status.MsgBotAckButton).Render(t.renderer.language),
+				"callback_data": data,
+			},
+		}},
+	}
+}
+
+// sendMessage sends message to the chat and returns its ID. extra holds
+// additional sendMessage parameters.
+func (t *TelegramNotificator) sendMessage(chat TelegramChatConfig, message string, extra map[string]any) (int, error) {
+	params := map[string]any{
+		"chat_id":    chat.ChatID,
 		"text":       message,
-		"parse_mode": "Markdown",
-	})
+		"parse_mode": t.parseMode,
+	}
+	if chat.MessageThreadID != 0 {
+		params["message_thread_id"] = chat.MessageThreadID
+	}
+	for k, v := range extra {
+		params[k] = v
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var sent telegramMessage
+	err := t.call(ctx, "sendMessage", params, &sent)
+	return sent.MessageID, err
+}
+
+// call invokes Telegram Bot API method and decodes its result
+func (t *TelegramNotificator) call(ctx context.Context, method string, params map[string]any, result any) error {
+	apiURL := fmt.Sprintf("%s/bot%s/%s", t.apiURL, t.config.Token, method)
+
+	requestBody, err := json.Marshal(params)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request body: %v", err)
 	}
 
-	resp, err := client.Post(apiURL, "application/json", bytes.NewBuffer(requestBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("HTTP request failed: %v", err)
+		// don't leak bot token, which is a part of request url
+		return fmt.Errorf("HTTP request failed: %v", strings.ReplaceAll(err.Error(), t.config.Token, "***"))
 	}
 	defer resp.Body.Close()
 
@@ -86,14 +291,51 @@ func (t TelegramNotificator) sendMessage(message string) error {
 		return fmt.Errorf("telegram API error: %s", telegramResp.Description)
 	}
 
+	if result != nil {
+		if err := json.Unmarshal(telegramResp.Result, result); err != nil {
+			return fmt.Errorf("failed to decode result: %v", err)
+		}
+	}
+
 	return nil
 }
 
 // GetName implements Notificator.
-func (t TelegramNotificator) GetName() string {
+func (t *TelegramNotificator) GetName() string {
 	return t.config.Name
 }
 
-func NewTelegramNotificator(config TelegramNotificatorConfig) TelegramNotificator {
-	return TelegramNotificator{config: config}
+func NewTelegramNotificator(config TelegramNotificatorConfig, templates Templates) *TelegramNotificator {
+	defaults := defaultTemplates
+	if config.ReminderMinutes > 0 {
+		defaults.StillNotAvailable = defaultReminderTemplate
+	}
+
+	parseMode, format := "HTML", formatHTML
+	if config.ParseMode == "MarkdownV2" {
+		parseMode, format = "MarkdownV2", formatMarkdownV2
+	}
+
+	apiURL := strings.TrimRight(config.APIURL, "/")
+	if apiURL == "" {
+		apiURL = telegramAPIURL
+	}
+
+	return &TelegramNotificator{
+		config:    config,
+		chats:     config.allChats(),
+		parseMode: parseMode,
+		renderer: newRenderer(
+			format,
+			templates.language(config.Language),
+			defaults,
+			templates,
+			config.Templates,
+		),
+		apiURL: apiURL,
+		// long polling requests wait for updates up to telegramPollTimeout
+		client:         http.Client{Timeout: telegramPollTimeout + 10*time.Second},
+		lastNotified:   make(map[string]time.Time),
+		outageMessages: make(map[string][]telegramSentMessage),
+	}
 }
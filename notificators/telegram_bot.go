@@ -0,0 +1,286 @@
+package notificators
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+const (
+	telegramPollTimeout = 30 * time.Second
+	telegramErrorDelay  = 5 * time.Second
+	telegramAckPrefix   = "ack:"
+)
+
+type telegramUser struct {
+	ID        int64  `json:"id"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+}
+
+func (u telegramUser) displayName() string {
+	if u.Username != "" {
+		return "@" + u.Username
+	}
+	return u.FirstName
+}
+
+type telegramChat struct {
+	ID int64 `json:"id"`
+}
+
+type telegramMessage struct {
+	MessageID       int           `json:"message_id"`
+	MessageThreadID int           `json:"message_thread_id"`
+	Chat            telegramChat  `json:"chat"`
+	From            *telegramUser `json:"from"`
+	Text            string        `json:"text"`
+}
+
+type telegramCallbackQuery struct {
+	ID      string           `json:"id"`
+	From    telegramUser     `json:"from"`
+	Message *telegramMessage `json:"message"`
+	Data    string           `json:"data"`
+}
+
+type telegramUpdate struct {
+	UpdateID      int                    `json:"update_id"`
+	Message       *telegramMessage       `json:"message"`
+	CallbackQuery *telegramCallbackQuery `json:"callback_query"`
+}
+
+// Listen implements Interactive. If interactive mode is enabled, it long
+// polls Telegram for updates and answers commands from allowed chats.
+func (t *TelegramNotificator) Listen(ctx context.Context, provider StateProvider) {
+	if !t.config.Interactive {
+		return
+	}
+
+	slog.Info("Starting telegram bot", "notificator_name", t.GetName())
+
+	offset := 0
+	for ctx.Err() == nil {
+		var updates []telegramUpdate
+		err := t.call(ctx, "getUpdates", map[string]any{
+			"offset":          offset,
+			"timeout":         int(telegramPollTimeout.Seconds()),
+			"allowed_updates": []string{"message", "callback_query"},
+		}, &updates)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Error("Error receiving telegram updates", "notificator_name", t.GetName(), "error", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(telegramErrorDelay):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			t.handleUpdate(provider, update)
+		}
+	}
+}
+
+func (t *TelegramNotificator) handleUpdate(provider StateProvider, update telegramUpdate) {
+	switch {
+	case update.Message != nil && strings.HasPrefix(update.Message.Text, "/"):
+		chatID := strconv.FormatInt(update.Message.Chat.ID, 10)
+		if !t.isAllowed(chatID) {
+			slog.Warn("Command from unauthorised chat", "notificator_name", t.GetName(), "chat_id", chatID)
+			return
+		}
+
+		var from string
+		if update.Message.From != nil {
+			from = update.Message.From.displayName()
+		}
+
+		// reply to the same forum topic the command was sent to
+		chat := TelegramChatConfig{ChatID: chatID, MessageThreadID: update.Message.MessageThreadID}
+		reply := t.handleCommand(provider, update.Message.Text, from)
+		if _, err := t.sendMessage(chat, reply, nil); err != nil {
+			slog.Error("Error sending telegram reply", "notificator_name", t.GetName(), "error", err)
+		}
+	case update.CallbackQuery != nil:
+		t.handleCallback(provider, *update.CallbackQuery)
+	}
+}
+
+func (t *TelegramNotificator) handleCommand(provider StateProvider, text, from string) string {
+	fields := strings.Fields(text)
+	// commands in groups are sent as /command@bot_name
+	command, _, _ := strings.Cut(fields[0], "@")
+	args := fields[1:]
+
+	switch command {
+	case "/status":
+		return t.statusReply(provider)
+	case "/check":
+		if len(args) != 1 {
+			return t.renderer.t(status.MsgBotUsageCheck)
+		}
+		return t.checkReply(provider, args[0])
+	case "/mute":
+		if len(args) != 2 {
+			return t.renderer.t(status.MsgBotUsageMute)
+		}
+		duration, err := time.ParseDuration(args[1])
+		if err != nil || duration <= 0 {
+			return t.renderer.t(status.MsgBotUsageMute)
+		}
+		if err := provider.Mute(args[0], duration); err != nil {
+			return t.renderer.t(status.MsgBotError, t.renderer.format.escape(err.Error()))
+		}
+		until := t.renderer.format.escape(time.Now().Add(duration).Format(time.DateTime))
+		return t.renderer.t(status.MsgBotMuted, t.renderer.format.escape(args[0]), until)
+	case "/unmute":
+		if len(args) != 1 {
+			return t.renderer.t(status.MsgBotUsageUnmute)
+		}
+		if err := provider.Mute(args[0], 0); err != nil {
+			return t.renderer.t(status.MsgBotError, t.renderer.format.escape(err.Error()))
+		}
+		return t.renderer.t(status.MsgBotUnmuted, t.renderer.format.escape(args[0]))
+	case "/ack":
+		if len(args) != 1 {
+			return t.renderer.t(status.MsgBotUsageAck)
+		}
+		if err := provider.Acknowledge(args[0], from); err != nil {
+			return t.renderer.t(status.MsgBotError, t.renderer.format.escape(err.Error()))
+		}
+		return t.renderer.t(status.MsgBotAcknowledged, t.renderer.format.escape(args[0]), t.renderer.format.escape(from))
+	case "/incidents":
+		return t.incidentsReply(provider)
+	default:
+		return t.renderer.t(status.MsgBotHelp)
+	}
+}
+
+// handleCallback processes inline keyboard button presses
+func (t *TelegramNotificator) handleCallback(provider StateProvider, query telegramCallbackQuery) {
+	if query.Message == nil || !strings.HasPrefix(query.Data, telegramAckPrefix) {
+		return
+	}
+
+	chatID := strconv.FormatInt(query.Message.Chat.ID, 10)
+	if !t.isAllowed(chatID) {
+		slog.Warn("Callback from unauthorised chat", "notificator_name", t.GetName(), "chat_id", chatID)
+		return
+	}
+
+	resourceName := strings.TrimPrefix(query.Data, telegramAckPrefix)
+	from := query.From.displayName()
+
+	answer := t.t(status.MsgBotAcknowledged, resourceName, from)
+	if err := provider.Acknowledge(resourceName, from); err != nil {
+		answer = t.t(status.MsgBotError, err.Error())
+	} else {
+		// remove the button from the outage message
+		t.call(context.Background(), "editMessageReplyMarkup", map[string]any{
+			"chat_id":      chatID,
+			"message_id":   query.Message.MessageID,
+			"reply_markup": map[string]any{"inline_keyboard": [][]any{}},
+		}, nil)
+	}
+
+	err := t.call(context.Background(), "answerCallbackQuery", map[string]any{
+		"callback_query_id": query.ID,
+		"text":              answer,
+	}, nil)
+	if err != nil {
+		slog.Error("Error answering telegram callback", "notificator_name", t.GetName(), "error", err)
+	}
+}
+
+func (t *TelegramNotificator) statusReply(provider StateProvider) string {
+	statuses := provider.Statuses()
+	if len(statuses) == 0 {
+		return t.renderer.t(status.MsgBotNoResources)
+	}
+
+	var b strings.Builder
+	for _, s := range statuses {
+		state := s.State.String()
+		if !s.MutedUntil.IsZero() {
+			state += ", " + t.t(status.MsgBotMutedLabel)
+		}
+		fmt.Fprintf(&b, "%-20s %-12s %s\n", s.ResourceName, s.MonitorName, state)
+	}
+	return t.renderer.format.pre(b.String())
+}
+
+func (t *TelegramNotificator) checkReply(provider StateProvider, resourceName string) string {
+	checkResult, err := provider.CheckNow(resourceName)
+	if err != nil {
+		return t.renderer.t(status.MsgBotError, t.renderer.format.escape(err.Error()))
+	}
+
+	if checkResult.State == status.StateAvailable {
+		return "✅ " + t.renderer.t(status.MsgBotCheckAvailable, t.renderer.format.escape(resourceName))
+	}
+
+	var b strings.Builder
+	b.WriteString("❌ " + t.renderer.t(status.MsgNotificationNotAvailable, t.renderer.format.escape(resourceName)) + "\n\n")
+	for _, d := range checkResult.Details {
+		fmt.Fprintf(
+			&b, "%s: %s\n",
+			t.renderer.format.escape(d.TitleIn(t.renderer.language)),
+			t.renderer.format.escape(d.DescriptionIn(t.renderer.language)),
+		)
+	}
+	return b.String()
+}
+
+func (t *TelegramNotificator) incidentsReply(provider StateProvider) string {
+	incidents := provider.Incidents()
+	if len(incidents) == 0 {
+		return t.renderer.t(status.MsgBotNoIncidents)
+	}
+
+	var lines []string
+	for _, incident := range incidents {
+		name := t.renderer.format.escape(incident.ResourceName)
+		startedAt := t.renderer.format.escape(incident.StartedAt.Format(time.DateTime))
+
+		var line string
+		if incident.IsOpen() {
+			line = "🔴 " + t.renderer.t(status.MsgBotIncidentOpen, incident.ID, name, startedAt)
+			if incident.IsAcknowledged() {
+				line += " " + t.renderer.t(status.MsgBotIncidentAcknowledged, t.renderer.format.escape(incident.AcknowledgedBy))
+			}
+		} else {
+			downtime := t.renderer.format.escape(formatDuration(incident.ResolvedAt.Sub(incident.StartedAt)))
+			line = "🟢 " + t.renderer.t(status.MsgBotIncidentResolved, incident.ID, name, startedAt, downtime)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isAllowed reports whether commands from the chat should be answered.
+// By default commands are accepted from the chats notifications are sent to.
+func (t *TelegramNotificator) isAllowed(chatID string) bool {
+	if len(t.config.AllowedChatIDs) == 0 {
+		return slices.ContainsFunc(t.chats, func(chat TelegramChatConfig) bool {
+			return chat.ChatID == chatID
+		})
+	}
+	return slices.Contains(t.config.AllowedChatIDs, chatID)
+}
+
+// t translates catalog message to the notificator language without
+// formatting, it is used for texts Telegram shows as is
+func (t *TelegramNotificator) t(key string, params ...any) string {
+	return status.Msg(key, params...).Render(t.renderer.language)
+}
@@ -0,0 +1,231 @@
+package notificators
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+type telegramStandIn struct {
+	mu    sync.Mutex
+	calls map[string][]map[string]any
+	// failChats holds chats, where messages are rejected
+	failChats map[string]bool
+}
+
+func (s *telegramStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var params map[string]any
+	json.NewDecoder(r.Body).Decode(&params)
+
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	s.calls[method] = append(s.calls[method], params)
+
+	if chatID, _ := params["chat_id"].(string); method == "sendMessage" && s.failChats[chatID] {
+		json.NewEncoder(w).Encode(map[string]any{"ok": false, "description": "Bad Request: chat not found"})
+		return
+	}
+
+	var result any = true
+	if method == "sendMessage" {
+		result = map[string]any{"message_id": len(s.calls[method])}
+	}
+	json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+type mockedStateProvider struct {
+	muted        map[string]time.Duration
+	acknowledged []string
+	incidents    []Incident
+}
+
+func (p *mockedStateProvider) Statuses() []ResourceStatus {
+	return []ResourceStatus{{ResourceName: "api", MonitorName: "every-minute", State: status.StateNotAvailable}}
+}
+
+func (p *mockedStateProvider) Incidents() []Incident { return p.incidents }
+
+func (p *mockedStateProvider) CheckNow(resourceName string) (status.CheckResult, error) {
+	return status.NewCheckResult(resourceName, "http", nil, status.StateAvailable), nil
+}
+
+func (p *mockedStateProvider) Mute(resourceName string, duration time.Duration) error {
+	p.muted[resourceName] = duration
+	return nil
+}
+
+func (p *mockedStateProvider) Acknowledge(resourceName, by string) error {
+	p.acknowledged = append(p.acknowledged, resourceName+" by "+by)
+	return nil
+}
+
+func newTestTelegramBot(t *testing.T, reminderMinutes int) (*TelegramNotificator, *telegramStandIn) {
+	return newTestTelegramBotWithMode(t, reminderMinutes, "")
+}
+
+func newTestTelegramBotWithMode(t *testing.T, reminderMinutes int, parseMode string) (*TelegramNotificator, *telegramStandIn) {
+	standIn := &telegramStandIn{calls: make(map[string][]map[string]any)}
+	server := httptest.NewServer(standIn)
+	t.Cleanup(server.Close)
+
+	notificator := NewTelegramNotificator(TelegramNotificatorConfig{
+		Name:            "bot",
+		ChatID:          "100",
+		Token:           "token",
+		Language:        "en",
+		Interactive:     true,
+		AllowedChatIDs:  []string{"100"},
+		ReminderMinutes: reminderMinutes,
+		ParseMode:       parseMode,
+	}, Templates{})
+	notificator.apiURL = server.URL
+	return notificator, standIn
+}
+
+func TestTelegramBot_Commands(t *testing.T) {
+	notificator, standIn := newTestTelegramBot(t, 0)
+	provider := &mockedStateProvider{muted: make(map[string]time.Duration)}
+
+	command := func(chatID int64, text string) {
+		notificator.handleUpdate(provider, telegramUpdate{Message: &telegramMessage{
+			Chat: telegramChat{ID: chatID},
+			From: &telegramUser{Username: "oncall"},
+			Text: text,
+		}})
+	}
+
+	command(100, "/mute api 2h")
+	if provider.muted["api"] != 2*time.Hour {
+		t.Errorf("Expected api to be muted for 2h, got %v", provider.muted["api"])
+	}
+
+	command(100, "/status@avalio_bot")
+	command(200, "/ack api")
+	if len(provider.acknowledged) != 0 {
+		t.Error("Expected command from unauthorised chat to be ignored")
+	}
+
+	replies := standIn.calls["sendMessage"]
+	if len(replies) != 2 {
+		t.Fatalf("Expected 2 replies, got %d", len(replies))
+	}
+	if !strings.Contains(replies[0]["text"].(string), "muted until") {
+		t.Errorf("Unexpected mute reply: %v", replies[0]["text"])
+	}
+	if !strings.Contains(replies[1]["text"].(string), "every-minute") {
+		t.Errorf("Expected status table, got %v", replies[1]["text"])
+	}
+}
+
+// markdownV2Unescaped matches reserved MarkdownV2 characters, which are
+// not escaped, as Telegram rejects such messages
+var markdownV2Unescaped = regexp.MustCompile(`(^|[^\\])[-.!#=+|{}()]`)
+
+func TestTelegramBot_CommandsMarkdownV2(t *testing.T) {
+	notificator, standIn := newTestTelegramBotWithMode(t, 0, "MarkdownV2")
+	provider := &mockedStateProvider{muted: make(map[string]time.Duration)}
+
+	notificator.handleUpdate(provider, telegramUpdate{Message: &telegramMessage{
+		Chat: telegramChat{ID: 100},
+		From: &telegramUser{Username: "oncall"},
+		Text: "/mute api.v1 2h",
+	}})
+
+	replies := standIn.calls["sendMessage"]
+	if len(replies) != 1 {
+		t.Fatalf("Expected 1 reply, got %d", len(replies))
+	}
+	text := replies[0]["text"].(string)
+	if match := markdownV2Unescaped.FindString(text); match != "" {
+		t.Errorf("Expected reserved characters to be escaped, got %q in %q", match, text)
+	}
+	if !strings.Contains(text, `api\.v1`) {
+		t.Errorf("Unexpected mute reply: %q", text)
+	}
+}
+
+func TestTelegramBot_IncidentsMarkdownV2(t *testing.T) {
+	notificator, standIn := newTestTelegramBotWithMode(t, 0, "MarkdownV2")
+	started := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	provider := &mockedStateProvider{
+		muted: make(map[string]time.Duration),
+		incidents: []Incident{
+			{ID: 1, ResourceName: "api.v1", StartedAt: started},
+			{ID: 2, ResourceName: "db", StartedAt: started, ResolvedAt: started.Add(90*time.Minute + 500*time.Millisecond)},
+		},
+	}
+
+	notificator.handleUpdate(provider, telegramUpdate{Message: &telegramMessage{
+		Chat: telegramChat{ID: 100},
+		From: &telegramUser{Username: "oncall"},
+		Text: "/incidents",
+	}})
+
+	replies := standIn.calls["sendMessage"]
+	if len(replies) != 1 {
+		t.Fatalf("Expected 1 reply, got %d", len(replies))
+	}
+	text := replies[0]["text"].(string)
+	if match := markdownV2Unescaped.FindString(text); match != "" {
+		t.Errorf("Expected reserved characters to be escaped, got %q in %q", match, text)
+	}
+	if !strings.Contains(text, `2026\-10\-19 03:00:00`) {
+		t.Errorf("Expected escaped incident start time, got %q", text)
+	}
+}
+
+func TestTelegramBot_AcknowledgeButton(t *testing.T) {
+	notificator, standIn := newTestTelegramBot(t, 0)
+	provider := &mockedStateProvider{muted: make(map[string]time.Duration)}
+
+	checkResult := status.NewCheckResult("api", "http", nil, status.StateNotAvailable)
+	if err := notificator.Send(checkResult); err != nil {
+		t.Fatalf("Expected Send() to succeed, got %v", err)
+	}
+
+	markup, _ := standIn.calls["sendMessage"][0]["reply_markup"].(map[string]any)
+	keyboard, _ := markup["inline_keyboard"].([]any)
+	if len(keyboard) != 1 {
+		t.Fatalf("Expected acknowledge button, got %v", markup)
+	}
+	button := keyboard[0].([]any)[0].(map[string]any)
+
+	notificator.handleUpdate(provider, telegramUpdate{CallbackQuery: &telegramCallbackQuery{
+		ID:      "query",
+		From:    telegramUser{Username: "oncall"},
+		Message: &telegramMessage{MessageID: 1, Chat: telegramChat{ID: 100}},
+		Data:    button["callback_data"].(string),
+	}})
+
+	if len(provider.acknowledged) != 1 || provider.acknowledged[0] != "api by @oncall" {
+		t.Errorf("Expected api to be acknowledged by @oncall, got %v", provider.acknowledged)
+	}
+	if len(standIn.calls["answerCallbackQuery"]) != 1 {
+		t.Error("Expected callback query to be answered")
+	}
+}
+
+func TestTelegramNotificator_Reminders(t *testing.T) {
+	notificator, standIn := newTestTelegramBot(t, 30)
+
+	notificator.Send(status.NewCheckResult("api", "http", nil, status.StateNotAvailable))
+	notificator.Send(status.NewCheckResult("api", "http", nil, status.StateStillNotAvailable))
+	if len(standIn.calls["sendMessage"]) != 1 {
+		t.Errorf("Expected reminder to be throttled, got %d messages", len(standIn.calls["sendMessage"]))
+	}
+
+	notificator.lastNotified["/api"] = time.Now().Add(-time.Hour)
+	notificator.Send(status.NewCheckResult("api", "http", nil, status.StateStillNotAvailable))
+	if len(standIn.calls["sendMessage"]) != 2 {
+		t.Errorf("Expected reminder after interval, got %d messages", len(standIn.calls["sendMessage"]))
+	}
+}
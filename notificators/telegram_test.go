@@ -0,0 +1,173 @@
+package notificators
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+func TestTelegramNotificator_ChatsAndRecovery(t *testing.T) {
+	standIn := &telegramStandIn{calls: make(map[string][]map[string]any)}
+	server := httptest.NewServer(standIn)
+	t.Cleanup(server.Close)
+
+	notificator := NewTelegramNotificator(TelegramNotificatorConfig{
+		Name:           "telegram",
+		ChatID:         "100",
+		Chats:          []TelegramChatConfig{{ChatID: "-200", MessageThreadID: 7}},
+		Token:          "token",
+		APIURL:         server.URL + "/",
+		Language:       "en",
+		SilentRecovery: true,
+		EditOnRecovery: true,
+	}, Templates{})
+
+	if err := notificator.Send(status.NewCheckResult("api.v1", "http", nil, status.StateNotAvailable)); err != nil {
+		t.Fatalf("Expected Send() to succeed, got %v", err)
+	}
+
+	sent := standIn.calls["sendMessage"]
+	if len(sent) != 2 {
+		t.Fatalf("Expected message to be sent to 2 chats, got %d", len(sent))
+	}
+	if sent[0]["parse_mode"] != "HTML" || !strings.Contains(sent[0]["text"].(string), "<code>api.v1</code>") {
+		t.Errorf("Expected HTML message, got %v", sent[0])
+	}
+	if _, exists := sent[0]["message_thread_id"]; exists {
+		t.Errorf("Expected no thread for chat without message_thread_id, got %v", sent[0])
+	}
+	if sent[1]["chat_id"] != "-200" || sent[1]["message_thread_id"] != float64(7) {
+		t.Errorf("Expected message to be sent to the thread, got %v", sent[1])
+	}
+
+	if err := notificator.Send(status.NewCheckResult("api.v1", "http", nil, status.StateRecovered)); err != nil {
+		t.Fatalf("Expected Send() to succeed, got %v", err)
+	}
+
+	edits := standIn.calls["editMessageText"]
+	if len(edits) != 2 {
+		t.Fatalf("Expected outage messages to be edited, got %d edits", len(edits))
+	}
+	if edits[1]["chat_id"] != "-200" || edits[1]["message_id"] != float64(2) {
+		t.Errorf("Expected second outage message to be edited, got %v", edits[1])
+	}
+	if len(standIn.calls["sendMessage"]) != 2 {
+		t.Errorf("Expected no new messages on recovery, got %d", len(standIn.calls["sendMessage"])-2)
+	}
+
+	// without stored outage messages recovery is sent silently
+	if err := notificator.Send(status.NewCheckResult("api.v1", "http", nil, status.StateRecovered)); err != nil {
+		t.Fatalf("Expected Send() to succeed, got %v", err)
+	}
+	sent = standIn.calls["sendMessage"]
+	if len(sent) != 4 || sent[2]["disable_notification"] != true {
+		t.Errorf("Expected silent recovery messages, got %v", sent[2:])
+	}
+}
+
+func TestTelegramNotificator_ChatFailure(t *testing.T) {
+	standIn := &telegramStandIn{calls: make(map[string][]map[string]any), failChats: map[string]bool{"-200": true}}
+	server := httptest.NewServer(standIn)
+	t.Cleanup(server.Close)
+
+	notificator := NewTelegramNotificator(TelegramNotificatorConfig{
+		Name:     "telegram",
+		ChatID:   "100",
+		Chats:    []TelegramChatConfig{{ChatID: "-200"}},
+		Token:    "token",
+		APIURL:   server.URL + "/",
+		Language: "en",
+	}, Templates{})
+
+	// the message isn't retried, as the first chat has got it
+	if err := notificator.Send(status.NewCheckResult("api", "http", nil, status.StateNotAvailable)); err != nil {
+		t.Errorf("Expected Send() to succeed if any chat got the message, got %v", err)
+	}
+
+	standIn.failChats["100"] = true
+	err := notificator.Send(status.NewCheckResult("api", "http", nil, status.StateNotAvailable))
+	if err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Errorf("Expected error if no chat got the message, got %v", err)
+	}
+}
+
+func TestTelegramNotificator_ReminderAfterFailure(t *testing.T) {
+	notificator, standIn := newTestTelegramBot(t, 60)
+	standIn.failChats = map[string]bool{"100": true}
+
+	outage := status.NewCheckResult("api", "http", nil, status.StateNotAvailable)
+	if err := notificator.Send(outage); err == nil {
+		t.Fatal("Expected Send() to fail")
+	}
+
+	// retried reminder isn't throttled by the failed send
+	standIn.failChats = nil
+	if err := notificator.Send(status.NewCheckResult("api", "http", nil, status.StateStillNotAvailable)); err != nil {
+		t.Fatalf("Expected Send() to succeed, got %v", err)
+	}
+	if err := notificator.Send(status.NewCheckResult("api", "http", nil, status.StateStillNotAvailable)); err != nil {
+		t.Fatalf("Expected Send() to succeed, got %v", err)
+	}
+	if sent := standIn.calls["sendMessage"]; len(sent) != 2 {
+		t.Errorf("Expected failed outage and one reminder, got %d messages", len(sent))
+	}
+}
+
+func TestTelegramNotificator_DigestRecovery(t *testing.T) {
+	standIn := &telegramStandIn{calls: make(map[string][]map[string]any)}
+	server := httptest.NewServer(standIn)
+	t.Cleanup(server.Close)
+
+	notificator := NewTelegramNotificator(TelegramNotificatorConfig{
+		Name:           "telegram",
+		ChatID:         "100",
+		Token:          "token",
+		APIURL:         server.URL + "/",
+		Language:       "en",
+		EditOnRecovery: true,
+	}, Templates{})
+
+	if err := notificator.Send(status.NewCheckResult("api", "http", nil, status.StateNotAvailable)); err != nil {
+		t.Fatalf("Expected Send() to succeed, got %v", err)
+	}
+	err := notificator.SendDigest(Digest{Results: []status.CheckResult{
+		status.NewCheckResult("api", "http", nil, status.StateRecovered),
+		status.NewCheckResult("db", "tcp", nil, status.StateNotAvailable),
+	}})
+	if err != nil {
+		t.Fatalf("Expected SendDigest() to succeed, got %v", err)
+	}
+
+	edits := standIn.calls["editMessageText"]
+	if len(edits) != 1 || edits[0]["message_id"] != float64(1) || !strings.Contains(edits[0]["text"].(string), "available again") {
+		t.Errorf("Expected outage message to be edited with recovery, got %v", edits)
+	}
+
+	// digest message isn't edited on recovery of its resources
+	if err := notificator.Send(status.NewCheckResult("db", "tcp", nil, status.StateRecovered)); err != nil {
+		t.Fatalf("Expected Send() to succeed, got %v", err)
+	}
+	if len(standIn.calls["editMessageText"]) != 1 || len(standIn.calls["sendMessage"]) != 3 {
+		t.Errorf("Expected recovery to be sent as a new message, got %v", standIn.calls)
+	}
+}
+
+func TestTelegramNotificator_MarkdownV2(t *testing.T) {
+	notificator := NewTelegramNotificator(TelegramNotificatorConfig{
+		Name:      "telegram",
+		ChatID:    "100",
+		Token:     "token",
+		ParseMode: "MarkdownV2",
+		Language:  "en",
+	}, Templates{})
+
+	message, _, err := notificator.renderer.Render(status.NewCheckResult("api.v1", "http", nil, status.StateRecovered))
+	if err != nil {
+		t.Fatalf("Expected Render() to succeed, got %v", err)
+	}
+	if expected := "✅ Resource `api.v1` is available again\\."; message != expected {
+		t.Errorf("Expected %q, got %q", expected, message)
+	}
+}
@@ -0,0 +1,362 @@
+package notificators
+
+import (
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+// [notificators.templates]
+// not_available = '🔥 {{ code .ResourceName }} is down'
+// recovered = '{{ code .ResourceName }} is back after {{ duration .Downtime }}'
+//
+// Empty template is inherited from the upper level: monitor templates
+// override notificator templates, which override global ones.
+type TemplatesConfig struct {
+	Available         string `toml:"available"`
+	NotAvailable      string `toml:"not_available"`
+	StillNotAvailable string `toml:"still_not_available"`
+	Recovered         string `toml:"recovered"`
+	// Digest renders grouped results, it can't be overridden by monitors
+	Digest string `toml:"digest"`
+}
+
+// Validate parses every template and executes it with sample data, so
+// unknown functions and fields are reported at config load time.
+func (c TemplatesConfig) Validate() error {
+	sample := newTemplateData(status.LanguageEnglish, status.CheckResult{
+		ResourceName: "example",
+		ResourceType: "http",
+		Details:      []status.CheckDetails{status.NewCheckError("title", "description")},
+		CheckedAt:    time.Now(),
+		DownSince:    time.Now(),
+	})
+
+	for state, text := range c.byState() {
+		if text == "" {
+			continue
+		}
+		tmpl, err := parseTemplate(formatPlain, status.LanguageEnglish, state.String(), text)
+		if err != nil {
+			return fmt.Errorf("invalid '%s' template: %v", state, err)
+		}
+		sample.State = state
+		if err := tmpl.Execute(&strings.Builder{}, sample); err != nil {
+			return fmt.Errorf("invalid '%s' template: %v", state, err)
+		}
+	}
+
+	if c.Digest != "" {
+		tmpl, err := parseTemplate(formatPlain, status.LanguageEnglish, "digest", c.Digest)
+		if err != nil {
+			return fmt.Errorf("invalid 'digest' template: %v", err)
+		}
+		digest := Digest{Results: []status.CheckResult{{ResourceName: "example", State: status.StateNotAvailable}}}
+		if err := tmpl.Execute(&strings.Builder{}, newDigestData(status.LanguageEnglish, digest)); err != nil {
+			return fmt.Errorf("invalid 'digest' template: %v", err)
+		}
+	}
+	return nil
+}
+
+func (c TemplatesConfig) byState() map[status.ResourceState]string {
+	return map[status.ResourceState]string{
+		status.StateAvailable:         c.Available,
+		status.StateNotAvailable:      c.NotAvailable,
+		status.StateStillNotAvailable: c.StillNotAvailable,
+		status.StateRecovered:         c.Recovered,
+	}
+}
+
+// Templates holds templates and language shared by all notificators
+type Templates struct {
+	Global   TemplatesConfig
+	Monitors map[string]TemplatesConfig
+	Language status.Language
+}
+
+// language returns notificator language, falling back to the shared one
+func (t Templates) language(code string) status.Language {
+	// language is checked in Validate
+	if language, err := status.ParseLanguage(code); code != "" && err == nil {
+		return language
+	}
+	if t.Language != "" {
+		return t.Language
+	}
+	return status.DefaultLanguage()
+}
+
+// defaultTemplates are used for states without configured templates
+var defaultTemplates = TemplatesConfig{
+	NotAvailable: "❌ {{ t \"notification.not_available\" (code .ResourceName) }}\n\n" +
+		"{{ t \"notification.check_type\" (code .ResourceType) }}\n" +
+		"{{ range .Details }}{{ escape .Title }}: {{ escape .Description }}\n{{ end }}",
+	Recovered: "✅ {{ t \"notification.recovered\" (code .ResourceName) }}",
+}
+
+// defaultReminderTemplate is used by notificators with reminders enabled
+const defaultReminderTemplate = "⏰ {{ t \"notification.still_not_available\" (code .ResourceName) (duration .Downtime) }}"
+
+type messageFormat int
+
+const (
+	formatPlain messageFormat = iota
+	formatMarkdownV2
+	formatHTML
+)
+
+// TemplateDetail is a single check detail, translated to notificator language
+type TemplateDetail struct {
+	Title       string
+	Description string
+}
+
+// TemplateData is passed to templates on render
+type TemplateData struct {
+	ResourceName string
+	ResourceType string
+	MonitorName  string
+	Labels       map[string]string
+	State        status.ResourceState
+	Details      []TemplateDetail
+	CheckedAt    time.Time
+	DownSince    time.Time
+	// Downtime is duration of the current outage, zero if resource is available
+	Downtime time.Duration
+}
+
+func newTemplateData(language status.Language, checkResult status.CheckResult) TemplateData {
+	data := TemplateData{
+		ResourceName: checkResult.ResourceName,
+		ResourceType: checkResult.ResourceType,
+		MonitorName:  checkResult.MonitorName,
+		Labels:       checkResult.Labels,
+		State:        checkResult.State,
+		CheckedAt:    checkResult.CheckedAt,
+		DownSince:    checkResult.DownSince,
+	}
+	for _, d := range checkResult.Details {
+		data.Details = append(data.Details, TemplateDetail{
+			Title:       d.TitleIn(language),
+			Description: d.DescriptionIn(language),
+		})
+	}
+	if !checkResult.DownSince.IsZero() {
+		data.Downtime = checkResult.CheckedAt.Sub(checkResult.DownSince)
+	}
+	return data
+}
+
+// Renderer renders messages for a single notificator
+type Renderer struct {
+	format    formatter
+	language  status.Language
+	templates map[status.ResourceState]*template.Template
+	monitors  map[string]map[status.ResourceState]*template.Template
+	digest    *template.Template
+}
+
+// Render returns message for check result. If there is no template for the
+// result state, ok is false and nothing should be sent.
+func (r *Renderer) Render(checkResult status.CheckResult) (message string, ok bool, err error) {
+	tmpl := r.templates[checkResult.State]
+	if monitorTemplate, exists := r.monitors[checkResult.MonitorName][checkResult.State]; exists {
+		tmpl = monitorTemplate
+	}
+	if tmpl == nil {
+		return "", false, nil
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, newTemplateData(r.language, checkResult)); err != nil {
+		return "", false, fmt.Errorf("failed to render '%s' template: %v", checkResult.State, err)
+	}
+	return b.String(), true, nil
+}
+
+// RenderDigest returns message for grouped check results
+func (r *Renderer) RenderDigest(digest Digest) (string, error) {
+	var b strings.Builder
+	if err := r.digest.Execute(&b, newDigestData(r.language, digest)); err != nil {
+		return "", fmt.Errorf("failed to render 'digest' template: %v", err)
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// t translates catalog message to the renderer language and format
+func (r *Renderer) t(key string, params ...any) string {
+	return r.format.t(r.language, key, params...)
+}
+
+// newRenderer merges defaults, global and notificator templates and parses
+// them for the given message format. Templates are expected to be validated.
+func newRenderer(
+	format messageFormat,
+	language status.Language,
+	defaults TemplatesConfig,
+	shared Templates,
+	own TemplatesConfig,
+) *Renderer {
+	renderer := &Renderer{
+		format:    newFormatter(format),
+		language:  language,
+		templates: make(map[status.ResourceState]*template.Template),
+		monitors:  make(map[string]map[status.ResourceState]*template.Template),
+	}
+
+	layers := []TemplatesConfig{{Digest: defaultDigestTemplate}, defaults, shared.Global, own}
+	for _, layer := range layers {
+		for state, text := range layer.byState() {
+			if tmpl := mustParseTemplate(format, language, state.String(), text); tmpl != nil {
+				renderer.templates[state] = tmpl
+			}
+		}
+		if tmpl := mustParseTemplate(format, language, "digest", layer.Digest); tmpl != nil {
+			renderer.digest = tmpl
+		}
+	}
+
+	for monitorName, monitorTemplates := range shared.Monitors {
+		parsed := make(map[status.ResourceState]*template.Template)
+		for state, text := range monitorTemplates.byState() {
+			if tmpl := mustParseTemplate(format, language, state.String(), text); tmpl != nil {
+				parsed[state] = tmpl
+			}
+		}
+		renderer.monitors[monitorName] = parsed
+	}
+
+	return renderer
+}
+
+func mustParseTemplate(
+	format messageFormat,
+	language status.Language,
+	name string,
+	text string,
+) *template.Template {
+	if text == "" {
+		return nil
+	}
+	tmpl, err := parseTemplate(format, language, name, text)
+	if err != nil {
+		slog.Error("Invalid template", "template", name, "error", err)
+		return nil
+	}
+	return tmpl
+}
+
+func parseTemplate(
+	format messageFormat,
+	language status.Language,
+	name string,
+	text string,
+) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs(format, language)).Parse(text)
+}
+
+// formatter formats message parts according to notificator message format
+type formatter struct {
+	escape func(string) string
+	code   func(string) string
+	bold   func(string) string
+	pre    func(string) string
+}
+
+func newFormatter(format messageFormat) formatter {
+	switch format {
+	case formatMarkdownV2:
+		return formatter{
+			escape: escapeMarkdownV2,
+			code:   func(s string) string { return "`" + escapeMarkdownV2Code(s) + "`" },
+			bold:   func(s string) string { return "*" + escapeMarkdownV2(s) + "*" },
+			pre:    func(s string) string { return "```\n" + escapeMarkdownV2Code(s) + "```" },
+		}
+	case formatHTML:
+		return formatter{
+			escape: html.EscapeString,
+			code:   func(s string) string { return "<code>" + html.EscapeString(s) + "</code>" },
+			bold:   func(s string) string { return "<b>" + html.EscapeString(s) + "</b>" },
+			pre:    func(s string) string { return "<pre>" + html.EscapeString(s) + "</pre>" },
+		}
+	default:
+		identity := func(s string) string { return s }
+		return formatter{escape: identity, code: identity, bold: identity, pre: identity}
+	}
+}
+
+// t translates catalog message to the language. Message text is escaped,
+// parameters are inserted as is, so they should be escaped by the caller.
+func (f formatter) t(language status.Language, key string, params ...any) string {
+	return status.Msg(key, params...).RenderEscaped(language, f.escape)
+}
+
+func templateFuncs(format messageFormat, language status.Language) template.FuncMap {
+	f := newFormatter(format)
+
+	return template.FuncMap{
+		// escape, code, bold and t depend on notificator message format
+		"escape":   f.escape,
+		"code":     f.code,
+		"bold":     f.bold,
+		"markdown": escapeMarkdownV2,
+		"html":     html.EscapeString,
+		"duration": formatDuration,
+		"since":    func(t time.Time) time.Duration { return time.Since(t) },
+		// t translates message from the catalog to notificator language
+		"t": func(key string, params ...any) string {
+			return f.t(language, key, params...)
+		},
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
+
+// escapeMarkdownV2 escapes characters with special meaning in Telegram MarkdownV2
+func escapeMarkdownV2(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\_*[]()~`>#+-=|{}.!", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// escapeMarkdownV2Code escapes text inside MarkdownV2 code entities
+func escapeMarkdownV2Code(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "`", "\\`").Replace(s)
+}
+
+// formatDuration formats duration as "1d 2h 3m 4s", omitting zero parts
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d <= 0 {
+		return "0s"
+	}
+
+	var parts []string
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+	for _, unit := range units {
+		if d >= unit.size {
+			parts = append(parts, fmt.Sprintf("%d%s", d/unit.size, unit.suffix))
+			d %= unit.size
+		}
+	}
+	return strings.Join(parts, " ")
+}
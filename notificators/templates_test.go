@@ -0,0 +1,130 @@
+package notificators
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+func TestRenderer_Precedence(t *testing.T) {
+	templates := Templates{
+		Global: TemplatesConfig{
+			NotAvailable: "global {{ .ResourceName }}",
+			Recovered:    "global recovered",
+		},
+		Monitors: map[string]TemplatesConfig{
+			"payments": {NotAvailable: "monitor {{ .MonitorName }}"},
+		},
+	}
+	own := TemplatesConfig{Recovered: "own recovered"}
+	renderer := newRenderer(formatPlain, status.LanguageEnglish, defaultTemplates, templates, own)
+
+	cases := []struct {
+		monitor  string
+		state    status.ResourceState
+		expected string
+		ok       bool
+	}{
+		{"other", status.StateNotAvailable, "global api", true},
+		{"payments", status.StateNotAvailable, "monitor payments", true},
+		{"payments", status.StateRecovered, "own recovered", true},
+		{"other", status.StateStillNotAvailable, "", false},
+	}
+	for _, c := range cases {
+		checkResult := status.NewCheckResult("api", "http", nil, c.state)
+		checkResult.MonitorName = c.monitor
+
+		message, ok, err := renderer.Render(checkResult)
+		if err != nil {
+			t.Fatalf("Expected Render() to succeed, got %v", err)
+		}
+		if message != c.expected || ok != c.ok {
+			t.Errorf("Expected (%q, %v) for %s/%s, got (%q, %v)", c.expected, c.ok, c.monitor, c.state, message, ok)
+		}
+	}
+}
+
+func TestRenderer_FormatAwareHelpers(t *testing.T) {
+	own := TemplatesConfig{NotAvailable: "{{ code .ResourceName }} {{ escape .ResourceName }} {{ duration .Downtime }}"}
+	checkResult := status.NewCheckResult("api_<v1>", "http", nil, status.StateNotAvailable)
+	checkResult.DownSince = checkResult.CheckedAt.Add(-(time.Hour + 5*time.Second))
+
+	cases := map[messageFormat]string{
+		formatPlain:      "api_<v1> api_<v1> 1h 5s",
+		formatMarkdownV2: "`api_<v1>` api\\_<v1\\> 1h 5s",
+		formatHTML:       "<code>api_&lt;v1&gt;</code> api_&lt;v1&gt; 1h 5s",
+	}
+	for format, expected := range cases {
+		message, _, err := newRenderer(format, status.LanguageEnglish, TemplatesConfig{}, Templates{}, own).Render(checkResult)
+		if err != nil {
+			t.Fatalf("Expected Render() to succeed, got %v", err)
+		}
+		if message != expected {
+			t.Errorf("Expected %q, got %q", expected, message)
+		}
+	}
+}
+
+func TestRenderer_EscapesTranslations(t *testing.T) {
+	own := TemplatesConfig{Recovered: `{{ t "notification.recovered" (code .ResourceName) }}`}
+	checkResult := status.NewCheckResult("my-api", "http", nil, status.StateRecovered)
+
+	cases := map[messageFormat]string{
+		formatPlain:      "Resource my-api is available again.",
+		formatMarkdownV2: "Resource `my-api` is available again\\.",
+		formatHTML:       "Resource <code>my-api</code> is available again.",
+	}
+	for format, expected := range cases {
+		message, _, err := newRenderer(format, status.LanguageEnglish, TemplatesConfig{}, Templates{}, own).Render(checkResult)
+		if err != nil {
+			t.Fatalf("Expected Render() to succeed, got %v", err)
+		}
+		if message != expected {
+			t.Errorf("Expected %q, got %q", expected, message)
+		}
+	}
+}
+
+func TestRenderer_RenderDigest(t *testing.T) {
+	down := status.NewCheckResult("api", "http", []status.CheckDetails{status.NewCheckError("Reason", "timeout")}, status.StateNotAvailable)
+	recovered := status.NewCheckResult("db", "ping", nil, status.StateRecovered)
+	recovered.DownSince = recovered.CheckedAt.Add(-2 * time.Minute)
+
+	renderer := newRenderer(formatPlain, status.LanguageEnglish, defaultTemplates, Templates{}, TemplatesConfig{})
+	message, err := renderer.RenderDigest(Digest{Results: []status.CheckResult{down, recovered}})
+	if err != nil {
+		t.Fatalf("Expected RenderDigest() to succeed, got %v", err)
+	}
+
+	expected := "❌ 1 resources are not available:\n• api - timeout\n\n✅ 1 resources are available again:\n• db (2m)"
+	if message != expected {
+		t.Errorf("Expected %q, got %q", expected, message)
+	}
+
+	own := TemplatesConfig{Digest: "{{ len .Results }} changes in {{ .Group.env }}"}
+	renderer = newRenderer(formatPlain, status.LanguageEnglish, defaultTemplates, Templates{}, own)
+	message, _ = renderer.RenderDigest(Digest{Group: map[string]string{"env": "prod"}, Results: []status.CheckResult{down}})
+	if message != "1 changes in prod" {
+		t.Errorf("Expected custom digest template to be used, got %q", message)
+	}
+}
+
+func TestTemplatesConfig_Validate(t *testing.T) {
+	valid := TemplatesConfig{Recovered: "{{ .ResourceName }} is back after {{ duration .Downtime }}"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected template to be valid, got %v", err)
+	}
+
+	invalid := []TemplatesConfig{
+		{NotAvailable: "{{ .ResourceName "},
+		{NotAvailable: "{{ unknown .ResourceName }}"},
+		{Recovered: "{{ .UnknownField }}"},
+		{Digest: "{{ .ResourceName }}"},
+	}
+	for _, config := range invalid {
+		if err := config.Validate(); err == nil {
+			t.Errorf("Expected error for %+v", config)
+		}
+	}
+}
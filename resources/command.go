@@ -0,0 +1,104 @@
+package resources
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// commandWaitDelay is how long output of killed command is read, e.g. if
+// its pipes are kept open by a daemonized child
+const commandWaitDelay = time.Second
+
+// command is an external program run by a resource check
+type command struct {
+	path    string
+	args    []string
+	env     []string
+	dir     string
+	timeout time.Duration
+	// maxOutput limits size of stdout and stderr kept in the result
+	maxOutput int
+}
+
+// commandResult is a result of command run. Err is set if the command
+// couldn't be run or has timed out, exit code is -1 in this case.
+type commandResult struct {
+	stdout   string
+	stderr   string
+	exitCode int
+	timedOut bool
+	err      error
+}
+
+// run starts the command in its own process group, so all its children
+// are killed on timeout
+func (c command) run() commandResult {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: c.maxOutput}
+	stderr := &limitedBuffer{limit: c.maxOutput}
+	cmd := exec.CommandContext(ctx, c.path, c.args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Dir = c.dir
+	if len(c.env) > 0 {
+		cmd.Env = append(os.Environ(), c.env...)
+	}
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		return killProcessGroup(cmd)
+	}
+	cmd.WaitDelay = commandWaitDelay
+
+	err := cmd.Run()
+	result := commandResult{
+		stdout:   stdout.String(),
+		stderr:   stderr.String(),
+		exitCode: -1,
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.timedOut = true
+		result.err = fmt.Errorf("command timed out after %s", c.timeout)
+	case errors.As(err, &exitErr):
+		result.exitCode = exitErr.ExitCode()
+	case err != nil:
+		result.err = err
+	default:
+		result.exitCode = 0
+	}
+	return result
+}
+
+// limitedBuffer keeps the first limit bytes written to it, the rest is
+// discarded, so a chatty command can't exhaust memory
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if free := b.limit - b.buf.Len(); len(p) > free {
+		b.buf.Write(p[:max(free, 0)])
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// String returns kept output, "..." marks truncated one
+func (b *limitedBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + "..."
+	}
+	return b.buf.String()
+}
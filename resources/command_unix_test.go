@@ -0,0 +1,64 @@
+//go:build unix
+
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCommand_KillsProcessGroupOnTimeout(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	started := time.Now()
+	result := command{
+		path: "sh",
+		// the child keeps stdout open, so the command can't finish until
+		// the child is killed too
+		args:      []string{"-c", "sleep 30 & echo $! > " + pidFile + "; wait"},
+		timeout:   200 * time.Millisecond,
+		maxOutput: 1024,
+	}.run()
+
+	if !result.timedOut || result.err == nil {
+		t.Fatalf("Expected command to time out, got %+v", result)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("Expected command to be killed on timeout, it took %s", elapsed)
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the killed child may stay a zombie for a moment, until it is reaped
+	deadline := time.Now().Add(2 * time.Second)
+	alive := func() bool {
+		return syscall.Kill(pid, 0) == nil && !isZombie(pid)
+	}
+	for alive() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if alive() {
+		t.Errorf("Expected child process %d to be killed", pid)
+	}
+}
+
+// isZombie reports whether the process has exited, but isn't reaped yet
+func isZombie(pid int) bool {
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	// state follows the command name in parentheses
+	fields := strings.Fields(string(stat[strings.LastIndex(string(stat), ")")+1:]))
+	return len(fields) > 0 && fields[0] == "Z"
+}
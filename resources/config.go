@@ -5,8 +5,18 @@ import (
 	"fmt"
 	"log/slog"
 	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
 )
 
+// ResourceDuplicateNameError is returned when several resources, of any
+// types, have the same name
+var ResourceDuplicateNameError = errors.New("duplicate resource name")
+
 // Error variables for HTTP resource validation
 var (
 	HTTPResourceNameIsEmptyError     = errors.New("name is required")
@@ -37,12 +47,16 @@ var (
 // [[resources.http]]
 // name = 'example'
 // url = 'https://example.com'
+// labels = { env = 'prod' }
+// depends_on = ['gateway']
 type HttpResourceConfig struct {
-	Url            string `toml:"url"`
-	Name           string `toml:"name"`
-	ExpectedStatus int    `toml:"expected_status"`
-	MaxRetries     int    `toml:"max_retries"`
-	RetryDelay     int    `toml:"retry_delay"`
+	Url            string            `toml:"url"`
+	Name           string            `toml:"name"`
+	ExpectedStatus int               `toml:"expected_status"`
+	MaxRetries     int               `toml:"max_retries"`
+	RetryDelay     int               `toml:"retry_delay"`
+	Labels         map[string]string `toml:"labels"`
+	DependsOn      []string          `toml:"depends_on"`
 }
 
 // Validate checks if the HTTP resource configuration is valid
@@ -116,9 +130,11 @@ func (c *HttpResourceConfig) Validate() error {
 // name = 'example'
 // address = 'https://example.com'
 type PingResourceConfig struct {
-	Address        string `toml:"address"`
-	Name           string `toml:"name"`
-	TimeoutSeconds int    `toml:"timeout_seconds"`
+	Address        string            `toml:"address"`
+	Name           string            `toml:"name"`
+	TimeoutSeconds int               `toml:"timeout_seconds"`
+	Labels         map[string]string `toml:"labels"`
+	DependsOn      []string          `toml:"depends_on"`
 }
 
 // Validate checks if the ping resource configuration is valid
@@ -153,33 +169,505 @@ func (c *PingResourceConfig) Validate() error {
 	return nil
 }
 
+// Error variables for Nagios resource validation
+var (
+	NagiosResourceNameIsEmptyError     = errors.New("name is required")
+	NagiosResourceCommandEmptyError    = errors.New("command is required")
+	NagiosResourceLongNameError        = errors.New("name must not exceed 255 characters")
+	NagiosResourceNegativeTimeoutError = errors.New("timeout_seconds must be non-negative")
+	NagiosResourceHighTimeoutError     = errors.New("timeout_seconds must not exceed 300 seconds (5 minutes)")
+)
+
+// [[resources.nagios]]
+// name = 'disk'
+// command = '/usr/lib/nagios/plugins/check_disk'
+// args = ['-w', '20%', '-c', '10%', '-p', '/']
+//
+// WARNING state of the plugin is a failure unless ignore_warning is set.
+type NagiosResourceConfig struct {
+	Name           string            `toml:"name"`
+	Command        string            `toml:"command"`
+	Args           []string          `toml:"args"`
+	TimeoutSeconds int               `toml:"timeout_seconds"`
+	IgnoreWarning  bool              `toml:"ignore_warning"`
+	Labels         map[string]string `toml:"labels"`
+	DependsOn      []string          `toml:"depends_on"`
+}
+
+// Validate checks if the Nagios resource configuration is valid
+func (c *NagiosResourceConfig) Validate() error {
+	if c.Name == "" {
+		return NagiosResourceNameIsEmptyError
+	}
+
+	if len(c.Name) > 255 {
+		return NagiosResourceLongNameError
+	}
+
+	if c.Command == "" {
+		return NagiosResourceCommandEmptyError
+	}
+
+	// zero timeout means the default one
+	if c.TimeoutSeconds < 0 {
+		return NagiosResourceNegativeTimeoutError
+	}
+
+	if c.TimeoutSeconds > 300 {
+		return NagiosResourceHighTimeoutError
+	}
+
+	return nil
+}
+
+// Error variables for exec resource validation
+var (
+	ExecResourceNameIsEmptyError     = errors.New("name is required")
+	ExecResourceCommandEmptyError    = errors.New("command is required")
+	ExecResourceLongNameError        = errors.New("name must not exceed 255 characters")
+	ExecResourceNegativeTimeoutError = errors.New("timeout_seconds must be non-negative")
+	ExecResourceHighTimeoutError     = errors.New("timeout_seconds must not exceed 300 seconds (5 minutes)")
+	ExecResourceInvalidEnvError      = errors.New("env names must not be empty or contain '='")
+)
+
+// [[resources.exec]]
+// name = 'backup'
+// command = '/usr/local/bin/check-backup.sh'
+// args = ['--max-age', '24h']
+// env = { BACKUP_DIR = '/var/backups' }
+// working_dir = '/var/backups'
+// json_output = true
+type ExecResourceConfig struct {
+	Name           string            `toml:"name"`
+	Command        string            `toml:"command"`
+	Args           []string          `toml:"args"`
+	Env            map[string]string `toml:"env"`
+	WorkingDir     string            `toml:"working_dir"`
+	TimeoutSeconds int               `toml:"timeout_seconds"`
+	// JSONOutput enables parsing of ExecOutput from stdout
+	JSONOutput bool              `toml:"json_output"`
+	Labels     map[string]string `toml:"labels"`
+	DependsOn  []string          `toml:"depends_on"`
+}
+
+// Validate checks if the exec resource configuration is valid
+func (c *ExecResourceConfig) Validate() error {
+	if c.Name == "" {
+		return ExecResourceNameIsEmptyError
+	}
+
+	if len(c.Name) > 255 {
+		return ExecResourceLongNameError
+	}
+
+	if c.Command == "" {
+		return ExecResourceCommandEmptyError
+	}
+
+	for name := range c.Env {
+		if name == "" || strings.Contains(name, "=") {
+			return ExecResourceInvalidEnvError
+		}
+	}
+
+	// zero timeout means the default one
+	if c.TimeoutSeconds < 0 {
+		return ExecResourceNegativeTimeoutError
+	}
+
+	if c.TimeoutSeconds > 300 {
+		return ExecResourceHighTimeoutError
+	}
+
+	return nil
+}
+
+// Error variables for heartbeat resource validation
+var (
+	HeartbeatResourceNameIsEmptyError    = errors.New("name is required")
+	HeartbeatResourceLongNameError       = errors.New("name must not exceed 255 characters")
+	HeartbeatResourceTokenEmptyError     = errors.New("token is required")
+	HeartbeatResourceInvalidTokenError   = errors.New("token must be at least 8 characters of letters, digits, '-' and '_'")
+	HeartbeatResourceScheduleError       = errors.New("either period_seconds or cron must be set")
+	HeartbeatResourceNegativePeriodError = errors.New("period_seconds must be non-negative")
+	HeartbeatResourceNegativeGraceError  = errors.New("grace_seconds must be non-negative")
+	HeartbeatResourceDuplicateTokenError = errors.New("token is already used by another heartbeat")
+)
+
+var heartbeatTokenRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]{8,}$`)
+
+// [[resources.heartbeat]]
+// name = 'nightly-backup'
+// token = 'b4ckup-2f9c1e'
+// cron = '0 3 * * *'
+// grace_seconds = 3600
+//
+// The job pings /ping/<token> of the API server. Pings are expected either
+// every period_seconds or after each run scheduled by cron.
+type HeartbeatResourceConfig struct {
+	Name string `toml:"name"`
+	// Token identifies the resource in ping URLs
+	Token string `toml:"token"`
+	// TokenFile is read into Token on config load
+	TokenFile     string            `toml:"token_file"`
+	PeriodSeconds int               `toml:"period_seconds"`
+	Cron          string            `toml:"cron"`
+	Timezone      string            `toml:"timezone"`
+	GraceSeconds  int               `toml:"grace_seconds"`
+	Labels        map[string]string `toml:"labels"`
+	DependsOn     []string          `toml:"depends_on"`
+}
+
+// Validate checks if the heartbeat resource configuration is valid
+func (c *HeartbeatResourceConfig) Validate() error {
+	if c.Name == "" {
+		return HeartbeatResourceNameIsEmptyError
+	}
+
+	if len(c.Name) > 255 {
+		return HeartbeatResourceLongNameError
+	}
+
+	if c.Token == "" {
+		return HeartbeatResourceTokenEmptyError
+	}
+
+	// token is a part of URL path and is guessed by nobody
+	if !heartbeatTokenRegexp.MatchString(c.Token) {
+		return HeartbeatResourceInvalidTokenError
+	}
+
+	if c.PeriodSeconds < 0 {
+		return HeartbeatResourceNegativePeriodError
+	}
+
+	if (c.PeriodSeconds == 0) == (c.Cron == "") {
+		return HeartbeatResourceScheduleError
+	}
+
+	if c.GraceSeconds < 0 {
+		return HeartbeatResourceNegativeGraceError
+	}
+
+	if _, _, err := c.compileSchedule(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// compileSchedule parses cron in the configured timezone, schedule is nil
+// if pings are expected periodically
+func (c *HeartbeatResourceConfig) compileSchedule() (cron.Schedule, *time.Location, error) {
+	location := time.Local
+	if c.Timezone != "" {
+		var err error
+		location, err = time.LoadLocation(c.Timezone)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid timezone '%s': %v", c.Timezone, err)
+		}
+	}
+	if c.Cron == "" {
+		return nil, location, nil
+	}
+
+	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+	schedule, err := parser.Parse(c.Cron)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid cron '%s': %v", c.Cron, err)
+	}
+	return schedule, location, nil
+}
+
+// Error variables for group resource validation
+var (
+	GroupResourceNameIsEmptyError     = errors.New("name is required")
+	GroupResourceLongNameError        = errors.New("name must not exceed 255 characters")
+	GroupResourceNoMembersError       = errors.New("members must not be empty")
+	GroupResourceDuplicateMemberError = errors.New("members must not contain duplicates")
+	GroupResourceInvalidModeError     = errors.New("mode must be one of all, any, quorum or percent")
+	GroupResourceQuorumRangeError     = errors.New("quorum must be between 1 and the number of members")
+	GroupResourcePercentRangeError    = errors.New("percent must be between 1 and 100")
+)
+
+// Modes of group resource, which define how many members must be available
+const (
+	GroupModeAll     = "all"
+	GroupModeAny     = "any"
+	GroupModeQuorum  = "quorum"
+	GroupModePercent = "percent"
+)
+
+// [[resources.group]]
+// name = 'api-replicas'
+// members = ['api-1', 'api-2', 'api-3']
+// mode = 'quorum'
+// quorum = 2
+//
+// Members are other resources, they are checked by the group and don't
+// need to be a part of any monitor.
+type GroupResourceConfig struct {
+	Name    string   `toml:"name"`
+	Members []string `toml:"members"`
+	// Mode is all if empty
+	Mode string `toml:"mode"`
+	// Quorum is the number of available members required in quorum mode
+	Quorum int `toml:"quorum"`
+	// Percent is the share of available members required in percent mode
+	Percent   int               `toml:"percent"`
+	Labels    map[string]string `toml:"labels"`
+	DependsOn []string          `toml:"depends_on"`
+}
+
+// Validate checks if the group resource configuration is valid. Members
+// are checked by ResourcesConfig.ValidateGroups.
+func (c *GroupResourceConfig) Validate() error {
+	if c.Name == "" {
+		return GroupResourceNameIsEmptyError
+	}
+
+	if len(c.Name) > 255 {
+		return GroupResourceLongNameError
+	}
+
+	if len(c.Members) == 0 {
+		return GroupResourceNoMembersError
+	}
+
+	for i, member := range c.Members {
+		if slices.Contains(c.Members[:i], member) {
+			return GroupResourceDuplicateMemberError
+		}
+	}
+
+	switch c.Mode {
+	case "", GroupModeAll, GroupModeAny:
+	case GroupModeQuorum:
+		if c.Quorum < 1 || c.Quorum > len(c.Members) {
+			return GroupResourceQuorumRangeError
+		}
+	case GroupModePercent:
+		if c.Percent < 1 || c.Percent > 100 {
+			return GroupResourcePercentRangeError
+		}
+	default:
+		return GroupResourceInvalidModeError
+	}
+
+	return nil
+}
+
+// required returns the number of members, which must be available
+func (c *GroupResourceConfig) required() int {
+	switch c.Mode {
+	case GroupModeAny:
+		return 1
+	case GroupModeQuorum:
+		return c.Quorum
+	case GroupModePercent:
+		// rounded up, so 50% of 3 members is 2
+		return (len(c.Members)*c.Percent + 99) / 100
+	default:
+		return len(c.Members)
+	}
+}
+
 type ResourcesConfig struct {
-	Http []HttpResourceConfig `toml:"http"`
-	Ping []PingResourceConfig `toml:"ping"`
+	Http      []HttpResourceConfig      `toml:"http"`
+	Ping      []PingResourceConfig      `toml:"ping"`
+	Nagios    []NagiosResourceConfig    `toml:"nagios"`
+	Exec      []ExecResourceConfig      `toml:"exec"`
+	Heartbeat []HeartbeatResourceConfig `toml:"heartbeat"`
+	Group     []GroupResourceConfig     `toml:"group"`
+}
+
+// Names returns names of all resources in config order, including
+// duplicates
+func (c *ResourcesConfig) Names() []string {
+	var names []string
+	for _, r := range c.Http {
+		names = append(names, r.Name)
+	}
+	for _, r := range c.Ping {
+		names = append(names, r.Name)
+	}
+	for _, r := range c.Nagios {
+		names = append(names, r.Name)
+	}
+	for _, r := range c.Exec {
+		names = append(names, r.Name)
+	}
+	for _, r := range c.Heartbeat {
+		names = append(names, r.Name)
+	}
+	for _, r := range c.Group {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// Configs returns configs of all resources, keyed by resource name
+func (c *ResourcesConfig) Configs() map[string]any {
+	configs := make(map[string]any)
+	for _, r := range c.Http {
+		configs[r.Name] = r
+	}
+	for _, r := range c.Ping {
+		configs[r.Name] = r
+	}
+	for _, r := range c.Nagios {
+		configs[r.Name] = r
+	}
+	for _, r := range c.Exec {
+		configs[r.Name] = r
+	}
+	for _, r := range c.Heartbeat {
+		configs[r.Name] = r
+	}
+	// group is changed if any of its members is changed, as it checks
+	// members itself
+	groups := make(map[string]GroupResourceConfig)
+	for _, r := range c.Group {
+		groups[r.Name] = r
+	}
+	var groupConfig func(r GroupResourceConfig, visited []string) []any
+	groupConfig = func(r GroupResourceConfig, visited []string) []any {
+		config := []any{r}
+		for _, member := range r.Members {
+			if g, ok := groups[member]; ok && !slices.Contains(visited, member) {
+				config = append(config, groupConfig(g, append(visited, member)))
+			} else {
+				config = append(config, configs[member])
+			}
+		}
+		return config
+	}
+	for _, r := range c.Group {
+		configs[r.Name] = groupConfig(r, []string{r.Name})
+	}
+	return configs
 }
 
+// BuildResources creates resources from config. Errors of all resources
+// are returned together.
 func BuildResources(config *ResourcesConfig) ([]Resource, error) {
 	var buildedResources []Resource
+	var errs []error
 
 	for _, httpResourceConfig := range config.Http {
 		if err := httpResourceConfig.Validate(); err != nil {
-			return nil, fmt.Errorf("invalid http resource configuration: %w", err)
+			errs = append(errs, fmt.Errorf("invalid http resource configuration '%s': %w", httpResourceConfig.Name, err))
+			continue
 		}
 
 		httpResource := NewHTTPResource(httpResourceConfig)
-		slog.Info("Builded resource", "resource_name", httpResource.GetName())
 		buildedResources = append(buildedResources, httpResource)
 	}
 
 	for _, pingResourceConfig := range config.Ping {
 		if err := pingResourceConfig.Validate(); err != nil {
-			return nil, fmt.Errorf("invalid ping resource configuration: %w", err)
+			errs = append(errs, fmt.Errorf("invalid ping resource configuration '%s': %w", pingResourceConfig.Name, err))
+			continue
 		}
 
 		pingResource := NewPingResource(pingResourceConfig)
-		slog.Info("Builded resource", "resource_name", pingResource.GetName())
 		buildedResources = append(buildedResources, pingResource)
 	}
 
+	for _, nagiosResourceConfig := range config.Nagios {
+		if err := nagiosResourceConfig.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid nagios resource configuration '%s': %w", nagiosResourceConfig.Name, err))
+			continue
+		}
+
+		nagiosResource := NewNagiosResource(nagiosResourceConfig)
+		buildedResources = append(buildedResources, nagiosResource)
+	}
+
+	for _, execResourceConfig := range config.Exec {
+		if err := execResourceConfig.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid exec resource configuration '%s': %w", execResourceConfig.Name, err))
+			continue
+		}
+
+		execResource := NewExecResource(execResourceConfig)
+		buildedResources = append(buildedResources, execResource)
+	}
+
+	var tokens []string
+	for _, heartbeatResourceConfig := range config.Heartbeat {
+		if err := heartbeatResourceConfig.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid heartbeat resource configuration '%s': %w", heartbeatResourceConfig.Name, err))
+			continue
+		}
+		if slices.Contains(tokens, heartbeatResourceConfig.Token) {
+			errs = append(errs, fmt.Errorf("invalid heartbeat resource configuration '%s': %w", heartbeatResourceConfig.Name, HeartbeatResourceDuplicateTokenError))
+			continue
+		}
+		tokens = append(tokens, heartbeatResourceConfig.Token)
+
+		heartbeatResource := NewHeartbeatResource(heartbeatResourceConfig)
+		buildedResources = append(buildedResources, heartbeatResource)
+	}
+
+	for _, groupResourceConfig := range config.Group {
+		if err := groupResourceConfig.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid group resource configuration '%s': %w", groupResourceConfig.Name, err))
+		}
+	}
+	if err := config.ValidateGroups(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid resource groups: %w", err))
+	}
+
+	var names []string
+	for _, name := range config.Names() {
+		if slices.Contains(names, name) {
+			errs = append(errs, fmt.Errorf("resource '%s': %w", name, ResourceDuplicateNameError))
+		}
+		names = append(names, name)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	// groups are built after their members, members are checked for cycles
+	// above
+	nameToResource := make(map[string]Resource)
+	for _, r := range buildedResources {
+		nameToResource[r.GetName()] = r
+	}
+	groupConfigs := make(map[string]GroupResourceConfig)
+	for _, g := range config.Group {
+		groupConfigs[g.Name] = g
+	}
+	var buildGroup func(config GroupResourceConfig) Resource
+	buildGroup = func(config GroupResourceConfig) Resource {
+		if r, exists := nameToResource[config.Name]; exists {
+			return r
+		}
+		var members []Resource
+		for _, member := range config.Members {
+			r, exists := nameToResource[member]
+			if !exists {
+				r = buildGroup(groupConfigs[member])
+			}
+			members = append(members, r)
+		}
+		r := NewGroupResource(config, members)
+		nameToResource[config.Name] = r
+		return r
+	}
+	for _, groupResourceConfig := range config.Group {
+		buildedResources = append(buildedResources, buildGroup(groupResourceConfig))
+	}
+
+	if err := ValidateDependencies(buildedResources); err != nil {
+		return nil, fmt.Errorf("invalid resource dependencies: %w", err)
+	}
+
+	for _, r := range buildedResources {
+		slog.Info("Builded resource", "resource_name", r.GetName())
+	}
 	return buildedResources, nil
 }
@@ -0,0 +1,29 @@
+package resources
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuildResources_Errors(t *testing.T) {
+	config := &ResourcesConfig{
+		Http: []HttpResourceConfig{
+			{Name: "api", Url: "https://api.example.com"},
+			{Name: "web"},
+		},
+		Ping: []PingResourceConfig{
+			{Name: "api", Address: "api.example.com", TimeoutSeconds: 5},
+			{Name: "db", Address: "db.example.com"},
+		},
+	}
+
+	resources, err := BuildResources(config)
+	if err == nil || resources != nil {
+		t.Fatal("Expected invalid resources to be rejected")
+	}
+	for _, expected := range []error{HTTPResourceURLEmptyError, PingResourceZeroTimeoutError, ResourceDuplicateNameError} {
+		if !errors.Is(err, expected) {
+			t.Errorf("Expected %v in %v", expected, err)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error variables for resource dependencies validation
+var (
+	ResourceUnknownDependencyError = errors.New("unknown resource in depends_on")
+	ResourceDependencyCycleError   = errors.New("dependency cycle")
+	ResourceUnknownMemberError     = errors.New("unknown resource in members")
+	ResourceMembersCycleError      = errors.New("group members cycle")
+)
+
+// ValidateDependencies checks that resources depend on existing resources
+// and the dependency graph has no cycles
+func ValidateDependencies(resources []Resource) error {
+	var names []string
+	dependencies := make(map[string][]string)
+	for _, r := range resources {
+		names = append(names, r.GetName())
+		dependencies[r.GetName()] = r.GetDependencies()
+	}
+	return validateDependencies(names, dependencies)
+}
+
+// ValidateDependencies checks dependencies of configured resources, like
+// ValidateDependencies does for built ones
+func (c *ResourcesConfig) ValidateDependencies() error {
+	var names []string
+	dependencies := make(map[string][]string)
+	for _, r := range c.Http {
+		names = append(names, r.Name)
+		dependencies[r.Name] = r.DependsOn
+	}
+	for _, r := range c.Ping {
+		names = append(names, r.Name)
+		dependencies[r.Name] = r.DependsOn
+	}
+	for _, r := range c.Nagios {
+		names = append(names, r.Name)
+		dependencies[r.Name] = r.DependsOn
+	}
+	for _, r := range c.Exec {
+		names = append(names, r.Name)
+		dependencies[r.Name] = r.DependsOn
+	}
+	for _, r := range c.Heartbeat {
+		names = append(names, r.Name)
+		dependencies[r.Name] = r.DependsOn
+	}
+	for _, r := range c.Group {
+		names = append(names, r.Name)
+		dependencies[r.Name] = r.DependsOn
+	}
+	return validateDependencies(names, dependencies)
+}
+
+// validateDependencies checks the graph of resource names in order to
+// their dependencies
+func validateDependencies(names []string, dependencies map[string][]string) error {
+	for _, name := range names {
+		for _, parent := range dependencies[name] {
+			if _, exists := dependencies[parent]; !exists {
+				return fmt.Errorf("resource '%s' depends on '%s': %w", name, parent, ResourceUnknownDependencyError)
+			}
+		}
+	}
+
+	if cycle := findCycle(names, dependencies); cycle != nil {
+		return fmt.Errorf("%s: %w", strings.Join(cycle, " -> "), ResourceDependencyCycleError)
+	}
+	return nil
+}
+
+// findCycle returns the first cycle found in the graph of resource names,
+// e.g. [a b a], or nil if the graph has no cycles
+func findCycle(names []string, edges map[string][]string) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make(map[string]int)
+	var path []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch marks[name] {
+		case visited:
+			return nil
+		case visiting:
+			// path holds the chain from the first visited resource, cut it
+			// to start from the repeated one
+			start := 0
+			for i, n := range path {
+				if n == name {
+					start = i
+					break
+				}
+			}
+			return append(path[start:], name)
+		}
+
+		marks[name] = visiting
+		path = append(path, name)
+		for _, next := range edges[name] {
+			if cycle := visit(next); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		marks[name] = visited
+		return nil
+	}
+
+	for _, name := range names {
+		if cycle := visit(name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// ValidateGroups checks that groups consist of existing resources and
+// don't contain themselves, directly or through other groups
+func (c *ResourcesConfig) ValidateGroups() error {
+	exists := make(map[string]bool)
+	for _, name := range c.Names() {
+		exists[name] = true
+	}
+
+	var names []string
+	members := make(map[string][]string)
+	for _, g := range c.Group {
+		for _, member := range g.Members {
+			if !exists[member] {
+				return fmt.Errorf("group '%s' has member '%s': %w", g.Name, member, ResourceUnknownMemberError)
+			}
+		}
+		names = append(names, g.Name)
+		members[g.Name] = g.Members
+	}
+
+	if cycle := findCycle(names, members); cycle != nil {
+		return fmt.Errorf("%s: %w", strings.Join(cycle, " -> "), ResourceMembersCycleError)
+	}
+	return nil
+}
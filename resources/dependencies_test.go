@@ -0,0 +1,45 @@
+package resources
+
+import (
+	"errors"
+	"testing"
+)
+
+func pingWithDependencies(name string, dependsOn ...string) Resource {
+	return NewPingResource(PingResourceConfig{Name: name, Address: "127.0.0.1", DependsOn: dependsOn})
+}
+
+func TestValidateDependencies(t *testing.T) {
+	valid := []Resource{
+		pingWithDependencies("gateway"),
+		pingWithDependencies("api", "gateway"),
+		pingWithDependencies("web", "api", "gateway"),
+	}
+	if err := ValidateDependencies(valid); err != nil {
+		t.Errorf("Expected valid dependencies, got %v", err)
+	}
+
+	unknown := []Resource{pingWithDependencies("api", "gateway")}
+	if err := ValidateDependencies(unknown); !errors.Is(err, ResourceUnknownDependencyError) {
+		t.Errorf("Expected unknown dependency error, got %v", err)
+	}
+
+	cycle := []Resource{
+		pingWithDependencies("gateway"),
+		pingWithDependencies("api", "gateway", "web"),
+		pingWithDependencies("web", "db"),
+		pingWithDependencies("db", "api"),
+	}
+	err := ValidateDependencies(cycle)
+	if !errors.Is(err, ResourceDependencyCycleError) {
+		t.Fatalf("Expected dependency cycle error, got %v", err)
+	}
+	if err.Error() != "api -> web -> db -> api: dependency cycle" {
+		t.Errorf("Expected cycle path in error, got %q", err.Error())
+	}
+
+	self := []Resource{pingWithDependencies("api", "api")}
+	if err := ValidateDependencies(self); !errors.Is(err, ResourceDependencyCycleError) {
+		t.Errorf("Expected self dependency to be a cycle, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+const (
+	execDefaultTimeout = 30 * time.Second
+	// execMaxOutput limits size of stdout and stderr in check details
+	execMaxOutput = 4096
+)
+
+// ExecOutput is a result, which command prints to stdout if json_output is
+// set:
+//
+//	{"state": "fail", "message": "last backup is 30 hours old", "metrics": {"age_hours": 30}}
+//
+// All fields are optional, state is either "ok" or "fail".
+type ExecOutput struct {
+	State   string             `json:"state"`
+	Message string             `json:"message"`
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+// ExecResource runs a command, the resource is available if the command
+// exits with code 0
+type ExecResource struct {
+	config ExecResourceConfig
+}
+
+// GetName implements Resource.
+func (e ExecResource) GetName() string {
+	return e.config.Name
+}
+
+func (e ExecResource) GetType() string {
+	return "exec"
+}
+
+// GetLabels implements Resource.
+func (e ExecResource) GetLabels() map[string]string {
+	return e.config.Labels
+}
+
+// GetDependencies implements Resource.
+func (e ExecResource) GetDependencies() []string {
+	return e.config.DependsOn
+}
+
+// RunCheck implements Resource.
+func (e ExecResource) RunCheck() (bool, []status.CheckDetails) {
+	env := []string{"AVALIO_RESOURCE_NAME=" + e.config.Name}
+	for _, name := range slices.Sorted(maps.Keys(e.config.Env)) {
+		env = append(env, name+"="+e.config.Env[name])
+	}
+	timeout := time.Duration(e.config.TimeoutSeconds) * time.Second
+	result := command{
+		path:      e.config.Command,
+		args:      e.config.Args,
+		env:       env,
+		dir:       e.config.WorkingDir,
+		timeout:   timeout,
+		maxOutput: execMaxOutput,
+	}.run()
+
+	var details []status.CheckDetails
+	available := true
+	switch {
+	case result.timedOut:
+		available = false
+		details = append(details, status.NewCheckDetails(status.Msg(status.MsgReason), status.Msg(status.MsgCommandTimedOut, timeout.String())))
+	case result.err != nil:
+		available = false
+		details = append(details, status.NewCheckDetails(status.Msg(status.MsgOriginalError), status.Text(result.err.Error())))
+	case result.exitCode != 0:
+		available = false
+		details = append(details, status.NewCheckDetails(status.Msg(status.MsgReason), status.Msg(status.MsgCommandFailed, result.exitCode)))
+	}
+
+	stdout := strings.TrimSpace(result.stdout)
+	if e.config.JSONOutput && result.err == nil {
+		output, err := parseExecOutput(stdout)
+		switch {
+		case err != nil && available:
+			available = false
+			details = append(details, status.NewCheckDetails(status.Msg(status.MsgReason), status.Msg(status.MsgInvalidOutput, err.Error())))
+		case err != nil:
+			// failed command may print an error instead of the result, it is
+			// shown as is
+		default:
+			if output.State == "fail" {
+				if available {
+					details = append(details, status.NewCheckDetails(status.Msg(status.MsgReason), status.Msg(status.MsgCommandReported)))
+				}
+				available = false
+			}
+			if output.Message != "" {
+				details = append(details, status.NewCheckDetails(status.Msg(status.MsgMessage), status.Text(output.Message)))
+			}
+			for _, name := range slices.Sorted(maps.Keys(output.Metrics)) {
+				details = append(details, status.NewMeasurementDetails(status.Measurement{Label: name, Value: output.Metrics[name]}))
+			}
+			// stdout is shown only if it isn't the parsed result
+			stdout = ""
+		}
+	}
+
+	if available {
+		return true, details
+	}
+	if stdout != "" {
+		details = append(details, status.NewCheckDetails(status.Msg(status.MsgOutput), status.Text(stdout)))
+	}
+	if stderr := strings.TrimSpace(result.stderr); stderr != "" {
+		details = append(details, status.NewCheckDetails(status.Msg(status.MsgErrorOutput), status.Text(stderr)))
+	}
+	return false, details
+}
+
+func parseExecOutput(stdout string) (ExecOutput, error) {
+	var output ExecOutput
+	if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+		return output, err
+	}
+	switch output.State {
+	case "", "ok", "fail":
+		return output, nil
+	default:
+		return output, fmt.Errorf("state must be ok or fail, got '%s'", output.State)
+	}
+}
+
+func NewExecResource(config ExecResourceConfig) ExecResource {
+	if config.TimeoutSeconds == 0 {
+		config.TimeoutSeconds = int(execDefaultTimeout.Seconds())
+	}
+	return ExecResource{config: config}
+}
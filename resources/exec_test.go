@@ -0,0 +1,68 @@
+package resources
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+func TestExecResource_RunCheck(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands are run with sh")
+	}
+
+	cases := []struct {
+		name       string
+		script     string
+		jsonOutput bool
+		available  bool
+		details    []string
+	}{
+		{"success", "echo ok", false, true, nil},
+		{"failure", "echo 'disk is full' >&2; exit 3", false, false, []string{"Command exited with code 3", "disk is full"}},
+		{"env", `test "$CHECK_DIR" = "$(pwd)" && test "$AVALIO_RESOURCE_NAME" = "script"`, false, true, nil},
+		{"json ok", `echo '{"state": "ok", "metrics": {"age_hours": 3.5}}'`, true, true, []string{"3.5"}},
+		{"json fail", `echo '{"state": "fail", "message": "backup is old"}'`, true, false, []string{"Command reported failure", "backup is old"}},
+		{"invalid json", "echo done", true, false, []string{"Invalid JSON output"}},
+		{"failure with text", "echo 'no backups'; exit 1", true, false, []string{"Command exited with code 1", "no backups"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			resource := NewExecResource(ExecResourceConfig{
+				Name:       "script",
+				Command:    "sh",
+				Args:       []string{"-c", c.script},
+				Env:        map[string]string{"CHECK_DIR": dir},
+				WorkingDir: dir,
+				JSONOutput: c.jsonOutput,
+			})
+
+			available, details := resource.RunCheck()
+			if available != c.available {
+				t.Errorf("Expected available %v, got %v, details %+v", c.available, available, details)
+			}
+			var text []string
+			for _, detail := range details {
+				text = append(text, detail.TitleIn(status.LanguageEnglish)+": "+detail.DescriptionIn(status.LanguageEnglish))
+			}
+			for _, expected := range c.details {
+				if !strings.Contains(strings.Join(text, "\n"), expected) {
+					t.Errorf("Expected details to contain %q, got %q", expected, text)
+				}
+			}
+		})
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	buffer := &limitedBuffer{limit: 5}
+	buffer.Write([]byte("abc"))
+	buffer.Write([]byte("defgh"))
+	buffer.Write([]byte("ijk"))
+	if got := buffer.String(); got != "abcde..." {
+		t.Errorf("Expected truncated output, got %q", got)
+	}
+}
@@ -0,0 +1,94 @@
+package resources
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+// GroupResource checks its members concurrently and is available if
+// enough of them are available, e.g. a majority of service replicas
+type GroupResource struct {
+	config  GroupResourceConfig
+	members []Resource
+}
+
+// GetName implements Resource.
+func (g GroupResource) GetName() string {
+	return g.config.Name
+}
+
+func (g GroupResource) GetType() string {
+	return "group"
+}
+
+// GetLabels implements Resource.
+func (g GroupResource) GetLabels() map[string]string {
+	return g.config.Labels
+}
+
+// GetDependencies implements Resource.
+func (g GroupResource) GetDependencies() []string {
+	return g.config.DependsOn
+}
+
+// Members returns resources checked by the group
+func (g GroupResource) Members() []Resource {
+	return g.members
+}
+
+// RunCheck implements Resource.
+func (g GroupResource) RunCheck() (bool, []status.CheckDetails) {
+	type memberResult struct {
+		available bool
+		details   []status.CheckDetails
+	}
+	results := make([]memberResult, len(g.members))
+
+	var wg sync.WaitGroup
+	for i, member := range g.members {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			available, details := member.RunCheck()
+			results[i] = memberResult{available: available, details: details}
+		}()
+	}
+	wg.Wait()
+
+	var failed []string
+	var failedDetails []status.CheckDetails
+	for i, result := range results {
+		if result.available {
+			continue
+		}
+		name := g.members[i].GetName()
+		failed = append(failed, name)
+
+		// the first details are the reason of failure
+		reason := status.NewCheckDetails(status.Text(name), status.Msg(status.MsgMemberFailed))
+		if len(result.details) > 0 {
+			reason = result.details[0].WithTitle(status.Text(name))
+		}
+		failedDetails = append(failedDetails, reason)
+	}
+
+	availableCount := len(g.members) - len(failed)
+	required := g.config.required()
+	if availableCount >= required {
+		return true, nil
+	}
+
+	details := []status.CheckDetails{
+		status.NewCheckDetails(status.Msg(status.MsgReason), status.Msg(status.MsgGroupNotEnough, availableCount, len(g.members), required)),
+		status.NewCheckDetails(status.Msg(status.MsgFailedMembers), status.Text(strings.Join(failed, ", "))),
+	}
+	return false, append(details, failedDetails...)
+}
+
+// NewGroupResource creates resource, config is expected to be validated
+// and members to be in the order of config members
+func NewGroupResource(config GroupResourceConfig, members []Resource) GroupResource {
+	return GroupResource{config: config, members: members}
+}
@@ -0,0 +1,120 @@
+package resources
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+type stubResource struct {
+	name      string
+	available bool
+}
+
+func (r stubResource) GetName() string              { return r.name }
+func (r stubResource) GetType() string              { return "stub" }
+func (r stubResource) GetLabels() map[string]string { return nil }
+func (r stubResource) GetDependencies() []string    { return nil }
+
+func (r stubResource) RunCheck() (bool, []status.CheckDetails) {
+	if r.available {
+		return true, nil
+	}
+	return false, []status.CheckDetails{
+		status.NewCheckDetails(status.Msg(status.MsgReason), status.Msg(status.MsgConnectionError)),
+	}
+}
+
+func TestGroupResource_RunCheck(t *testing.T) {
+	members := []Resource{
+		stubResource{name: "api-1", available: true},
+		stubResource{name: "api-2", available: false},
+		stubResource{name: "api-3", available: true},
+		stubResource{name: "api-4", available: false},
+	}
+	names := []string{"api-1", "api-2", "api-3", "api-4"}
+
+	cases := []struct {
+		config    GroupResourceConfig
+		available bool
+	}{
+		{GroupResourceConfig{Mode: GroupModeAll}, false},
+		{GroupResourceConfig{}, false},
+		{GroupResourceConfig{Mode: GroupModeAny}, true},
+		{GroupResourceConfig{Mode: GroupModeQuorum, Quorum: 2}, true},
+		{GroupResourceConfig{Mode: GroupModeQuorum, Quorum: 3}, false},
+		{GroupResourceConfig{Mode: GroupModePercent, Percent: 50}, true},
+		{GroupResourceConfig{Mode: GroupModePercent, Percent: 51}, false},
+	}
+	for _, c := range cases {
+		c.config.Name = "api"
+		c.config.Members = names
+		if err := c.config.Validate(); err != nil {
+			t.Fatalf("Expected %+v to be valid, got %v", c.config, err)
+		}
+
+		available, _ := NewGroupResource(c.config, members).RunCheck()
+		if available != c.available {
+			t.Errorf("Expected available = %v for mode %q, got %v", c.available, c.config.Mode, available)
+		}
+	}
+
+	_, details := NewGroupResource(GroupResourceConfig{Name: "api", Members: names}, members).RunCheck()
+	var text []string
+	for _, d := range details {
+		text = append(text, d.TitleIn(status.LanguageEnglish)+": "+d.DescriptionIn(status.LanguageEnglish))
+	}
+	expected := []string{
+		"Reason: 2 of 4 members are available, 4 required",
+		"Failed members: api-2, api-4",
+		"api-2: Connection error",
+		"api-4: Connection error",
+	}
+	if !slices.Equal(text, expected) {
+		t.Errorf("Expected details %q, got %q", expected, text)
+	}
+}
+
+func TestBuildResources_Groups(t *testing.T) {
+	config := &ResourcesConfig{
+		Exec: []ExecResourceConfig{
+			{Name: "api-1", Command: "true"},
+			{Name: "api-2", Command: "true"},
+		},
+		Group: []GroupResourceConfig{
+			{Name: "all", Members: []string{"api", "api-2"}, Mode: GroupModeAny},
+			{Name: "api", Members: []string{"api-1", "api-2"}, Mode: GroupModeQuorum, Quorum: 2},
+		},
+	}
+	built, err := BuildResources(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	group, ok := built[len(built)-2].(GroupResource)
+	if !ok || group.GetName() != "all" {
+		t.Fatalf("Expected group 'all', got %v", built[len(built)-2])
+	}
+	members := group.Members()
+	if nested, ok := members[0].(GroupResource); !ok || len(nested.Members()) != 2 {
+		t.Errorf("Expected nested group 'api' to be built with members, got %v", members[0])
+	}
+
+	config.Group[1].Members = []string{"api-1", "all"}
+	if _, err := BuildResources(config); !errors.Is(err, ResourceMembersCycleError) || !strings.Contains(err.Error(), "all -> api -> all") {
+		t.Errorf("Expected members cycle, got %v", err)
+	}
+
+	config.Group[1].Members = []string{"api-1", "db"}
+	if _, err := BuildResources(config); !errors.Is(err, ResourceUnknownMemberError) {
+		t.Errorf("Expected unknown member, got %v", err)
+	}
+
+	config.Group[1].Members = []string{"api-1", "api-2"}
+	config.Group[1].Quorum = 3
+	if _, err := BuildResources(config); !errors.Is(err, GroupResourceQuorumRangeError) {
+		t.Errorf("Expected quorum range error, got %v", err)
+	}
+}
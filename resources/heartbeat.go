@@ -0,0 +1,186 @@
+package resources
+
+import (
+	"sync"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// heartbeatMaxBody limits size of the ping body kept as the job log
+	heartbeatMaxBody      = 10 * 1024
+	heartbeatDefaultGrace = time.Minute
+)
+
+// HeartbeatSignal is a kind of ping sent by a job
+type HeartbeatSignal int
+
+const (
+	// HeartbeatSuccess is sent when the job has succeeded
+	HeartbeatSuccess HeartbeatSignal = iota
+	// HeartbeatStart is sent when the job starts, so too long runs are
+	// detected
+	HeartbeatStart
+	// HeartbeatFail is sent when the job has failed
+	HeartbeatFail
+)
+
+// HeartbeatResource is a push-based check: a job pings avalio, and the
+// resource fails if pings stop coming in time or the job reports failure
+type HeartbeatResource struct {
+	config   HeartbeatResourceConfig
+	schedule cron.Schedule
+	location *time.Location
+	state    *heartbeatState
+}
+
+// heartbeatState is the last pings, which are received by the API server
+type heartbeatState struct {
+	mu        sync.Mutex
+	createdAt time.Time
+	lastPing  time.Time
+	lastStart time.Time
+	lastFail  time.Time
+	failBody  string
+}
+
+// GetName implements Resource.
+func (h HeartbeatResource) GetName() string {
+	return h.config.Name
+}
+
+func (h HeartbeatResource) GetType() string {
+	return "heartbeat"
+}
+
+// GetLabels implements Resource.
+func (h HeartbeatResource) GetLabels() map[string]string {
+	return h.config.Labels
+}
+
+// GetDependencies implements Resource.
+func (h HeartbeatResource) GetDependencies() []string {
+	return h.config.DependsOn
+}
+
+// Token returns token, which identifies the resource in ping URLs
+func (h HeartbeatResource) Token() string {
+	return h.config.Token
+}
+
+// Ping records signal of the job, body is kept as the job log of failure
+func (h HeartbeatResource) Ping(signal HeartbeatSignal, body string, now time.Time) {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+
+	switch signal {
+	case HeartbeatSuccess:
+		h.state.lastPing = now
+	case HeartbeatStart:
+		h.state.lastStart = now
+	case HeartbeatFail:
+		h.state.lastFail = now
+		if len(body) > heartbeatMaxBody {
+			body = body[:heartbeatMaxBody] + "..."
+		}
+		h.state.failBody = body
+	}
+}
+
+// RunCheck implements Resource.
+func (h HeartbeatResource) RunCheck() (bool, []status.CheckDetails) {
+	return h.check(time.Now())
+}
+
+func (h HeartbeatResource) check(now time.Time) (bool, []status.CheckDetails) {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+
+	state := h.state
+	lastPing := state.lastPing
+	grace := time.Duration(h.config.GraceSeconds) * time.Second
+	if grace == 0 {
+		grace = heartbeatDefaultGrace
+	}
+	lastPingDetails := status.NewCheckDetails(status.Msg(status.MsgLastPing), status.Msg(status.MsgNever))
+	if !lastPing.IsZero() {
+		lastPingDetails = status.NewCheckDetails(status.Msg(status.MsgLastPing), status.Text(formatTime(lastPing)))
+	}
+
+	// the latest signal decides, the job may succeed after a failure
+	if state.lastFail.After(lastPing) {
+		details := []status.CheckDetails{
+			status.NewCheckDetails(status.Msg(status.MsgReason), status.Msg(status.MsgHeartbeatFailed, formatTime(state.lastFail))),
+			lastPingDetails,
+		}
+		if state.failBody != "" {
+			details = append(details, status.NewCheckDetails(status.Msg(status.MsgOutput), status.Text(state.failBody)))
+		}
+		return false, details
+	}
+
+	if state.lastStart.After(lastPing) && now.Sub(state.lastStart) > grace {
+		return false, []status.CheckDetails{
+			status.NewCheckDetails(status.Msg(status.MsgReason), status.Msg(status.MsgHeartbeatRunning, formatTime(state.lastStart))),
+			lastPingDetails,
+		}
+	}
+
+	// until the first ping the job is expected since avalio start
+	since := lastPing
+	if since.IsZero() {
+		since = state.createdAt
+	}
+	if h.schedule != nil {
+		// the ping covers the latest run scheduled at or before it, or a
+		// bit later, as the job may ping just before its slot. The next
+		// run is expected after the covered one.
+		covered := since
+		if !lastPing.IsZero() {
+			covered = since.Add(grace)
+		}
+		expected := h.schedule.Next(covered.In(h.location))
+		if now.After(expected.Add(grace)) {
+			return false, []status.CheckDetails{
+				status.NewCheckDetails(status.Msg(status.MsgReason), status.Msg(status.MsgHeartbeatMissedRun, formatTime(expected))),
+				lastPingDetails,
+			}
+		}
+		return true, nil
+	}
+
+	period := time.Duration(h.config.PeriodSeconds) * time.Second
+	if now.After(since.Add(period + grace)) {
+		return false, []status.CheckDetails{
+			status.NewCheckDetails(status.Msg(status.MsgReason), status.Msg(status.MsgHeartbeatMissed, (period+grace).String())),
+			lastPingDetails,
+		}
+	}
+	return true, nil
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.DateTime)
+}
+
+// NewHeartbeatResource creates resource, config is expected to be
+// validated
+func NewHeartbeatResource(config HeartbeatResourceConfig) HeartbeatResource {
+	// schedule is checked in Validate
+	schedule, location, _ := config.compileSchedule()
+	return HeartbeatResource{
+		config:   config,
+		schedule: schedule,
+		location: location,
+		state:    &heartbeatState{createdAt: time.Now()},
+	}
+}
+
+// KeepState returns the resource with pings received by the previous one,
+// so they aren't lost on config reload
+func (h HeartbeatResource) KeepState(previous HeartbeatResource) HeartbeatResource {
+	h.state = previous.state
+	return h
+}
@@ -0,0 +1,132 @@
+package resources
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+func TestHeartbeatResourceConfig_Validate(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   HeartbeatResourceConfig
+		expected error
+	}{
+		{"period", HeartbeatResourceConfig{Name: "backup", Token: "b4ckup-token", PeriodSeconds: 3600}, nil},
+		{"cron", HeartbeatResourceConfig{Name: "backup", Token: "b4ckup-token", Cron: "0 3 * * *"}, nil},
+		{"no token", HeartbeatResourceConfig{Name: "backup", PeriodSeconds: 3600}, HeartbeatResourceTokenEmptyError},
+		{"short token", HeartbeatResourceConfig{Name: "backup", Token: "abc", PeriodSeconds: 3600}, HeartbeatResourceInvalidTokenError},
+		{"token with slash", HeartbeatResourceConfig{Name: "backup", Token: "b4ckup/token", PeriodSeconds: 3600}, HeartbeatResourceInvalidTokenError},
+		{"no schedule", HeartbeatResourceConfig{Name: "backup", Token: "b4ckup-token"}, HeartbeatResourceScheduleError},
+		{"both schedules", HeartbeatResourceConfig{Name: "backup", Token: "b4ckup-token", PeriodSeconds: 60, Cron: "0 3 * * *"}, HeartbeatResourceScheduleError},
+		{"negative grace", HeartbeatResourceConfig{Name: "backup", Token: "b4ckup-token", PeriodSeconds: 60, GraceSeconds: -1}, HeartbeatResourceNegativeGraceError},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.config.Validate(); !errors.Is(err, c.expected) {
+				t.Errorf("Expected %v, got %v", c.expected, err)
+			}
+		})
+	}
+
+	config := HeartbeatResourceConfig{Name: "backup", Token: "b4ckup-token", Cron: "0 3 * *"}
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "invalid cron") {
+		t.Errorf("Expected invalid cron error, got %v", err)
+	}
+}
+
+func TestHeartbeatResource_Period(t *testing.T) {
+	resource := NewHeartbeatResource(HeartbeatResourceConfig{
+		Name:          "backup",
+		Token:         "b4ckup-token",
+		PeriodSeconds: 3600,
+		GraceSeconds:  600,
+	})
+	start := resource.state.createdAt
+
+	if available, _ := resource.check(start.Add(time.Hour)); !available {
+		t.Error("Expected resource to be available until the first period ends")
+	}
+	assertHeartbeatFailure(t, resource, start.Add(2*time.Hour), "No ping for more than 1h10m0s")
+
+	resource.Ping(HeartbeatSuccess, "", start.Add(2*time.Hour))
+	if available, _ := resource.check(start.Add(3 * time.Hour)); !available {
+		t.Error("Expected resource to be available after ping")
+	}
+
+	resource.Ping(HeartbeatStart, "", start.Add(3*time.Hour))
+	if available, _ := resource.check(start.Add(3*time.Hour + 5*time.Minute)); !available {
+		t.Error("Expected started job to be available within grace")
+	}
+	assertHeartbeatFailure(t, resource, start.Add(3*time.Hour+15*time.Minute), "hasn't finished")
+
+	resource.Ping(HeartbeatFail, "disk is full", start.Add(3*time.Hour+20*time.Minute))
+	assertHeartbeatFailure(t, resource, start.Add(3*time.Hour+20*time.Minute), "Job reported failure", "disk is full")
+
+	resource.Ping(HeartbeatSuccess, "", start.Add(4*time.Hour))
+	if available, _ := resource.check(start.Add(4 * time.Hour)); !available {
+		t.Error("Expected resource to recover after successful ping")
+	}
+}
+
+func TestHeartbeatResource_Cron(t *testing.T) {
+	resource := NewHeartbeatResource(HeartbeatResourceConfig{
+		Name:         "backup",
+		Token:        "b4ckup-token",
+		Cron:         "0 3 * * *",
+		Timezone:     "UTC",
+		GraceSeconds: 3600,
+	})
+	resource.state.createdAt = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	if available, _ := resource.check(time.Date(2026, 10, 20, 3, 30, 0, 0, time.UTC)); !available {
+		t.Error("Expected resource to be available within grace of the scheduled run")
+	}
+	assertHeartbeatFailure(t, resource, time.Date(2026, 10, 20, 4, 30, 0, 0, time.UTC), "No ping after the run scheduled at 2026-10-20 03:00:00")
+
+	resource.Ping(HeartbeatSuccess, "", time.Date(2026, 10, 20, 4, 40, 0, 0, time.UTC))
+	if available, _ := resource.check(time.Date(2026, 10, 21, 3, 59, 0, 0, time.UTC)); !available {
+		t.Error("Expected resource to be available after ping")
+	}
+	assertHeartbeatFailure(t, resource, time.Date(2026, 10, 21, 4, 1, 0, 0, time.UTC), "2026-10-21 03:00:00")
+
+	// the job pings just before its slot, the run is not missed
+	resource.Ping(HeartbeatSuccess, "", time.Date(2026, 10, 22, 2, 59, 0, 0, time.UTC))
+	if available, _ := resource.check(time.Date(2026, 10, 22, 4, 30, 0, 0, time.UTC)); !available {
+		t.Error("Expected ping just before the scheduled run to cover it")
+	}
+	assertHeartbeatFailure(t, resource, time.Date(2026, 10, 23, 4, 1, 0, 0, time.UTC), "2026-10-23 03:00:00")
+}
+
+func TestHeartbeatResource_KeepState(t *testing.T) {
+	config := HeartbeatResourceConfig{Name: "backup", Token: "b4ckup-token", PeriodSeconds: 60}
+	previous := NewHeartbeatResource(config)
+	previous.Ping(HeartbeatFail, "", time.Now())
+
+	config.PeriodSeconds = 120
+	resource := NewHeartbeatResource(config).KeepState(previous)
+	if available, _ := resource.RunCheck(); available {
+		t.Error("Expected failure ping to be kept on reload")
+	}
+}
+
+func assertHeartbeatFailure(t *testing.T, resource HeartbeatResource, now time.Time, expected ...string) {
+	t.Helper()
+
+	available, details := resource.check(now)
+	if available {
+		t.Fatalf("Expected resource to be unavailable at %s", now)
+	}
+	var text []string
+	for _, d := range details {
+		text = append(text, d.DescriptionIn(status.LanguageEnglish))
+	}
+	for _, e := range expected {
+		if !strings.Contains(strings.Join(text, "\n"), e) {
+			t.Errorf("Expected %q in details %q", e, text)
+		}
+	}
+}
@@ -21,6 +21,16 @@ func (H HTTPResource) GetType() string {
 	return "http"
 }
 
+// GetLabels implements Resource.
+func (H HTTPResource) GetLabels() map[string]string {
+	return H.config.Labels
+}
+
+// GetDependencies implements Resource.
+func (H HTTPResource) GetDependencies() []string {
+	return H.config.DependsOn
+}
+
 func (H HTTPResource) RunCheck() (bool, []status.CheckDetails) {
 	// Use configured max retries, default to 3 if not set
 	maxRetries := H.config.MaxRetries
@@ -54,7 +64,7 @@ func (h HTTPResource) performCheck() (bool, []status.CheckDetails) {
 	resp, err := client.Head(h.config.Url)
 	if err != nil {
 		var checkErrors [1]status.CheckDetails
-		checkErrors[0] = status.NewCheckError("Причина", "Ошибка соединения")
+		checkErrors[0] = status.NewCheckDetails(status.Msg(status.MsgReason), status.Msg(status.MsgConnectionError))
 		return false, checkErrors[:]
 	}
 	defer resp.Body.Close()
@@ -62,9 +72,9 @@ func (h HTTPResource) performCheck() (bool, []status.CheckDetails) {
 	statusCode := resp.StatusCode
 	if statusCode != h.config.ExpectedStatus {
 		var checkErrors [3]status.CheckDetails
-		checkErrors[0] = status.NewCheckError("Причина", "Неожиданный статус ответа")
-		checkErrors[1] = status.NewCheckError("Статус ответа", strconv.Itoa(resp.StatusCode))
-		checkErrors[2] = status.NewCheckError("Ожидаемый статус ответа", strconv.Itoa(h.config.ExpectedStatus))
+		checkErrors[0] = status.NewCheckDetails(status.Msg(status.MsgReason), status.Msg(status.MsgUnexpectedStatus))
+		checkErrors[1] = status.NewCheckDetails(status.Msg(status.MsgResponseStatus), status.Text(strconv.Itoa(resp.StatusCode)))
+		checkErrors[2] = status.NewCheckDetails(status.Msg(status.MsgExpectedStatus), status.Text(strconv.Itoa(h.config.ExpectedStatus)))
 		return false, checkErrors[:]
 	}
 
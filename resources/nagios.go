@@ -0,0 +1,272 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewsapw/avalio/status"
+)
+
+// nagiosMaxOutput is the output size, which Nagios reads from plugins
+const nagiosMaxOutput = 8192
+
+// NagiosState is a state reported by Nagios plugin exit code
+type NagiosState int
+
+const (
+	NagiosOK       NagiosState = 0
+	NagiosWarning  NagiosState = 1
+	NagiosCritical NagiosState = 2
+	NagiosUnknown  NagiosState = 3
+)
+
+func (s NagiosState) String() string {
+	switch s {
+	case NagiosOK:
+		return "OK"
+	case NagiosWarning:
+		return "WARNING"
+	case NagiosCritical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// NagiosResult is a result of Nagios plugin run
+type NagiosResult struct {
+	State NagiosState
+	// Output is the first line of plugin output, LongOutput is the rest
+	Output     string
+	LongOutput string
+	PerfData   []status.Measurement
+	// Err is set if plugin couldn't be run or has timed out
+	Err error
+}
+
+type NagiosResource struct {
+	config NagiosResourceConfig
+}
+
+// GetName implements Resource.
+func (n NagiosResource) GetName() string {
+	return n.config.Name
+}
+
+func (n NagiosResource) GetType() string {
+	return "nagios"
+}
+
+// GetLabels implements Resource.
+func (n NagiosResource) GetLabels() map[string]string {
+	return n.config.Labels
+}
+
+// GetDependencies implements Resource.
+func (n NagiosResource) GetDependencies() []string {
+	return n.config.DependsOn
+}
+
+// RunCheck implements Resource. CRITICAL and UNKNOWN states are failures,
+// WARNING is a failure unless ignore_warning is set.
+func (n NagiosResource) RunCheck() (bool, []status.CheckDetails) {
+	result := n.Check()
+
+	details := []status.CheckDetails{
+		status.NewCheckDetails(status.Msg(status.MsgPluginState), status.Text(result.State.String())),
+	}
+	if output := strings.TrimSpace(result.Output + "\n" + result.LongOutput); output != "" {
+		details = append(details, status.NewCheckDetails(status.Msg(status.MsgOutput), status.Text(output)))
+	}
+	if result.Err != nil {
+		details = append(details, status.NewCheckDetails(status.Msg(status.MsgOriginalError), status.Text(result.Err.Error())))
+	}
+	for _, measurement := range result.PerfData {
+		details = append(details, status.NewMeasurementDetails(measurement))
+	}
+
+	switch result.State {
+	case NagiosOK:
+		return true, details
+	case NagiosWarning:
+		return n.config.IgnoreWarning, details
+	default:
+		return false, details
+	}
+}
+
+// Check runs the plugin, exit codes other than 0-3 and failures to run the
+// plugin are reported as UNKNOWN
+func (n NagiosResource) Check() NagiosResult {
+	run := command{
+		path:      n.config.Command,
+		args:      n.config.Args,
+		timeout:   time.Duration(n.config.TimeoutSeconds) * time.Second,
+		maxOutput: nagiosMaxOutput,
+	}.run()
+
+	result := ParseNagiosOutput(run.stdout)
+	switch {
+	case run.err != nil:
+		result.State = NagiosUnknown
+		result.Err = run.err
+	case run.exitCode < int(NagiosOK) || run.exitCode > int(NagiosUnknown):
+		result.State = NagiosUnknown
+		result.Err = fmt.Errorf("plugin exited with code %d: %s", run.exitCode, strings.TrimSpace(run.stderr))
+	default:
+		result.State = NagiosState(run.exitCode)
+	}
+	return result
+}
+
+// ParseNagiosOutput splits plugin output into text and performance data:
+//
+//	DISK OK - free space: / 3326 MB (56%); | /=2643MB;5948;5958;0;5968
+//	/ 15272 MB (77%);
+//	/boot 68 MB (69%); | /boot=68MB;88;93;0;98
+//	/home=69357MB;253404;253409;0;253414
+//
+// Invalid performance data items are skipped.
+func ParseNagiosOutput(output string) NagiosResult {
+	var result NagiosResult
+	lines := strings.Split(strings.TrimRight(output, "\r\n"), "\n")
+
+	text, perfData, _ := strings.Cut(lines[0], "|")
+	result.Output = strings.TrimSpace(text)
+
+	// performance data of the long output starts after the first "|" and
+	// takes the rest of lines
+	var longOutput []string
+	inPerfData := false
+	for _, line := range lines[1:] {
+		if inPerfData {
+			perfData += " " + line
+			continue
+		}
+		text, data, found := strings.Cut(line, "|")
+		longOutput = append(longOutput, strings.TrimRight(text, "\r "))
+		if found {
+			perfData += " " + data
+			inPerfData = true
+		}
+	}
+	result.LongOutput = strings.TrimSpace(strings.Join(longOutput, "\n"))
+
+	result.PerfData, _ = ParsePerfData(perfData)
+	return result
+}
+
+var perfValueRegexp = regexp.MustCompile(`^([-+]?(?:[0-9]+\.?[0-9]*|\.[0-9]+)(?:[eE][-+]?[0-9]+)?)([a-zA-Z%]*)$`)
+
+// ParsePerfData parses space separated Nagios performance data items:
+//
+//	'label'=value[unit];[warning];[critical];[min];[max]
+//
+// Valid items are returned even if some of items are invalid. Items with
+// unknown value "U" are skipped.
+func ParsePerfData(perfData string) ([]status.Measurement, error) {
+	var measurements []status.Measurement
+	var errs []error
+
+	rest := strings.TrimSpace(perfData)
+	for rest != "" {
+		var label string
+		if strings.HasPrefix(rest, "'") {
+			// quoted labels may contain spaces, quotes are doubled
+			end := 1
+			for {
+				i := strings.Index(rest[end:], "'")
+				if i < 0 {
+					return measurements, errors.Join(append(errs, fmt.Errorf("label is not closed: %s", rest))...)
+				}
+				end += i + 1
+				if !strings.HasPrefix(rest[end:], "'") {
+					break
+				}
+				end++
+			}
+			label = strings.ReplaceAll(rest[1:end-1], "''", "'")
+			rest = rest[end:]
+		} else {
+			i := strings.IndexAny(rest, "= ")
+			if i < 0 {
+				i = len(rest)
+			}
+			label, rest = rest[:i], rest[i:]
+		}
+
+		item, remaining, _ := strings.Cut(rest, " ")
+		rest = strings.TrimSpace(remaining)
+		value, found := strings.CutPrefix(item, "=")
+		if !found || label == "" {
+			errs = append(errs, fmt.Errorf("invalid item '%s%s'", label, item))
+			continue
+		}
+
+		measurement, err := parsePerfValue(label, value)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if measurement != nil {
+			measurements = append(measurements, *measurement)
+		}
+	}
+	return measurements, errors.Join(errs...)
+}
+
+// parsePerfValue parses "value[unit];[warning];[critical];[min];[max]", it
+// returns nil for unknown value
+func parsePerfValue(label, value string) (*status.Measurement, error) {
+	fields := strings.Split(value, ";")
+	if fields[0] == "U" {
+		return nil, nil
+	}
+	match := perfValueRegexp.FindStringSubmatch(fields[0])
+	if match == nil {
+		return nil, fmt.Errorf("'%s' - invalid value '%s'", label, fields[0])
+	}
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("'%s' - invalid value '%s'", label, fields[0])
+	}
+
+	measurement := &status.Measurement{Label: label, Value: number, Unit: match[2]}
+	field := func(i int) string {
+		if i < len(fields) {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+	measurement.Warning = field(1)
+	measurement.Critical = field(2)
+	if measurement.Min, err = parsePerfLimit(label, field(3)); err != nil {
+		return nil, err
+	}
+	if measurement.Max, err = parsePerfLimit(label, field(4)); err != nil {
+		return nil, err
+	}
+	return measurement, nil
+}
+
+func parsePerfLimit(label, limit string) (*float64, error) {
+	if limit == "" {
+		return nil, nil
+	}
+	number, err := strconv.ParseFloat(limit, 64)
+	if err != nil {
+		return nil, fmt.Errorf("'%s' - invalid limit '%s'", label, limit)
+	}
+	return &number, nil
+}
+
+func NewNagiosResource(config NagiosResourceConfig) NagiosResource {
+	if config.TimeoutSeconds == 0 {
+		config.TimeoutSeconds = 30
+	}
+	return NagiosResource{config: config}
+}
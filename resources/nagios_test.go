@@ -0,0 +1,76 @@
+package resources
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestParseNagiosOutput(t *testing.T) {
+	output := "DISK OK - free space: / 3326 MB (56%); | /=2643MB;5948;5958;0;5968\n" +
+		"/ 15272 MB (77%);\n" +
+		"/boot 68 MB (69%); | /boot=68MB;88;93;0;98\n" +
+		"'/home dir'=69357MB;253404;253409;0;253414 swap=U;;;0\n"
+
+	result := ParseNagiosOutput(output)
+	if result.Output != "DISK OK - free space: / 3326 MB (56%);" {
+		t.Errorf("Unexpected output %q", result.Output)
+	}
+	if result.LongOutput != "/ 15272 MB (77%);\n/boot 68 MB (69%);" {
+		t.Errorf("Unexpected long output %q", result.LongOutput)
+	}
+
+	if len(result.PerfData) != 3 {
+		t.Fatalf("Expected 3 measurements, got %+v", result.PerfData)
+	}
+	home := result.PerfData[2]
+	if home.Label != "/home dir" || home.Value != 69357 || home.Unit != "MB" || home.Warning != "253404" || *home.Max != 253414 {
+		t.Errorf("Unexpected measurement %+v", home)
+	}
+	if got := home.PerfData(); got != "'/home dir'=69357MB;253404;253409;0;253414" {
+		t.Errorf("Expected perfdata to be formatted back, got %q", got)
+	}
+}
+
+func TestParsePerfData_Invalid(t *testing.T) {
+	measurements, err := ParsePerfData("load1=0.52;1;2 load5=high time=0.2s;;;0")
+	if err == nil {
+		t.Error("Expected error about invalid value")
+	}
+	if len(measurements) != 2 || measurements[1].Label != "time" || measurements[1].Unit != "s" {
+		t.Errorf("Expected valid measurements to be kept, got %+v", measurements)
+	}
+}
+
+func TestNagiosResource_RunCheck(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are run with sh")
+	}
+
+	cases := []struct {
+		script        string
+		ignoreWarning bool
+		available     bool
+		state         string
+	}{
+		{"echo 'OK | load1=0.1'", false, true, "OK"},
+		{"echo 'WARNING'; exit 1", false, false, "WARNING"},
+		{"echo 'WARNING'; exit 1", true, true, "WARNING"},
+		{"echo 'CRITICAL'; exit 2", true, false, "CRITICAL"},
+		{"exit 127", false, false, "UNKNOWN"},
+	}
+	for _, c := range cases {
+		resource := NewNagiosResource(NagiosResourceConfig{
+			Name:          "plugin",
+			Command:       "sh",
+			Args:          []string{"-c", c.script},
+			IgnoreWarning: c.ignoreWarning,
+		})
+		available, details := resource.RunCheck()
+		if available != c.available {
+			t.Errorf("%s: expected available %v, got %v", c.script, c.available, available)
+		}
+		if details[0].Description() != c.state {
+			t.Errorf("%s: expected state %s, got %s", c.script, c.state, details[0].Description())
+		}
+	}
+}
@@ -48,9 +48,9 @@ func Ping(ctx context.Context, host string, timeout time.Duration) PingResult {
 	output := strings.TrimSpace(string(out))
 	if err != nil {
 		if ctx.Err() != nil {
-			return PingResult{Error: fmt.Errorf("таймаут ожидания ответа: %w", ctx.Err())}
+			return PingResult{Error: fmt.Errorf("timeout waiting for reply: %w", ctx.Err())}
 		}
-		return PingResult{Error: fmt.Errorf("ping не прошел: %w: %s", err, output)}
+		return PingResult{Error: fmt.Errorf("ping failed: %w: %s", err, output)}
 	}
 
 	rtt := time.Duration(0)
@@ -86,15 +86,25 @@ func (P PingResource) GetType() string {
 	return "ping"
 }
 
+// GetLabels implements Resource.
+func (P PingResource) GetLabels() map[string]string {
+	return P.config.Labels
+}
+
+// GetDependencies implements Resource.
+func (P PingResource) GetDependencies() []string {
+	return P.config.DependsOn
+}
+
 func (P PingResource) RunCheck() (bool, []status.CheckDetails) {
 	const numAttempts = 3
 	const sleepDuration = time.Second * 1
 	for i := range numAttempts {
 		if _, err := isReachable(P.config.Address, time.Duration(P.config.TimeoutSeconds*int(time.Second))); err != nil {
 			var checkErrors [3]status.CheckDetails
-			checkErrors[0] = status.NewCheckError("Причина", "Ресурс по адресу недоступен")
-			checkErrors[1] = status.NewCheckError("Адрес", P.config.Address)
-			checkErrors[2] = status.NewCheckError("Исходная ошибка", err.Error())
+			checkErrors[0] = status.NewCheckDetails(status.Msg(status.MsgReason), status.Msg(status.MsgAddressUnreachable))
+			checkErrors[1] = status.NewCheckDetails(status.Msg(status.MsgAddress), status.Text(P.config.Address))
+			checkErrors[2] = status.NewCheckDetails(status.Msg(status.MsgOriginalError), status.Text(err.Error()))
 
 			if i == (numAttempts - 1) {
 				return false, checkErrors[:]
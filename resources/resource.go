@@ -5,5 +5,9 @@ import "github.com/andrewsapw/avalio/status"
 type Resource interface {
 	GetName() string
 	GetType() string
+	GetLabels() map[string]string
+	// GetDependencies returns names of resources, which must be available
+	// for this resource to be reachable
+	GetDependencies() []string
 	RunCheck() (bool, []status.CheckDetails)
 }
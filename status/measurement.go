@@ -0,0 +1,49 @@
+package status
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Measurement is a numeric value reported by a check, e.g. Nagios plugin
+// performance data. Warning and critical are thresholds in Nagios range
+// format, e.g. "10:20" or "@5".
+type Measurement struct {
+	Label    string   `json:"label"`
+	Value    float64  `json:"value"`
+	Unit     string   `json:"unit,omitempty"`
+	Warning  string   `json:"warning,omitempty"`
+	Critical string   `json:"critical,omitempty"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+}
+
+// String returns value with unit, e.g. "93.5%"
+func (m Measurement) String() string {
+	return strconv.FormatFloat(m.Value, 'f', -1, 64) + m.Unit
+}
+
+// PerfData formats measurement as Nagios performance data:
+//
+//	'label'=value[unit];[warning];[critical];[min];[max]
+func (m Measurement) PerfData() string {
+	label := m.Label
+	if strings.ContainsAny(label, " '=") {
+		label = "'" + strings.ReplaceAll(label, "'", "''") + "'"
+	}
+
+	formatLimit := func(limit *float64) string {
+		if limit == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*limit, 'f', -1, 64)
+	}
+	fields := []string{
+		strconv.FormatFloat(m.Value, 'f', -1, 64) + m.Unit,
+		m.Warning,
+		m.Critical,
+		formatLimit(m.Min),
+		formatLimit(m.Max),
+	}
+	return label + "=" + strings.TrimRight(strings.Join(fields, ";"), ";")
+}
@@ -0,0 +1,320 @@
+package status
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+type Language string
+
+const (
+	LanguageEnglish Language = "en"
+	LanguageRussian Language = "ru"
+)
+
+// defaultLanguage is used to render messages when no language is given,
+// e.g. in logs and JSON output. It may be changed on configuration reload.
+var defaultLanguage atomic.Value
+
+func init() {
+	defaultLanguage.Store(LanguageRussian)
+}
+
+// SetDefaultLanguage changes language used by Message.String
+func SetDefaultLanguage(language Language) {
+	defaultLanguage.Store(language)
+}
+
+func DefaultLanguage() Language {
+	return defaultLanguage.Load().(Language)
+}
+
+// ParseLanguage validates language code from config. Empty string
+// means default language.
+func ParseLanguage(code string) (Language, error) {
+	if code == "" {
+		return DefaultLanguage(), nil
+	}
+
+	language := Language(strings.ToLower(code))
+	if _, ok := catalogs[language]; !ok {
+		return "", fmt.Errorf("unsupported language '%s'", code)
+	}
+	return language, nil
+}
+
+// Message keys. Keys are stable and may be used in templates via the t function.
+const (
+	MsgReason         = "detail.reason"
+	MsgAddress        = "detail.address"
+	MsgOriginalError  = "detail.original_error"
+	MsgResponseStatus = "detail.response_status"
+	MsgExpectedStatus = "detail.expected_status"
+	MsgMaintenance    = "detail.maintenance"
+	MsgCanary         = "detail.canary"
+	MsgPluginState    = "detail.plugin_state"
+	MsgOutput         = "detail.output"
+	MsgErrorOutput    = "detail.error_output"
+	MsgMessage        = "detail.message"
+	MsgLastPing       = "detail.last_ping"
+	MsgNever          = "detail.never"
+	MsgFailedMembers  = "detail.failed_members"
+
+	MsgConnectionError    = "reason.connection_error"
+	MsgUnexpectedStatus   = "reason.unexpected_status"
+	MsgAddressUnreachable = "reason.address_unreachable"
+	MsgMaintenanceEnded   = "reason.maintenance_ended"
+	MsgHostOffline        = "reason.host_offline"
+	MsgTestNotification   = "reason.test_notification"
+	MsgCommandFailed      = "reason.command_failed"
+	MsgCommandTimedOut    = "reason.command_timed_out"
+	MsgCommandReported    = "reason.command_reported"
+	MsgInvalidOutput      = "reason.invalid_output"
+	MsgHeartbeatMissed    = "reason.heartbeat_missed"
+	MsgHeartbeatMissedRun = "reason.heartbeat_missed_run"
+	MsgHeartbeatRunning   = "reason.heartbeat_running"
+	MsgHeartbeatFailed    = "reason.heartbeat_failed"
+	MsgGroupNotEnough     = "reason.group_not_enough"
+	MsgMemberFailed       = "reason.member_failed"
+
+	MsgNotificationNotAvailable = "notification.not_available"
+	MsgNotificationRecovered    = "notification.recovered"
+	MsgNotificationCheckType    = "notification.check_type"
+
+	MsgNotificationStillNotAvailable = "notification.still_not_available"
+
+	MsgDigestNotAvailable = "digest.not_available"
+	MsgDigestRecovered    = "digest.recovered"
+
+	MsgBotHelp                 = "bot.help"
+	MsgBotUsageCheck           = "bot.usage_check"
+	MsgBotUsageMute            = "bot.usage_mute"
+	MsgBotUsageUnmute          = "bot.usage_unmute"
+	MsgBotUsageAck             = "bot.usage_ack"
+	MsgBotError                = "bot.error"
+	MsgBotNoResources          = "bot.no_resources"
+	MsgBotNoIncidents          = "bot.no_incidents"
+	MsgBotCheckAvailable       = "bot.check_available"
+	MsgBotMuted                = "bot.muted"
+	MsgBotMutedLabel           = "bot.muted_label"
+	MsgBotUnmuted              = "bot.unmuted"
+	MsgBotAcknowledged         = "bot.acknowledged"
+	MsgBotAckButton            = "bot.ack_button"
+	MsgBotIncidentOpen         = "bot.incident_open"
+	MsgBotIncidentAcknowledged = "bot.incident_acknowledged"
+	MsgBotIncidentResolved     = "bot.incident_resolved"
+)
+
+var catalogs = map[Language]map[string]string{
+	LanguageEnglish: {
+		MsgReason:         "Reason",
+		MsgAddress:        "Address",
+		MsgOriginalError:  "Original error",
+		MsgResponseStatus: "Response status",
+		MsgExpectedStatus: "Expected response status",
+		MsgMaintenance:    "Maintenance",
+		MsgCanary:         "Monitoring host",
+		MsgPluginState:    "Plugin state",
+		MsgOutput:         "Output",
+		MsgErrorOutput:    "Error output",
+		MsgMessage:        "Message",
+		MsgLastPing:       "Last ping",
+		MsgNever:          "never",
+		MsgFailedMembers:  "Failed members",
+
+		MsgConnectionError:    "Connection error",
+		MsgUnexpectedStatus:   "Unexpected response status",
+		MsgAddressUnreachable: "Resource is unreachable",
+		MsgMaintenanceEnded:   "Maintenance %s has ended, the resource is still not available",
+		MsgHostOffline:        "Network was unavailable for %s, resource alerts were suppressed",
+		MsgTestNotification:   "This is a test notification from avalio",
+		MsgCommandFailed:      "Command exited with code %d",
+		MsgCommandTimedOut:    "Command timed out after %s",
+		MsgCommandReported:    "Command reported failure",
+		MsgInvalidOutput:      "Invalid JSON output: %s",
+		MsgHeartbeatMissed:    "No ping for more than %s",
+		MsgHeartbeatMissedRun: "No ping after the run scheduled at %s",
+		MsgHeartbeatRunning:   "Job started at %s and hasn't finished",
+		MsgHeartbeatFailed:    "Job reported failure at %s",
+		MsgGroupNotEnough:     "%d of %d members are available, %d required",
+		MsgMemberFailed:       "Check failed",
+
+		MsgNotificationNotAvailable: "Resource %s is not available.",
+		MsgNotificationRecovered:    "Resource %s is available again.",
+		MsgNotificationCheckType:    "Check type: %s",
+
+		MsgNotificationStillNotAvailable: "Resource %s is still not available (%s).",
+
+		MsgDigestNotAvailable: "%d resources are not available:",
+		MsgDigestRecovered:    "%d resources are available again:",
+
+		MsgBotHelp: "Available commands:\n" +
+			"/status - state of all resources\n" +
+			"/check <resource> - check resource now\n" +
+			"/mute <resource> <duration> - mute notifications, e.g. /mute api 2h\n" +
+			"/unmute <resource> - unmute notifications\n" +
+			"/ack <resource> - acknowledge incident\n" +
+			"/incidents - open and recent incidents",
+		MsgBotUsageCheck:           "Usage: /check <resource>",
+		MsgBotUsageMute:            "Usage: /mute <resource> <duration>, e.g. /mute api 2h",
+		MsgBotUsageUnmute:          "Usage: /unmute <resource>",
+		MsgBotUsageAck:             "Usage: /ack <resource>",
+		MsgBotError:                "Error: %s",
+		MsgBotNoResources:          "No resources were checked yet",
+		MsgBotNoIncidents:          "No incidents",
+		MsgBotCheckAvailable:       "Resource %s is available.",
+		MsgBotMuted:                "Notifications about %s are muted until %s",
+		MsgBotMutedLabel:           "muted",
+		MsgBotUnmuted:              "Notifications about %s are unmuted",
+		MsgBotAcknowledged:         "Incident of %s is acknowledged by %s",
+		MsgBotAckButton:            "Acknowledge",
+		MsgBotIncidentOpen:         "#%d %s - not available since %s",
+		MsgBotIncidentAcknowledged: "(acknowledged by %s)",
+		MsgBotIncidentResolved:     "#%d %s - %s, downtime %s",
+	},
+	LanguageRussian: {
+		MsgReason:         "Причина",
+		MsgAddress:        "Адрес",
+		MsgOriginalError:  "Исходная ошибка",
+		MsgResponseStatus: "Статус ответа",
+		MsgExpectedStatus: "Ожидаемый статус ответа",
+		MsgMaintenance:    "Обслуживание",
+		MsgCanary:         "Хост мониторинга",
+		MsgPluginState:    "Состояние плагина",
+		MsgOutput:         "Вывод",
+		MsgErrorOutput:    "Вывод ошибок",
+		MsgMessage:        "Сообщение",
+		MsgLastPing:       "Последний сигнал",
+		MsgNever:          "не было",
+		MsgFailedMembers:  "Недоступные участники",
+
+		MsgConnectionError:    "Ошибка соединения",
+		MsgUnexpectedStatus:   "Неожиданный статус ответа",
+		MsgAddressUnreachable: "Ресурс по адресу недоступен",
+		MsgMaintenanceEnded:   "Обслуживание %s завершено, ресурс все еще недоступен",
+		MsgHostOffline:        "Сеть была недоступна %s, уведомления о ресурсах не отправлялись",
+		MsgTestNotification:   "Это тестовое уведомление avalio",
+		MsgCommandFailed:      "Команда завершилась с кодом %d",
+		MsgCommandTimedOut:    "Команда не завершилась за %s",
+		MsgCommandReported:    "Команда сообщила о сбое",
+		MsgInvalidOutput:      "Некорректный JSON в выводе: %s",
+		MsgHeartbeatMissed:    "Нет сигнала больше %s",
+		MsgHeartbeatMissedRun: "Нет сигнала после запуска по расписанию в %s",
+		MsgHeartbeatRunning:   "Задача запущена в %s и не завершилась",
+		MsgHeartbeatFailed:    "Задача сообщила о сбое в %s",
+		MsgGroupNotEnough:     "Доступно участников: %d из %d, требуется %d",
+		MsgMemberFailed:       "Проверка не пройдена",
+
+		MsgNotificationNotAvailable: "Ресурс %s недоступен.",
+		MsgNotificationRecovered:    "Ресурс %s снова доступен.",
+		MsgNotificationCheckType:    "Тип проверки: %s",
+
+		MsgNotificationStillNotAvailable: "Ресурс %s все еще недоступен (%s).",
+
+		MsgDigestNotAvailable: "Недоступны ресурсы (%d):",
+		MsgDigestRecovered:    "Снова доступны ресурсы (%d):",
+
+		MsgBotHelp: "Доступные команды:\n" +
+			"/status - состояние всех ресурсов\n" +
+			"/check <ресурс> - проверить ресурс сейчас\n" +
+			"/mute <ресурс> <длительность> - отключить уведомления, например /mute api 2h\n" +
+			"/unmute <ресурс> - включить уведомления\n" +
+			"/ack <ресурс> - подтвердить инцидент\n" +
+			"/incidents - открытые и недавние инциденты",
+		MsgBotUsageCheck:           "Использование: /check <ресурс>",
+		MsgBotUsageMute:            "Использование: /mute <ресурс> <длительность>, например /mute api 2h",
+		MsgBotUsageUnmute:          "Использование: /unmute <ресурс>",
+		MsgBotUsageAck:             "Использование: /ack <ресурс>",
+		MsgBotError:                "Ошибка: %s",
+		MsgBotNoResources:          "Ресурсы еще не проверялись",
+		MsgBotNoIncidents:          "Инцидентов нет",
+		MsgBotCheckAvailable:       "Ресурс %s доступен.",
+		MsgBotMuted:                "Уведомления о %s отключены до %s",
+		MsgBotMutedLabel:           "уведомления отключены",
+		MsgBotUnmuted:              "Уведомления о %s включены",
+		MsgBotAcknowledged:         "Инцидент %s подтвержден: %s",
+		MsgBotAckButton:            "Подтвердить",
+		MsgBotIncidentOpen:         "#%d %s - недоступен с %s",
+		MsgBotIncidentAcknowledged: "(подтвержден: %s)",
+		MsgBotIncidentResolved:     "#%d %s - %s, простой %s",
+	},
+}
+
+// Message is a localizable text: catalog key with fmt-style parameters.
+// Message without key is a plain text, which is not translated.
+type Message struct {
+	Key    string `json:"key,omitempty"`
+	Params []any  `json:"params,omitempty"`
+}
+
+// Msg creates message from catalog key
+func Msg(key string, params ...any) Message {
+	return Message{Key: key, Params: params}
+}
+
+// Text creates message with untranslated text, e.g. a value received
+// from the checked resource
+func Text(text string) Message {
+	return Message{Params: []any{text}}
+}
+
+// Render returns message text in the given language, falling back to
+// English and then to the key itself
+func (m Message) Render(language Language) string {
+	if m.Key == "" {
+		return fmt.Sprint(m.Params...)
+	}
+
+	format, ok := catalogs[language][m.Key]
+	if !ok {
+		format, ok = catalogs[LanguageEnglish][m.Key]
+	}
+	if !ok {
+		return m.Key
+	}
+
+	if len(m.Params) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, m.Params...)
+}
+
+// formatVerb matches fmt verbs in catalog messages
+var formatVerb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+// RenderEscaped renders message like Render, but escapes message text with
+// escape function. Parameters are inserted as is.
+func (m Message) RenderEscaped(language Language, escape func(string) string) string {
+	if m.Key == "" {
+		return escape(fmt.Sprint(m.Params...))
+	}
+
+	format, ok := catalogs[language][m.Key]
+	if !ok {
+		format, ok = catalogs[LanguageEnglish][m.Key]
+	}
+	if !ok {
+		return escape(m.Key)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range formatVerb.FindAllStringIndex(format, -1) {
+		b.WriteString(strings.ReplaceAll(escape(format[last:loc[0]]), "%", "%%"))
+		b.WriteString(format[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(strings.ReplaceAll(escape(format[last:]), "%", "%%"))
+
+	if len(m.Params) == 0 {
+		return strings.ReplaceAll(b.String(), "%%", "%")
+	}
+	return fmt.Sprintf(b.String(), m.Params...)
+}
+
+// String renders message in the default language
+func (m Message) String() string {
+	return m.Render(DefaultLanguage())
+}
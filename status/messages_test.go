@@ -0,0 +1,81 @@
+package status
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	for language, catalog := range catalogs {
+		for key, english := range catalogs[LanguageEnglish] {
+			text, ok := catalog[key]
+			if !ok {
+				t.Errorf("Key '%s' is missing in '%s' catalog", key, language)
+				continue
+			}
+			// headers are followed by lists in both languages
+			if strings.HasSuffix(english, ":") != strings.HasSuffix(text, ":") {
+				t.Errorf("Key '%s' in '%s' catalog differs from English in trailing colon", key, language)
+			}
+		}
+		for key := range catalog {
+			if _, ok := catalogs[LanguageEnglish][key]; !ok {
+				t.Errorf("Key '%s' from '%s' catalog is missing in English catalog", key, language)
+			}
+		}
+	}
+}
+
+func TestMessage_Render(t *testing.T) {
+	cases := []struct {
+		message  Message
+		language Language
+		expected string
+	}{
+		{Msg(MsgReason), LanguageEnglish, "Reason"},
+		{Msg(MsgReason), LanguageRussian, "Причина"},
+		{Msg(MsgNotificationRecovered, "api"), LanguageRussian, "Ресурс api снова доступен."},
+		{Msg(MsgReason), Language("de"), "Reason"},
+		{Msg("unknown.key"), LanguageRussian, "unknown.key"},
+		{Text("404"), LanguageRussian, "404"},
+	}
+
+	for _, c := range cases {
+		if rendered := c.message.Render(c.language); rendered != c.expected {
+			t.Errorf("Expected '%s', got '%s'", c.expected, rendered)
+		}
+	}
+}
+
+func TestCheckDetails_RenderedInLanguage(t *testing.T) {
+	details := NewCheckDetails(Msg(MsgResponseStatus), Text("503"))
+
+	if details.TitleIn(LanguageEnglish) != "Response status" {
+		t.Errorf("Expected English title, got '%s'", details.TitleIn(LanguageEnglish))
+	}
+	if details.TitleIn(LanguageRussian) != "Статус ответа" {
+		t.Errorf("Expected Russian title, got '%s'", details.TitleIn(LanguageRussian))
+	}
+	if details.DescriptionIn(LanguageEnglish) != "503" {
+		t.Errorf("Expected description '503', got '%s'", details.DescriptionIn(LanguageEnglish))
+	}
+}
+
+func TestParseLanguage(t *testing.T) {
+	if language, err := ParseLanguage("EN"); err != nil || language != LanguageEnglish {
+		t.Errorf("Expected 'en', got '%s' (%v)", language, err)
+	}
+	if _, err := ParseLanguage("xx"); err == nil {
+		t.Error("Expected error for unsupported language")
+	}
+}
+
+func TestMessage_RenderEscaped(t *testing.T) {
+	escape := func(s string) string { return strings.ReplaceAll(s, ".", "\\.") }
+
+	message := Msg(MsgNotificationRecovered, "*api.v1*")
+	expected := "Resource *api.v1* is available again\\."
+	if rendered := message.RenderEscaped(LanguageEnglish, escape); rendered != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, rendered)
+	}
+}
@@ -1,13 +1,106 @@
 package status
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
+	"time"
 )
 
+// CheckDetails is a single localizable "title: description" line
+// describing check result
 type CheckDetails struct {
-	title       string
-	description string
+	title       Message
+	description Message
+	// measurement is set for details describing a measured value
+	measurement *Measurement
+}
+
+// Title returns title in the default language
+func (d CheckDetails) Title() string {
+	return d.title.String()
+}
+
+// Description returns description in the default language
+func (d CheckDetails) Description() string {
+	return d.description.String()
+}
+
+func (d CheckDetails) TitleIn(language Language) string {
+	return d.title.Render(language)
+}
+
+func (d CheckDetails) DescriptionIn(language Language) string {
+	return d.description.Render(language)
+}
+
+// WithTitle returns the details with another title, e.g. to tell which
+// resource they describe
+func (d CheckDetails) WithTitle(title Message) CheckDetails {
+	d.title = title
+	return d
+}
+
+// Measurement returns measured value, which the details describe
+func (d CheckDetails) Measurement() (Measurement, bool) {
+	if d.measurement == nil {
+		return Measurement{}, false
+	}
+	return *d.measurement, true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d CheckDetails) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Title             string       `json:"title"`
+		Description       string       `json:"description"`
+		TitleKey          string       `json:"title_key,omitempty"`
+		DescriptionKey    string       `json:"description_key,omitempty"`
+		DescriptionParams []any        `json:"description_params,omitempty"`
+		Measurement       *Measurement `json:"measurement,omitempty"`
+	}{
+		Title:             d.Title(),
+		Description:       d.Description(),
+		TitleKey:          d.title.Key,
+		DescriptionKey:    d.description.Key,
+		DescriptionParams: d.description.Params,
+		Measurement:       d.measurement,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Details with catalog keys
+// are restored as localizable messages.
+func (d *CheckDetails) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Title             string       `json:"title"`
+		Description       string       `json:"description"`
+		TitleKey          string       `json:"title_key"`
+		DescriptionKey    string       `json:"description_key"`
+		DescriptionParams []any        `json:"description_params"`
+		Measurement       *Measurement `json:"measurement"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	d.measurement = v.Measurement
+	d.title = Text(v.Title)
+	if v.TitleKey != "" {
+		d.title = Msg(v.TitleKey)
+	}
+	d.description = Text(v.Description)
+	if v.DescriptionKey != "" {
+		// JSON numbers are decoded as float64, integral ones are converted
+		// back so %d verbs of the catalog keep working
+		for i, param := range v.DescriptionParams {
+			if f, ok := param.(float64); ok && f == math.Trunc(f) {
+				v.DescriptionParams[i] = int(f)
+			}
+		}
+		d.description = Msg(v.DescriptionKey, v.DescriptionParams...)
+	}
+	return nil
 }
 
 type ResourceState int
@@ -17,6 +110,9 @@ const (
 	StateNotAvailable                           // 1
 	StateStillNotAvailable                      // 2
 	StateRecovered                              // 3
+	// StateUnreachable is a failure of a resource, which depends on another
+	// failed resource
+	StateUnreachable // 4
 )
 
 func (s ResourceState) String() string {
@@ -29,30 +125,85 @@ func (s ResourceState) String() string {
 		return "still not available"
 	case StateRecovered:
 		return "recovered"
+	case StateUnreachable:
+		return "unreachable"
 	default:
 		return "unknown"
 	}
 }
 
+// ParseResourceState converts state name, as returned by String, to ResourceState
+func ParseResourceState(name string) (ResourceState, error) {
+	for _, s := range []ResourceState{
+		StateAvailable,
+		StateNotAvailable,
+		StateStillNotAvailable,
+		StateRecovered,
+		StateUnreachable,
+	} {
+		if strings.EqualFold(s.String(), strings.TrimSpace(name)) {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown resource state '%s'", name)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s ResourceState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *ResourceState) UnmarshalText(text []byte) error {
+	state, err := ParseResourceState(string(text))
+	if err != nil {
+		return err
+	}
+	*s = state
+	return nil
+}
+
 type CheckResult struct {
-	ResourceName string
-	ResourceType string
-	State        ResourceState
-	Details      []CheckDetails
+	ResourceName string         `json:"resource_name"`
+	ResourceType string         `json:"resource_type"`
+	State        ResourceState  `json:"state"`
+	Details      []CheckDetails `json:"details"`
+	CheckedAt    time.Time      `json:"checked_at"`
+	MonitorName  string         `json:"monitor_name,omitempty"`
+	// Labels are copied from the checked resource
+	Labels map[string]string `json:"labels,omitempty"`
+	// DownSince is the time of the first failed check of the current outage
+	DownSince time.Time `json:"down_since,omitzero"`
 }
 
 func (c CheckResult) ErrorsAsString() string {
 	var b strings.Builder
 	for _, e := range c.Details {
-		b.WriteString(fmt.Sprintf("%s: %s\n", e.title, e.description))
+		b.WriteString(fmt.Sprintf("%s: %s\n", e.Title(), e.Description()))
 	}
 	return b.String()
 }
 
+// NewCheckError creates details with untranslated title and description
 func NewCheckError(title, description string) CheckDetails {
+	return CheckDetails{title: Text(title), description: Text(description)}
+}
+
+// NewCheckDetails creates details from localizable messages
+func NewCheckDetails(title, description Message) CheckDetails {
 	return CheckDetails{title: title, description: description}
 }
 
+// NewMeasurementDetails creates details describing measured value, e.g.
+// "load1: 0.52"
+func NewMeasurementDetails(measurement Measurement) CheckDetails {
+	return CheckDetails{
+		title:       Text(measurement.Label),
+		description: Text(measurement.String()),
+		measurement: &measurement,
+	}
+}
+
 func NewCheckResult(
 	resourceName, resourceType string,
 	details []CheckDetails,
@@ -63,5 +214,6 @@ func NewCheckResult(
 		ResourceType: resourceType,
 		Details:      details,
 		State:        state,
+		CheckedAt:    time.Now(),
 	}
 }
@@ -0,0 +1,63 @@
+package status
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCheckResult_JSONRoundTrip(t *testing.T) {
+	original := NewCheckResult("api", "http", []CheckDetails{
+		NewCheckDetails(Msg(MsgReason), Msg(MsgConnectionError)),
+		NewCheckError("Body", "bad gateway"),
+	}, StateStillNotAvailable)
+	original.MonitorName = "every-minute"
+	original.DownSince = original.CheckedAt.Add(-time.Minute)
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("Expected Marshal() to succeed, got %v", err)
+	}
+
+	var restored CheckResult
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("Expected Unmarshal() to succeed, got %v", err)
+	}
+
+	if restored.State != StateStillNotAvailable || restored.MonitorName != "every-minute" {
+		t.Errorf("Unexpected restored result %+v", restored)
+	}
+	if !restored.DownSince.Equal(original.DownSince) {
+		t.Errorf("Expected down_since %v, got %v", original.DownSince, restored.DownSince)
+	}
+	if len(restored.Details) != 2 {
+		t.Fatalf("Expected 2 details, got %d", len(restored.Details))
+	}
+	if got := restored.Details[0].DescriptionIn(LanguageRussian); got != "Ошибка соединения" {
+		t.Errorf("Expected catalog description to stay localizable, got %q", got)
+	}
+	if got := restored.Details[1].DescriptionIn(LanguageRussian); got != "bad gateway" {
+		t.Errorf("Expected raw description, got %q", got)
+	}
+}
+
+func TestCheckDetails_MeasurementJSON(t *testing.T) {
+	limit := 100.0
+	details := NewMeasurementDetails(Measurement{Label: "used", Value: 93.5, Unit: "%", Warning: "80", Max: &limit})
+	if details.Title() != "used" || details.Description() != "93.5%" {
+		t.Errorf("Unexpected details %s: %s", details.Title(), details.Description())
+	}
+
+	data, err := json.Marshal(details)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored CheckDetails
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatal(err)
+	}
+	measurement, ok := restored.Measurement()
+	if !ok || measurement.PerfData() != "used=93.5%;80;;;100" {
+		t.Errorf("Expected measurement to be restored, got %+v", measurement)
+	}
+}